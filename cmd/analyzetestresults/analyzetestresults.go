@@ -1,12 +1,25 @@
 package analyzetestresults
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
+	"github.com/bsm/ginkgo/v2/reporters"
+	"github.com/konflux-ci/qe-tools/cmd/prowconfig"
+	"github.com/konflux-ci/qe-tools/pkg/classifier"
+	"github.com/konflux-ci/qe-tools/pkg/junitdiff"
+	"github.com/konflux-ci/qe-tools/pkg/knownissues"
+	"github.com/konflux-ci/qe-tools/pkg/logexcerpt"
 	"github.com/konflux-ci/qe-tools/pkg/oci"
+	"github.com/konflux-ci/qe-tools/pkg/prcomment"
+	"github.com/konflux-ci/qe-tools/pkg/prow"
 	"github.com/konflux-ci/qe-tools/pkg/testresults"
+	"github.com/konflux-ci/qe-tools/pkg/utils"
 	"k8s.io/klog/v2"
+	prowUtils "k8s.io/test-infra/prow/pod-utils/downwardapi"
 
 	"github.com/konflux-ci/qe-tools/pkg/types"
 	"github.com/spf13/cobra"
@@ -19,56 +32,255 @@ var (
 	jUnitFilename               string
 	e2eTestRunLogFilename       string
 	outputFilename              string
+	knownIssuesFile             string
+	logContextLines             int
+	logMaxBytes                 int
+	artifactURL                 string
+	notifyOnPR                  bool
+	prCommentID                 string
+	outputFormat                string
+	localDir                    string
+	tarball                     string
+	prowJobID                   string
+	resultsDB                   string
+	baselineRef                 string
 )
 
+const (
+	logContextLinesParamName = "log-context-lines"
+	logMaxBytesParamName     = "log-max-bytes"
+	artifactURLParamName     = "artifact-url"
+	notifyOnPRParamName      = "notify-on-pr"
+	prCommentIDParamName     = "pr-comment-id"
+	formatParamName          = "format"
+	localDirParamName        = "local-dir"
+	tarballParamName         = "tarball"
+	baselineParamName        = "baseline"
+)
+
+// sourceParamNames are the mutually exclusive parameters defining the source of the test results
+var sourceParamNames = []string{types.OciArtifactRefParamName, localDirParamName, tarballParamName, types.ProwJobIDParamName}
+
+var notifyOnPRRequiredEnvVars = []string{types.GithubTokenEnv, prowUtils.RepoOwnerEnv, prowUtils.RepoNameEnv, prowUtils.PullNumberEnv}
+
 // AnalyzeTestResultsCmd represents the analyze-test-results command
 var AnalyzeTestResultsCmd = &cobra.Command{
 	Use:   "analyze-test-results",
 	Short: "Command for analyzing test results",
+	Long: `Analyzes test results stored in an OCI artifact, a local directory, a tarball or artifacts of a Prow job.
+
+Exactly one source of the test results is expected. The OCI artifact reference can be also provided via the ` + types.OciArtifactRefEnv + ` env var.`,
+	Example: `  # Analyze test results stored in an OCI artifact
+  qe-tools analyze-test-results --oci-ref quay.io/org/repo:oci-artifact-tag
+
+  # Analyze test results downloaded from another CI system
+  qe-tools analyze-test-results --local-dir ./artifacts
+  qe-tools analyze-test-results --tarball ./artifacts.tar.gz
+
+  # Analyze test results of a Prow job
+  qe-tools analyze-test-results --prow-job-id 1234
+
+  # Include the diff with the last green periodic job (Prow job 1000)
+  qe-tools analyze-test-results --prow-job-id 1234 --baseline 1000`,
 	PreRunE: func(cmd *cobra.Command, _ []string) error {
-		if viper.GetString(types.OciArtifactRefParamName) == "" {
+		if err := prowconfig.BindFlags(cmd.Flags()); err != nil {
+			return err
+		}
+		// bound when the command is executed, as "prowjob create-report" binds its flag of the same name in init
+		if err := viper.BindPFlag(types.ResultsDBParamName, cmd.Flags().Lookup(types.ResultsDBParamName)); err != nil {
+			return err
+		}
+		var sources []string
+		for _, name := range sourceParamNames {
+			if cmd.Flags().Changed(name) {
+				sources = append(sources, name)
+			}
+		}
+		if len(sources) > 1 {
+			_ = cmd.Usage()
+			return fmt.Errorf("parameters %q are mutually exclusive", sources)
+		}
+		if len(sources) == 0 && viper.GetString(types.OciArtifactRefParamName) == "" {
 			_ = cmd.Usage()
-			return fmt.Errorf("parameter %q not provided, neither %s env var was set", types.OciArtifactRefParamName, types.OciArtifactRefEnv)
+			return fmt.Errorf("none of parameters %q provided, neither %s env var was set", sourceParamNames, types.OciArtifactRefEnv)
+		}
+		if notifyOnPR {
+			for _, e := range notifyOnPRRequiredEnvVars {
+				if viper.GetString(e) == "" {
+					return fmt.Errorf("%q flag provided, but %q env var not set", notifyOnPRParamName, e)
+				}
+			}
+			if _, err := strconv.Atoi(viper.GetString(prowUtils.PullNumberEnv)); err != nil {
+				return fmt.Errorf("invalid PR number in %q env var: %+v", prowUtils.PullNumberEnv, err)
+			}
 		}
 		return nil
 	},
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ociArtifactRef = viper.GetString(types.OciArtifactRefParamName)
+		formatter, err := testresults.NewFormatter(outputFormat)
+		if err != nil {
+			return err
+		}
 
-		cfg := oci.ScannerConfig{
-			OciArtifactReference: ociArtifactRef,
-			FileNameFilter:       []string{jUnitFilename, clusterProvisionLogFilename, e2eTestRunLogFilename},
+		fileNameFilter, err := testresults.FileNameFilter(jUnitFilename, clusterProvisionLogFilename, e2eTestRunLogFilename)
+		if err != nil {
+			return err
+		}
+		// test results of Prow jobs (analyzed or baseline ones) are read from the Prow instance and the source selected by the flags
+		var prowConfig prow.ScannerConfig
+		if prowJobID != "" || baselineRef != "" {
+			if prowConfig, err = prowconfig.ScannerConfig(); err != nil {
+				return err
+			}
+		}
+		var filesPathMap oci.FilesPathMap
+		var fullLogsURL string
+		if prowJobID != "" {
+			filesPathMap, fullLogsURL, err = testresults.ScanProwJob(prowConfig, prowJobID, fileNameFilter)
+		} else {
+			filesPathMap, fullLogsURL, err = scanArtifact(fileNameFilter)
+		}
+		if err != nil {
+			return err
 		}
 
-		scanner, err := oci.NewArtifactScanner(cfg)
+		failureClassifier, err := classifier.FromConfig()
 		if err != nil {
-			return fmt.Errorf("failed to initialize artifact scanner: %+v", err)
+			return err
 		}
 
-		if err := scanner.Run(); err != nil {
-			return fmt.Errorf("failed to scan artifact from %s: %+v", ociArtifactRef, err)
+		failedTCReport := testresults.FailedTestCasesReport{
+			Classifier:  failureClassifier,
+			LogExcerpt:  &logexcerpt.Options{ContextLines: logContextLines, MaxBytes: logMaxBytes},
+			FullLogsURL: artifactURL,
+		}
+		if failedTCReport.FullLogsURL == "" {
+			failedTCReport.FullLogsURL = fullLogsURL
+		}
+		if knownIssuesFile != "" {
+			if failedTCReport.KnownIssues, err = knownissues.Load(knownIssuesFile); err != nil {
+				return err
+			}
+		}
+		if err := failedTCReport.CollectTestFilesData(filesPathMap, jUnitFilename, e2eTestRunLogFilename, clusterProvisionLogFilename); err != nil {
+			return err
 		}
 
-		failedTCReport := testresults.FailedTestCasesReport{}
-		failedTCReport.CollectTestFilesData(scanner.FilesPathMap, jUnitFilename, e2eTestRunLogFilename, clusterProvisionLogFilename)
+		if baselineRef != "" && failedTCReport.FailureType == testresults.TestCaseFailure {
+			if failedTCReport.Diff, err = diffWithBaseline(baselineRef, jUnitFilename, failedTCReport.JUnitTestSuites, prowConfig); err != nil {
+				return err
+			}
+		}
 
-		if err := os.WriteFile(outputFilename, []byte(testresults.GetFormattedReport(failedTCReport)), 0o600); err != nil {
+		output, err := formatter.Format(failedTCReport)
+		if err != nil {
+			return err
+		}
+		if !cmd.Flags().Changed(types.OutputFilenameParamName) {
+			outputFilename = "analysis." + formatter.FileExtension()
+		}
+		if err := os.WriteFile(outputFilename, output, 0o600); err != nil {
 			return fmt.Errorf("failed to create a file with the test result analysis: %+v", err)
 		}
 		klog.Infof("analysis saved to %s", outputFilename)
 
+		if notifyOnPR {
+			prNumber, _ := strconv.Atoi(viper.GetString(prowUtils.PullNumberEnv))
+			commenter := prcomment.NewCommenter(utils.NewGithubClient(viper.GetString(types.GithubTokenEnv)), viper.GetString(prowUtils.RepoOwnerEnv), viper.GetString(prowUtils.RepoNameEnv), prNumber, prCommentID)
+			// the comment is always in Markdown (rendered by GitHub), regardless of the format of the output file
+			comment, err := commenter.Post(context.Background(), testresults.GetFormattedReport(failedTCReport))
+			if err != nil {
+				return err
+			}
+			klog.Infof("analysis posted to %s", comment.GetHTMLURL())
+		}
+
+		if dbPath := viper.GetString(types.ResultsDBParamName); dbPath != "" {
+			if err := ingestResults(dbPath, failedTCReport, filesPathMap); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	},
 }
 
+// scanArtifact scans the OCI artifact, the local directory or the tarball for the files matching
+// the given filter. The URL of the artifact's quay.io page is returned for OCI artifacts stored in quay.io
+func scanArtifact(fileNameFilter []string) (oci.FilesPathMap, string, error) {
+	cfg := oci.ScannerConfig{
+		LocalDir:       localDir,
+		Tarball:        tarball,
+		FileNameFilter: fileNameFilter,
+	}
+	source, url := localDir, ""
+	if tarball != "" {
+		source = tarball
+	}
+	if localDir == "" && tarball == "" {
+		cfg.OciArtifactReference = viper.GetString(types.OciArtifactRefParamName)
+		source, url = cfg.OciArtifactReference, quayArtifactURL(cfg.OciArtifactReference)
+	}
+
+	scanner, err := oci.NewArtifactScanner(cfg)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to initialize artifact scanner: %+v", err)
+	}
+	if err := scanner.Run(); err != nil {
+		return nil, "", fmt.Errorf("failed to scan artifact from %s: %+v", source, err)
+	}
+	return scanner.FilesPathMap, url, nil
+}
+
+// diffWithBaseline returns the diff of the analyzed JUnit report with the report of the baseline run - JUnit files
+// of the baseline run are matched by the same pattern as the analyzed ones
+func diffWithBaseline(ref, junitPattern string, suites *reporters.JUnitTestSuites, prowConfig prow.ScannerConfig) (*junitdiff.Diff, error) {
+	pattern, err := testresults.NewFilePattern(junitPattern)
+	if err != nil {
+		return nil, err
+	}
+	baseline, err := testresults.LoadJUnit(ref, pattern, prowConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load test results of the baseline run: %+v", err)
+	}
+	diff := junitdiff.Compare(baseline, suites)
+	diff.Baseline = ref
+	klog.Infof("%s: %s", diff.Title(), diff.Summary())
+	return &diff, nil
+}
+
+// quayArtifactURL returns the URL of the quay.io page with the given OCI artifact, or an empty string for other registries
+func quayArtifactURL(ref string) string {
+	repo, tag, err := utils.ParseRepoAndTag(ref)
+	if err != nil {
+		return ""
+	}
+	return "https://quay.io/repository/" + repo + "?tab=tags&tag=" + tag
+}
+
 func init() {
 	AnalyzeTestResultsCmd.Flags().StringVar(&ociArtifactRef, types.OciArtifactRefParamName, "", "OCI artifact reference (e.g. \"quay.io/org/repo:oci-artifact-tag\")")
-	AnalyzeTestResultsCmd.Flags().StringVar(&jUnitFilename, types.JUnitFilenameParamName, "e2e-report.xml", "A name of the file containing JUnit report")
-	AnalyzeTestResultsCmd.Flags().StringVar(&clusterProvisionLogFilename, types.ClusterProvisionLogFileParamName, "cluster-provision.log", "A name of the file containing log from provisioning a testing cluster")
-	AnalyzeTestResultsCmd.Flags().StringVar(&e2eTestRunLogFilename, types.E2ETestRunLogFileParamName, "e2e-tests.log", "A name of the file containing log from running tests")
-	AnalyzeTestResultsCmd.Flags().StringVar(&outputFilename, types.OutputFilenameParamName, "analysis.md", "A name of the file to store the analysis output in")
+	AnalyzeTestResultsCmd.Flags().StringVar(&localDir, localDirParamName, "", "Path to the local directory with the test results (alternative to --oci-ref)")
+	AnalyzeTestResultsCmd.Flags().StringVar(&tarball, tarballParamName, "", "Path to the tarball (optionally gzip-compressed) with the test results (alternative to --oci-ref)")
+	AnalyzeTestResultsCmd.Flags().StringVar(&prowJobID, types.ProwJobIDParamName, "", "ID of the Prow job with the test results in its artifacts (alternative to --oci-ref)")
+	AnalyzeTestResultsCmd.Flags().StringVar(&jUnitFilename, types.JUnitFilenameParamName, "e2e-report.xml", "A name or glob pattern (or regular expression prefixed with \"re:\") of the file(s) containing JUnit report - all matching reports are merged")
+	AnalyzeTestResultsCmd.Flags().StringVar(&clusterProvisionLogFilename, types.ClusterProvisionLogFileParamName, "cluster-provision.log", "A name or glob pattern (or regular expression prefixed with \"re:\") of the file(s) containing log from provisioning a testing cluster")
+	AnalyzeTestResultsCmd.Flags().StringVar(&e2eTestRunLogFilename, types.E2ETestRunLogFileParamName, "e2e-tests.log", "A name or glob pattern (or regular expression prefixed with \"re:\") of the file(s) containing log from running tests")
+	AnalyzeTestResultsCmd.Flags().StringVar(&knownIssuesFile, types.KnownIssuesFileParamName, "", "Path to the YAML/JSON file with known issues matched against the failed test cases")
+	AnalyzeTestResultsCmd.Flags().IntVar(&logContextLines, logContextLinesParamName, logexcerpt.DefaultContextLines, "Number of lines included before and after every error found in the logs")
+	AnalyzeTestResultsCmd.Flags().IntVar(&logMaxBytes, logMaxBytesParamName, logexcerpt.DefaultMaxBytes, "Maximum size of every log excerpt in bytes (no limit if <= 0)")
+	AnalyzeTestResultsCmd.Flags().StringVar(&artifactURL, artifactURLParamName, "", "URL with the full logs linked from the log excerpts (defaults to the quay.io page of the OCI artifact or the Prow job URL)")
+	AnalyzeTestResultsCmd.Flags().BoolVar(&notifyOnPR, notifyOnPRParamName, false, fmt.Sprintf("Post the analysis as a comment to the related PR, editing the previous one - the comment is always in Markdown, regardless of --"+formatParamName+" (required env vars: %s)", strings.Join(notifyOnPRRequiredEnvVars, ", ")))
+	AnalyzeTestResultsCmd.Flags().StringVar(&prCommentID, prCommentIDParamName, "analyze-test-results", "Identifier of the PR comment, distinguishing analyses of different pipelines on the same PR")
+	AnalyzeTestResultsCmd.Flags().StringVar(&outputFormat, formatParamName, testresults.MarkdownFormat, fmt.Sprintf("Format of the analysis output (%s)", strings.Join(testresults.Formats, ", ")))
+	AnalyzeTestResultsCmd.Flags().StringVar(&baselineRef, baselineParamName, "", "Test results of a baseline run (e.g. the last green periodic job) to include the diff with - a path to a JUnit file, a Prow job ID or an OCI artifact reference (JUnit files within the artifacts are matched by --"+types.JUnitFilenameParamName+")")
+	AnalyzeTestResultsCmd.Flags().StringVar(&resultsDB, types.ResultsDBParamName, "", "Path to the results database the analyzed test results should be stored into (or "+types.ResultsDBEnv+" env var)")
+	AnalyzeTestResultsCmd.Flags().StringVar(&outputFilename, types.OutputFilenameParamName, "analysis.md", "A name of the file to store the analysis output in (defaults to \"analysis.<extension of the format>\")")
+
+	prowconfig.AddFlags(AnalyzeTestResultsCmd.Flags())
 
 	_ = viper.BindPFlag(types.OciArtifactRefParamName, AnalyzeTestResultsCmd.Flags().Lookup(types.OciArtifactRefParamName))
 	_ = viper.BindEnv(types.OciArtifactRefParamName, types.OciArtifactRefEnv)
+	_ = viper.BindEnv(types.ResultsDBParamName, types.ResultsDBEnv)
 }
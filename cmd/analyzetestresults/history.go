@@ -0,0 +1,76 @@
+package analyzetestresults
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/konflux-ci/qe-tools/pkg/history"
+	"github.com/konflux-ci/qe-tools/pkg/oci"
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+	"github.com/konflux-ci/qe-tools/pkg/testresults"
+	"github.com/konflux-ci/qe-tools/pkg/types"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+// ingestResults stores the analyzed test results into the results database, so that they can be queried
+// later via "qe-tools results query". The job metadata is taken from the JOB_SPEC env var, if set
+func ingestResults(dbPath string, report testresults.FailedTestCasesReport, fpm oci.FilesPathMap) error {
+	run := history.Run{
+		ID:        resultsID(fpm),
+		Source:    history.AnalyzeTestResultsSource,
+		URL:       report.FullLogsURL,
+		TestCases: history.TestCasesFromJUnit(report.JUnitTestSuites),
+	}
+	if jobSpec := viper.GetString(types.JobSpecEnv); jobSpec != "" {
+		spec, err := prow.ParseJobSpec(jobSpec)
+		if err != nil {
+			klog.Warningf("failed to parse job spec from %s env var - the job metadata won't be stored: %+v", types.JobSpecEnv, err)
+		} else {
+			run.SetJobSpec(spec)
+		}
+	}
+
+	store, err := history.Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	if err := store.Ingest(run); err != nil {
+		return fmt.Errorf("failed to store results from %s: %+v", run.ID, err)
+	}
+	klog.Infof("results from %s stored in %s", run.ID, dbPath)
+	return nil
+}
+
+// resultsID returns the identifier of the analyzed test results - the Prow job ID or the OCI artifact reference.
+// The path to the local directory/tarball doesn't identify the results (the same path is usually reused by every
+// run), so it's suffixed with the hash of the scanned files
+func resultsID(fpm oci.FilesPathMap) string {
+	switch {
+	case prowJobID != "":
+		return prowJobID
+	case localDir != "":
+		return localDir + "@" + contentHash(fpm)
+	case tarball != "":
+		return tarball + "@" + contentHash(fpm)
+	}
+	return viper.GetString(types.OciArtifactRefParamName)
+}
+
+// contentHash returns the short hash of the paths and the content of the scanned files
+func contentHash(fpm oci.FilesPathMap) string {
+	paths := make([]string, 0, len(fpm))
+	for p := range fpm {
+		paths = append(paths, string(p))
+	}
+	sort.Strings(paths)
+
+	h := sha256.New()
+	for _, p := range paths {
+		fmt.Fprintf(h, "%s\x00%d\x00%s", p, len(fpm[oci.FilePath(p)].Content), fpm[oci.FilePath(p)].Content)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
@@ -0,0 +1,226 @@
+package issues
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bsm/ginkgo/v2/reporters"
+	"github.com/konflux-ci/qe-tools/pkg/classifier"
+	"github.com/konflux-ci/qe-tools/pkg/issues"
+	"github.com/konflux-ci/qe-tools/pkg/knownissues"
+	"github.com/konflux-ci/qe-tools/pkg/oci"
+	"github.com/konflux-ci/qe-tools/pkg/testresults"
+	"github.com/konflux-ci/qe-tools/pkg/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+const (
+	junitFileParamName   = "junit-file"
+	jobNameParamName     = "job-name"
+	jobURLParamName      = "job-url"
+	jiraURLParamName     = "jira-url"
+	jiraProjectParamName = "jira-project"
+	issueTypeParamName   = "issue-type"
+	labelParamName       = "label"
+	dryRunParamName      = "dry-run"
+
+	jiraURLEnv     = "JIRA_URL"
+	jiraProjectEnv = "JIRA_PROJECT"
+	jiraUserEnv    = "JIRA_USER"
+	jiraTokenEnv   = "JIRA_TOKEN" // #nosec G101
+)
+
+var (
+	junitFile                   string
+	ociArtifactRef              string
+	jUnitFilename               string
+	clusterProvisionLogFilename string
+	e2eTestRunLogFilename       string
+	knownIssuesFile             string
+	jobName                     string
+	jobURL                      string
+	issueType                   string
+	labels                      []string
+	dryRun                      bool
+)
+
+// fileCmd files new failures as Jira issues
+var fileCmd = &cobra.Command{
+	Use:   "file",
+	Short: "Create or update Jira issues for failures from a create-report JUnit file or an analyze-test-results artifact",
+	Long: `Create or update Jira issues for failures from the JUnit file produced by "prowjob create-report" (--junit-file)
+or from the OCI artifact analyzed by "analyze-test-results" (--oci-ref).
+
+Failures are deduplicated by their signature (a hash of the test name and the normalized failure message) stored
+as a label of the issue. A new issue is created only if there's no unresolved issue with the same signature,
+otherwise a comment with the new occurrence and the job link is added to the existing issue.
+Failures matching known issues are skipped.
+
+The token for accessing Jira is read from the JIRA_TOKEN env var. It is sent as a Bearer token
+(personal access token), or used for the basic authentication if JIRA_USER is set.`,
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		if (junitFile == "") == (ociArtifactRef == "") {
+			_ = cmd.Usage()
+			return fmt.Errorf("exactly one of parameters %q and %q has to be provided", junitFileParamName, types.OciArtifactRefParamName)
+		}
+		for _, param := range []string{jiraURLParamName, jiraProjectParamName} {
+			if viper.GetString(param) == "" {
+				_ = cmd.Usage()
+				return fmt.Errorf("parameter %q not provided", param)
+			}
+		}
+		if viper.GetString(jiraTokenEnv) == "" && !dryRun {
+			return fmt.Errorf("%s env var not set", jiraTokenEnv)
+		}
+		return nil
+	},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		var failures []issues.Failure
+		var err error
+		if junitFile != "" {
+			failures, err = failuresFromJUnitFile(junitFile)
+		} else {
+			failures, err = failuresFromOCIArtifact(ociArtifactRef)
+		}
+		if err != nil {
+			return err
+		}
+
+		if failures, err = filterAndClassify(failures); err != nil {
+			return err
+		}
+		if len(failures) == 0 {
+			klog.Info("no new failures to file")
+			return nil
+		}
+
+		filer := &issues.Filer{
+			Client:    issues.NewJiraClient(viper.GetString(jiraURLParamName), viper.GetString(jiraUserEnv), viper.GetString(jiraTokenEnv)),
+			Project:   viper.GetString(jiraProjectParamName),
+			IssueType: issueType,
+			Labels:    labels,
+			DryRun:    dryRun,
+		}
+		results, err := filer.File(context.Background(), failures)
+		for _, r := range results {
+			issue, action := r.IssueURL, string(r.Action)
+			if issue == "" {
+				issue = "new issue"
+			}
+			if dryRun {
+				action = "would be " + action
+			}
+			fmt.Printf("%s: %s (%d failure(s) of %q, signature %s)\n", issue, action, len(r.Failures), r.Failures[0].TestName, r.Signature)
+		}
+		return err
+	},
+}
+
+// failuresFromJUnitFile returns failures from the JUnit file, e.g. produced by "prowjob create-report"
+func failuresFromJUnitFile(path string) ([]issues.Failure, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JUnit file %s: %+v", path, err)
+	}
+	suites := &reporters.JUnitTestSuites{}
+	if err := xml.Unmarshal(data, suites); err != nil {
+		return nil, fmt.Errorf("cannot decode JUnit suites from %s: %+v", path, err)
+	}
+	return issues.FromJUnit(suites, jobName, jobURL), nil
+}
+
+// failuresFromOCIArtifact returns failures from the OCI artifact the same way "analyze-test-results" detects them
+func failuresFromOCIArtifact(ref string) ([]issues.Failure, error) {
+	fileNameFilter, err := testresults.FileNameFilter(jUnitFilename, clusterProvisionLogFilename, e2eTestRunLogFilename)
+	if err != nil {
+		return nil, err
+	}
+	scanner, err := oci.NewArtifactScanner(oci.ScannerConfig{
+		OciArtifactReference: ref,
+		FileNameFilter:       fileNameFilter,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize artifact scanner: %+v", err)
+	}
+	if err := scanner.Run(); err != nil {
+		return nil, fmt.Errorf("failed to scan artifact from %s: %+v", ref, err)
+	}
+
+	report := testresults.FailedTestCasesReport{}
+	if err := report.CollectTestFilesData(scanner.FilesPathMap, jUnitFilename, e2eTestRunLogFilename, clusterProvisionLogFilename); err != nil {
+		return nil, err
+	}
+
+	var failures []issues.Failure
+	switch report.FailureType {
+	case testresults.TestCaseFailure:
+		return issues.FromJUnit(report.JUnitTestSuites, jobName, jobURL), nil
+	case testresults.ClusterCreationFailure:
+		failures = append(failures, issues.NewFailure("cluster provisioning", "failed to provision a cluster", report.ClusterProvisionLog))
+	case testresults.TestRunFailure:
+		failures = append(failures, issues.NewFailure("test run", "no JUnit file found after running tests", report.E2ETestLog))
+	}
+	for i := range failures {
+		failures[i].JobName, failures[i].JobURL = jobName, jobURL
+	}
+	return failures, nil
+}
+
+// filterAndClassify drops failures matching known issues (if --known-issues is set)
+// and classifies failures which weren't classified yet
+func filterAndClassify(failures []issues.Failure) ([]issues.Failure, error) {
+	var store *knownissues.Store
+	if knownIssuesFile != "" {
+		var err error
+		if store, err = knownissues.Load(knownIssuesFile); err != nil {
+			return nil, err
+		}
+	}
+
+	failureClassifier, err := classifier.FromConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []issues.Failure
+	for _, f := range failures {
+		if matched := store.Match(f.TestName, f.Message); len(matched) > 0 {
+			klog.Infof("skipping failure of %q matching known issue %s", f.TestName, matched[0].Key)
+			continue
+		}
+		if f.Category == "" {
+			f.Category = string(failureClassifier.Classify(classifier.Evidence{StepName: f.TestName, FailureMessage: f.Message, BuildLog: f.Log}).Category)
+		}
+		filtered = append(filtered, f)
+	}
+	return filtered, nil
+}
+
+func init() {
+	fileCmd.Flags().StringVar(&junitFile, junitFileParamName, "", "Path to the JUnit file with failures, e.g. produced by \"prowjob create-report\"")
+	fileCmd.Flags().StringVar(&ociArtifactRef, types.OciArtifactRefParamName, "", "OCI artifact reference with test results analyzed by \"analyze-test-results\" (e.g. \"quay.io/org/repo:oci-artifact-tag\")")
+	fileCmd.Flags().StringVar(&jUnitFilename, types.JUnitFilenameParamName, "e2e-report.xml", "A name or glob pattern (or regular expression prefixed with \"re:\") of the file(s) containing JUnit report within the OCI artifact")
+	fileCmd.Flags().StringVar(&clusterProvisionLogFilename, types.ClusterProvisionLogFileParamName, "cluster-provision.log", "A name or glob pattern (or regular expression prefixed with \"re:\") of the file(s) containing log from provisioning a testing cluster within the OCI artifact")
+	fileCmd.Flags().StringVar(&e2eTestRunLogFilename, types.E2ETestRunLogFileParamName, "e2e-tests.log", "A name or glob pattern (or regular expression prefixed with \"re:\") of the file(s) containing log from running tests within the OCI artifact")
+	fileCmd.Flags().StringVar(&knownIssuesFile, types.KnownIssuesFileParamName, "", "Path to the YAML/JSON file with known issues - matching failures are not filed")
+	fileCmd.Flags().StringVar(&jobName, jobNameParamName, "", "Name of the job the failures occurred in (unless defined in the JUnit file)")
+	fileCmd.Flags().StringVar(&jobURL, jobURLParamName, "", "URL of the job the failures occurred in (unless defined in the JUnit file)")
+	fileCmd.Flags().String(jiraURLParamName, "", "URL of the Jira instance, e.g. https://issues.redhat.com (or "+jiraURLEnv+" env var)")
+	fileCmd.Flags().String(jiraProjectParamName, "", "Key of the Jira project to file the issues into (or "+jiraProjectEnv+" env var)")
+	fileCmd.Flags().StringVar(&issueType, issueTypeParamName, "Bug", "Type of the created issues")
+	fileCmd.Flags().StringSliceVar(&labels, labelParamName, nil, "Labels added to the created issues (can be repeated)")
+	fileCmd.Flags().BoolVar(&dryRun, dryRunParamName, false, "Only print which issues would be created or commented")
+
+	_ = viper.BindPFlag(jiraURLParamName, fileCmd.Flags().Lookup(jiraURLParamName))
+	_ = viper.BindEnv(jiraURLParamName, jiraURLEnv)
+	_ = viper.BindPFlag(jiraProjectParamName, fileCmd.Flags().Lookup(jiraProjectParamName))
+	_ = viper.BindEnv(jiraProjectParamName, jiraProjectEnv)
+	_ = viper.BindEnv(jiraUserEnv)
+	_ = viper.BindEnv(jiraTokenEnv)
+}
@@ -0,0 +1,15 @@
+package issues
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// IssuesCmd represents the issues command
+var IssuesCmd = &cobra.Command{
+	Use:   "issues",
+	Short: "Commands for filing failures into the issue tracker",
+}
+
+func init() {
+	IssuesCmd.AddCommand(fileCmd)
+}
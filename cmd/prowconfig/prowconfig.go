@@ -0,0 +1,129 @@
+// Package prowconfig provides the flags and the config file settings selecting the Prow instance, the storage
+// of Prow job artifacts (GCS, S3-compatible bucket or local directory) and the job target rules, shared by
+// all commands reading artifacts of Prow jobs
+package prowconfig
+
+import (
+	"fmt"
+
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Names of the parameters and of the config file keys
+const (
+	InstanceParamName          = "prow-instance"
+	InstancesConfigKey         = "prowInstances"
+	JobTargetRulesConfigKey    = "jobTargetRules"
+	ArtifactSourceParamName    = "artifact-source"
+	ArtifactSourceDirParamName = "artifact-source-dir"
+	S3EndpointParamName        = "s3-endpoint"
+	S3InsecureParamName        = "s3-insecure"
+
+	s3AccessKeyEnv = "AWS_ACCESS_KEY_ID"
+	s3SecretKeyEnv = "AWS_SECRET_ACCESS_KEY" // #nosec G101
+)
+
+var paramNames = []string{InstanceParamName, ArtifactSourceParamName, ArtifactSourceDirParamName, S3EndpointParamName, S3InsecureParamName}
+
+// AddFlags adds flags used for selecting the Prow instance and the storage to read Prow job artifacts from.
+// The flags are shared by several commands, so they are bound to viper by BindFlags once the command is executed
+func AddFlags(flags *pflag.FlagSet) {
+	flags.String(InstanceParamName, prow.DefaultInstanceName, "Name of the Prow instance (defined under \""+InstancesConfigKey+"\" in the config file) the jobs ran in")
+	flags.String(ArtifactSourceParamName, string(prow.GCSSourceType), "Storage to read the Prow job artifacts from (gcs, s3, local)")
+	flags.String(ArtifactSourceDirParamName, "", "Path to the directory mirroring the bucket's structure (used with --artifact-source=local)")
+	flags.String(S3EndpointParamName, "", "Endpoint of the S3-compatible service, e.g. localhost:9000 (used with --artifact-source=s3)")
+	flags.Bool(S3InsecureParamName, false, "Disable TLS when connecting to the S3 endpoint")
+}
+
+// BindFlags binds the flags added by AddFlags to viper - it's called by the executed command (e.g. in its PreRunE),
+// since only the flags of a single command can be bound to the same viper key
+func BindFlags(flags *pflag.FlagSet) error {
+	for _, name := range paramNames {
+		if err := viper.BindPFlag(name, flags.Lookup(name)); err != nil {
+			return fmt.Errorf("failed to bind flag %q: %+v", name, err)
+		}
+	}
+	return nil
+}
+
+// Instance returns the Prow instance selected via the --prow-instance parameter.
+// Instances are defined in the config file, e.g.:
+//
+//	prowInstances:
+//	  - name: my-prow
+//	    bucket: my-bucket
+//	    prowJobURL: https://prow.example.com/prowjob?prowjob=
+//	    artifactBrowserURL: https://gcsweb.example.com/gcs/my-bucket/
+//	    jobViewURL: https://prow.example.com/view/gs/
+//	    credentialsFile: /path/to/service-account.json
+func Instance() (prow.Instance, error) {
+	var instances []prow.Instance
+	if err := viper.UnmarshalKey(InstancesConfigKey, &instances); err != nil {
+		return prow.Instance{}, fmt.Errorf("failed to parse %q from config: %+v", InstancesConfigKey, err)
+	}
+	return prow.GetInstance(instances, viper.GetString(InstanceParamName))
+}
+
+// JobTargetRules returns rules for determining the openshift-ci target of a job, defined in the config file, e.g.:
+//
+//	jobTargetRules:
+//	  - pattern: pull-ci-konflux-ci-e2e-tests
+//	    target: redhat-appstudio-e2e
+//
+// If no rules are defined, prow.DefaultJobTargetRules are used
+func JobTargetRules() ([]prow.JobTargetRule, error) {
+	var rules []prow.JobTargetRule
+	if err := viper.UnmarshalKey(JobTargetRulesConfigKey, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse %q from config: %+v", JobTargetRulesConfigKey, err)
+	}
+	return rules, nil
+}
+
+// NewSource creates the ArtifactSource selected via the command line
+// for the bucket of the given Prow instance
+func NewSource(instance prow.Instance) (prow.ArtifactSource, error) {
+	source, err := prow.NewArtifactSource(prow.SourceConfig{
+		Type:            prow.SourceType(viper.GetString(ArtifactSourceParamName)),
+		Bucket:          instance.Bucket,
+		CredentialsFile: instance.CredentialsFile,
+		LocalDir:        viper.GetString(ArtifactSourceDirParamName),
+		S3Endpoint:      viper.GetString(S3EndpointParamName),
+		S3AccessKey:     viper.GetString(s3AccessKeyEnv),
+		S3SecretKey:     viper.GetString(s3SecretKeyEnv),
+		S3Insecure:      viper.GetBool(S3InsecureParamName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize artifact source: %+v", err)
+	}
+	return source, nil
+}
+
+// ScannerConfig returns the configuration for scanning artifacts of Prow jobs of the selected instance
+// from the selected source, with job target rules from the config file. The job and the files to scan
+// are left to the caller
+func ScannerConfig() (prow.ScannerConfig, error) {
+	instance, err := Instance()
+	if err != nil {
+		return prow.ScannerConfig{}, err
+	}
+	source, err := NewSource(instance)
+	if err != nil {
+		return prow.ScannerConfig{}, err
+	}
+	jobTargetRules, err := JobTargetRules()
+	if err != nil {
+		return prow.ScannerConfig{}, err
+	}
+	return prow.ScannerConfig{
+		Instance:       instance,
+		Source:         source,
+		JobTargetRules: jobTargetRules,
+	}, nil
+}
+
+func init() {
+	_ = viper.BindEnv(s3AccessKeyEnv)
+	_ = viper.BindEnv(s3SecretKeyEnv)
+}
@@ -0,0 +1,27 @@
+package prowjob
+
+import (
+	"github.com/konflux-ci/qe-tools/cmd/prowconfig"
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+	"github.com/konflux-ci/qe-tools/pkg/types"
+)
+
+// reportStepName is the openshift-ci step publishing the report created by create-report - it contains
+// copies of the JUnit files of the other steps, so it's skipped by default when scanning the artifacts
+const reportStepName = "redhat-appstudio-report"
+
+// newScannerConfig returns the configuration for scanning finished.json and JUnit files of all openshift-ci
+// steps of a job (except the given steps to skip) from the given source, with job target rules from the config file
+func newScannerConfig(instance prow.Instance, source prow.ArtifactSource, stepsToSkip []string) (prow.ScannerConfig, error) {
+	jobTargetRules, err := prowconfig.JobTargetRules()
+	if err != nil {
+		return prow.ScannerConfig{}, err
+	}
+	return prow.ScannerConfig{
+		FileNameFilter: []string{finishedFilename, types.JunitFilename},
+		StepsToSkip:    stepsToSkip,
+		JobTargetRules: jobTargetRules,
+		Instance:       instance,
+		Source:         source,
+	}, nil
+}
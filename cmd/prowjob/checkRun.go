@@ -0,0 +1,55 @@
+package prowjob
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/konflux-ci/qe-tools/pkg/checks"
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+	"github.com/konflux-ci/qe-tools/pkg/types"
+	"github.com/konflux-ci/qe-tools/pkg/utils"
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+const (
+	githubCheckParamName     = "github-check"
+	githubCheckNameParamName = "github-check-name"
+	jobSpecParamName         = "job-spec"
+)
+
+var (
+	githubCheck     bool
+	githubCheckName string
+)
+
+// publishCheckRun publishes the results as a GitHub check run on the head commit of the PR the job was triggered for.
+// Jobs not triggered for a PR (e.g. periodic jobs) are skipped
+func publishCheckRun(suites *reporters.JUnitTestSuites, detailsURL string) error {
+	spec, err := prow.ParseJobSpec(viper.GetString(jobSpecParamName))
+	if err != nil {
+		return fmt.Errorf("failed to parse job spec: %+v", err)
+	}
+	if len(spec.Refs.Pulls) == 0 {
+		klog.Warningf("job %s of type %q wasn't triggered for a PR - skipping publishing the check run", spec.Job, spec.Type)
+		return nil
+	}
+
+	name := githubCheckName
+	if name == "" {
+		name = spec.Job
+	}
+	run := checks.FromJUnit(name, spec.Refs.Pulls[0].SHA, detailsURL, spec.Refs.Repo, suites)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*2)
+	defer cancel()
+	client := utils.NewGithubClient(viper.GetString(types.GithubTokenEnv))
+	checkRun, err := checks.Publish(ctx, client, spec.Refs.Organization, spec.Refs.Repo, run)
+	if err != nil {
+		return err
+	}
+	klog.Infof("check run %q published to %s", name, checkRun.GetHTMLURL())
+	return nil
+}
@@ -4,23 +4,22 @@ import (
 	"bufio"
 	"encoding/xml"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
-	"github.com/konflux-ci/qe-tools/pkg/customjunit"
+	"github.com/konflux-ci/qe-tools/cmd/prowconfig"
+	"github.com/konflux-ci/qe-tools/pkg/junitnormalize"
 	"github.com/konflux-ci/qe-tools/pkg/types"
 
-	"github.com/GoogleCloudPlatform/testgrid/metadata"
+	"github.com/konflux-ci/qe-tools/pkg/classifier"
+	"github.com/konflux-ci/qe-tools/pkg/knownissues"
 	"github.com/konflux-ci/qe-tools/pkg/prow"
+	"github.com/konflux-ci/qe-tools/pkg/timeline"
 
 	"k8s.io/klog/v2"
-	"sigs.k8s.io/yaml"
 
 	reporters "github.com/onsi/ginkgo/v2/reporters"
-	ginkgoTypes "github.com/onsi/ginkgo/v2/types"
 
 	"github.com/redhat-appstudio-qe/junit2html/pkg/convert"
 
@@ -29,123 +28,156 @@ import (
 )
 
 var (
+	concurrency        int
 	formatReportPortal bool
+	jobName            string
+	maxArtifactSizeKB  int64
+	prowJobIDs         []string
+	prowJobURLs        []string
+	since              time.Duration
+	spoolDir           string
 	stepsToSkip        []string
+	tailLogs           bool
+	scanTimeout        time.Duration
+	provisioningStep   string
+	knownIssuesFile    string
+	testStep           string
 )
 
 const (
 	buildLogFilename = "build-log.txt"
 	finishedFilename = "finished.json"
+	startedFilename  = "started.json"
 
-	gcsBrowserURLPrefix = "https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/test-platform-results/"
-
+	prowJobURLParamName         = "prow-job-url"
+	jobNameParamName            = "job-name"
+	sinceParamName              = "since"
 	reportPortalFormatParamName = "report-portal-format"
 	stepsToSkipParamName        = "skip-ci-steps"
+	maxArtifactSizeParamName    = "max-artifact-size-kb"
+	tailLogsParamName           = "tail-logs"
+	spoolDirParamName           = "spool-dir"
+	concurrencyParamName        = "concurrency"
+	scanTimeoutParamName        = "scan-timeout"
+	provisioningStepParamName   = "provisioning-step-pattern"
+	testStepParamName           = "test-step-pattern"
 	openshiftCITestSuiteName    = "openshift-ci job"
 )
 
 // createReportCmd represents the createReport command
 var createReportCmd = &cobra.Command{
 	Use:   "create-report",
-	Short: "Analyze specified prow job and create a report in junit/html format",
+	Short: "Analyze specified prow job(s) and create a report in junit/html format",
+	Long: `Analyze specified prow job(s) and create a report in junit/html format.
+
+When multiple jobs are specified (via multiple IDs/URLs, or via the job name and the time window),
+a combined report is created, where test suites of each job are grouped by the job name and ID,
+and the "` + types.AggregateTestSuiteName + `" test suite contains the result of every job.
+
+Examples:
+  - Report for a single job:
+      qe-tools prowjob create-report --prow-job-id 1234
+  - Combined report for multiple jobs:
+      qe-tools prowjob create-report --prow-job-id 1234,5678
+  - Combined report for all runs of a periodic job within the last day:
+      qe-tools prowjob create-report --job-name periodic-ci-org-repo-main-e2e --since 24h
+  - Report for a single job, uploaded to Report Portal as a launch (with ` + types.ReportPortalTokenEnv + ` env var set):
+      qe-tools prowjob create-report --prow-job-id 1234 --report-portal-upload --report-portal-url https://reportportal.example.com --report-portal-project konflux
+`,
 	PreRunE: func(cmd *cobra.Command, _ []string) error {
-		if viper.GetString(types.ProwJobIDParamName) == "" {
+		if len(viper.GetStringSlice(types.ProwJobIDParamName)) == 0 && len(viper.GetStringSlice(prowJobURLParamName)) == 0 && viper.GetString(jobNameParamName) == "" {
 			_ = cmd.Usage()
-			return fmt.Errorf("parameter %q not provided, neither %s env var was set", types.ProwJobIDParamName, types.ProwJobIDEnv)
+			return fmt.Errorf("none of parameters %q, %q, %q provided, neither %s env var was set", types.ProwJobIDParamName, prowJobURLParamName, jobNameParamName, types.ProwJobIDEnv)
+		}
+		if githubCheck {
+			if viper.GetString(types.GithubTokenEnv) == "" {
+				return fmt.Errorf("%q flag provided, but %q env var not set", githubCheckParamName, types.GithubTokenEnv)
+			}
+			if viper.GetString(jobSpecParamName) == "" {
+				return fmt.Errorf("%q flag provided, but parameter %q not provided, neither %s env var was set", githubCheckParamName, jobSpecParamName, types.JobSpecEnv)
+			}
 		}
 		return nil
 	},
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		prowJobID = viper.GetString(types.ProwJobIDParamName)
 		stepsToSkip = viper.GetStringSlice(stepsToSkipParamName)
 
-		cfg := prow.ScannerConfig{
-			ProwJobID:      prowJobID,
-			FileNameFilter: []string{finishedFilename, buildLogFilename, types.JunitFilename},
-			StepsToSkip:    stepsToSkip,
+		instance, err := prowconfig.Instance()
+		if err != nil {
+			return err
+		}
+		source, err := prowconfig.NewSource(instance)
+		if err != nil {
+			return err
+		}
+		cfg, err := newScannerConfig(instance, source, stepsToSkip)
+		if err != nil {
+			return err
+		}
+		cfg.FileNameFilter = append(cfg.FileNameFilter, startedFilename, buildLogFilename)
+		cfg.MaxArtifactSize = viper.GetInt64(maxArtifactSizeParamName) * 1024
+		cfg.SpoolDir = viper.GetString(spoolDirParamName)
+		cfg.Concurrency = viper.GetInt(concurrencyParamName)
+		cfg.Timeout = viper.GetDuration(scanTimeoutParamName)
+		if viper.GetBool(tailLogsParamName) {
+			cfg.TailFileNameFilter = []string{buildLogFilename}
 		}
 
-		scanner, err := prow.NewArtifactScanner(cfg)
+		jobs, err := getJobsToReport(cfg)
 		if err != nil {
-			return fmt.Errorf("failed to initialize artifact scanner: %+v", err)
-		}
-
-		if err := scanner.Run(); err != nil {
-			return fmt.Errorf("failed to scan artifacts for prow job %s: %+v", prowJobID, err)
-		}
-
-		overallJUnitSuites := &reporters.JUnitTestSuites{}
-		openshiftCiJunit := reporters.JUnitTestSuite{Name: openshiftCITestSuiteName, Properties: reporters.JUnitProperties{Properties: []reporters.JUnitProperty{}}}
-
-		htmlReportLink := gcsBrowserURLPrefix + scanner.ArtifactDirectoryPrefix + "redhat-appstudio-report/artifacts/junit-summary.html"
-		openshiftCiJunit.Properties.Properties = append(openshiftCiJunit.Properties.Properties, reporters.JUnitProperty{Name: "html-report-link", Value: htmlReportLink})
-
-		for stepName, artifactsFilenameMap := range scanner.ArtifactStepMap {
-			for artifactFilename, artifact := range artifactsFilenameMap {
-				if artifactFilename == finishedFilename {
-					if strings.Contains(string(stepName), "gather") {
-						openshiftCiJunit.Properties.Properties = append(openshiftCiJunit.Properties.Properties, reporters.JUnitProperty{Name: string(stepName), Value: gcsBrowserURLPrefix + strings.TrimSuffix(artifact.FullName, finishedFilename) + "artifacts"})
-					}
-
-					finished := metadata.Finished{}
-					err = yaml.Unmarshal([]byte(artifact.Content), &finished)
-					if err != nil {
-						return fmt.Errorf("cannot unmarshal %s into finished struct: %+v", artifact.Content, err)
-					}
-
-					var buildLog string
-					if val, ok := artifactsFilenameMap[buildLogFilename]; ok {
-						buildLog = val.Content
-					}
-
-					if *finished.Passed {
-						openshiftCiJunit.TestCases = append(openshiftCiJunit.TestCases, reporters.JUnitTestCase{Name: string(stepName), Status: ginkgoTypes.SpecStatePassed.String(), SystemErr: buildLog})
-					} else {
-						failure := &reporters.JUnitFailure{Message: fmt.Sprintf("%s has failed", stepName)}
-						tc := reporters.JUnitTestCase{Name: string(stepName), Status: ginkgoTypes.SpecStateFailed.String(), Failure: failure, SystemErr: buildLog}
-						openshiftCiJunit.Failures++
-						openshiftCiJunit.TestCases = append(openshiftCiJunit.TestCases, tc)
-					}
-					openshiftCiJunit.Tests++
-				} else if strings.Contains(string(artifactFilename), ".xml") {
-					if err = xml.Unmarshal([]byte(artifact.Content), overallJUnitSuites); err != nil {
-						klog.Errorf("cannot decode JUnit suite %q into xml: %+v", artifactFilename, err)
-					}
-				}
-			}
+			return err
 		}
 
-		artifactDir := viper.GetString(types.ArtifactDirParamName)
-		if artifactDir == "" {
-			artifactDir = "./tmp/" + prowJobID
-			klog.Warningf("path to artifact dir was not provided - using default %q\n", artifactDir)
+		failureClassifier, err := classifier.FromConfig()
+		if err != nil {
+			return err
+		}
+		var knownIssues *knownissues.Store
+		if knownIssuesFile := viper.GetString(types.KnownIssuesFileParamName); knownIssuesFile != "" {
+			if knownIssues, err = knownissues.Load(knownIssuesFile); err != nil {
+				return err
+			}
+		}
+		opts := jobReportOptions{
+			Timeline: timeline.Options{
+				ProvisioningStepPattern: viper.GetString(provisioningStepParamName),
+				TestStepPattern:         viper.GetString(testStepParamName),
+			},
+			Classifier:  failureClassifier,
+			KnownIssues: knownIssues,
 		}
 
-		if err := os.MkdirAll(artifactDir, 0o750); err != nil {
-			return fmt.Errorf("failed to create directory for results '%s': %+v", artifactDir, err)
+		var jobReports []*jobReport
+		for _, job := range jobs {
+			jobCfg := cfg
+			jobCfg.ProwJobID, jobCfg.ProwJobURL = job.ID, job.URL
+			report, err := createJobReport(jobCfg, opts)
+			if err != nil {
+				return err
+			}
+			jobReports = append(jobReports, report)
 		}
 
-		// Add timestamp to openshift-ci job
-		if len(overallJUnitSuites.TestSuites) > 0 {
-			openshiftCiJunit.Timestamp = overallJUnitSuites.TestSuites[0].Timestamp
+		var overallJUnitSuites *reporters.JUnitTestSuites
+		artifactDir := viper.GetString(types.ArtifactDirParamName)
+		if len(jobReports) == 1 {
+			overallJUnitSuites = jobReports[0].Suites
+			if artifactDir == "" {
+				artifactDir = "./tmp/" + jobReports[0].ID
+			}
 		} else {
-			openshiftCiJunit.Timestamp = time.Now().Format("2006-01-02T15:04:05")
+			overallJUnitSuites = aggregateJobReports(jobReports)
+			if artifactDir == "" {
+				artifactDir = "./tmp/aggregate-" + time.Now().Format("20060102-150405")
+			}
+		}
+		if viper.GetString(types.ArtifactDirParamName) == "" {
+			klog.Warningf("path to artifact dir was not provided - using default %q\n", artifactDir)
 		}
 
-		overallJUnitSuites.TestSuites = append(overallJUnitSuites.TestSuites, openshiftCiJunit)
-		overallJUnitSuites.Failures += openshiftCiJunit.Failures
-		overallJUnitSuites.Errors += openshiftCiJunit.Errors
-		overallJUnitSuites.Tests += openshiftCiJunit.Tests
-
-		// Omit system-err from passed test cases
-		for i := range overallJUnitSuites.TestSuites {
-			for j := range overallJUnitSuites.TestSuites[i].TestCases {
-				tc := &overallJUnitSuites.TestSuites[i].TestCases[j]
-				if tc.Status == "passed" {
-					tc.SystemErr = ""
-				}
-			}
+		if err := os.MkdirAll(artifactDir, 0o750); err != nil {
+			return fmt.Errorf("failed to create directory for results '%s': %+v", artifactDir, err)
 		}
 
 		generatedJunitFilepath := filepath.Clean(artifactDir + "/junit.xml")
@@ -169,74 +201,121 @@ var createReportCmd = &cobra.Command{
 		klog.Infof("JUnit report saved to: %s/junit.xml", artifactDir)
 		klog.Infof("HTML report saved to: %s/junit-summary.html", artifactDir)
 
-		if formatReportPortal {
-			reportPortalSuites := &customjunit.TestSuites{}
-			if err := readXMLFile(fmt.Sprintf("%s/junit.xml", artifactDir), reportPortalSuites); err != nil {
-				return fmt.Errorf("could not read junit.xml file")
+		for _, report := range jobReports {
+			name := "timeline"
+			if len(jobReports) > 1 {
+				name += "-" + report.ID
+			}
+			if err := writeTimeline(report.Timeline, artifactDir, name); err != nil {
+				return err
+			}
+		}
+
+		if dbPath := viper.GetString(types.ResultsDBParamName); dbPath != "" {
+			if err := ingestJobReports(dbPath, jobReports); err != nil {
+				return err
 			}
+		}
 
-			changeDisabledToSkipped(overallJUnitSuites, reportPortalSuites)
+		if githubCheck {
+			detailsURL := ""
+			if len(jobReports) == 1 {
+				detailsURL = jobReports[0].HTMLReportURL
+			}
+			if err := publishCheckRun(overallJUnitSuites, detailsURL); err != nil {
+				return err
+			}
+		}
+
+		if formatReportPortal {
+			// the report is normalized, so that the results of non-Ginkgo test suites are reported correctly as well
+			normalized, err := junitnormalize.DecodeFile(generatedJunitFilepath)
+			if err != nil {
+				return err
+			}
 
 			generatedReportPortalFilepath := filepath.Clean(artifactDir + "/junit-rp.xml")
 			outRPFile, err := os.Create(generatedReportPortalFilepath)
 			if err != nil {
 				return fmt.Errorf("cannot create file '%s': %+v", generatedReportPortalFilepath, err)
 			}
+			defer outRPFile.Close()
 
-			if err := xml.NewEncoder(bufio.NewWriter(outRPFile)).Encode(reportPortalSuites); err != nil {
-				return fmt.Errorf("cannot encode JUnit suites struct '%+v' into file located at '%s': %+v", reportPortalSuites, generatedJunitFilepath, err)
+			if err := junitnormalize.EncodeXML(outRPFile, normalized.ReportPortal()); err != nil {
+				return fmt.Errorf("cannot write JUnit report for Report Portal into file located at '%s': %+v", generatedReportPortalFilepath, err)
 			}
 			klog.Infof("JUnit report for Report Portal saved to: %s/junit-rp.xml", artifactDir)
 		}
 
+		if reportPortalUpload {
+			if err := checkReportPortalParams(); err != nil {
+				klog.Warningf("skipping the upload to Report Portal: %+v", err)
+			} else if err := uploadToReportPortal(overallJUnitSuites, artifactDir, jobReports); err != nil {
+				klog.Warningf("%+v", err)
+			}
+		}
+
 		return nil
 	},
 }
 
-func readXMLFile(xmlPath string, result any) error {
-	xmlFile, err := os.Open(filepath.Clean(xmlPath))
+// writeTimeline saves the timeline as "<name>.json" and "<name>.html" files into the given directory
+func writeTimeline(t *timeline.Timeline, dir, name string) error {
+	timelineJSON, err := t.JSON()
 	if err != nil {
-		return fmt.Errorf("could not open file '%s', error: %v", xmlPath, err)
+		return fmt.Errorf("failed to marshal timeline: %+v", err)
 	}
-	defer xmlFile.Close()
-
-	xmlBytes, err := io.ReadAll(xmlFile)
+	if err := os.WriteFile(filepath.Join(dir, name+".json"), timelineJSON, 0o600); err != nil {
+		return fmt.Errorf("failed to create JSON file with timeline: %+v", err)
+	}
+	timelineHTML, err := t.HTML()
 	if err != nil {
 		return err
 	}
-
-	if err = xml.Unmarshal(xmlBytes, &result); err != nil {
-		klog.Errorf("cannot decode JUnit suite %q into xml: %+v", xmlPath, err)
+	if err := os.WriteFile(filepath.Join(dir, name+".html"), timelineHTML, 0o600); err != nil {
+		return fmt.Errorf("failed to create HTML file with timeline: %+v", err)
 	}
-
+	klog.Infof("timeline saved to: %s/%s.html", dir, name)
 	return nil
 }
 
-func changeDisabledToSkipped(original *reporters.JUnitTestSuites, custom *customjunit.TestSuites) {
-	totalSkipped := 0
-	for _, suite := range original.TestSuites {
-		if suite.Disabled != 0 {
-			for i := range custom.TestSuites {
-				if custom.TestSuites[i].Name == suite.Name {
-					custom.TestSuites[i].Skipped += suite.Disabled
-				}
-				totalSkipped += custom.TestSuites[i].Skipped
-			}
-		}
-	}
-	custom.Skipped = totalSkipped
-}
-
 func init() {
-	createReportCmd.Flags().StringVar(&prowJobID, types.ProwJobIDParamName, "", "Prow job ID to analyze")
+	createReportCmd.Flags().StringSliceVar(&prowJobIDs, types.ProwJobIDParamName, nil, "Prow job ID(s) to analyze")
+	createReportCmd.Flags().StringSliceVar(&prowJobURLs, prowJobURLParamName, nil, "Prow job URL(s) to analyze (alternative to --prow-job-id, doesn't require access to Prow API)")
+	createReportCmd.Flags().StringVar(&jobName, jobNameParamName, "", "Name of the Prow job whose runs within the time window (see --since) should be analyzed")
+	createReportCmd.Flags().DurationVar(&since, sinceParamName, 24*time.Hour, "Time window for selecting runs of the job specified via --job-name")
 	createReportCmd.Flags().BoolVar(&formatReportPortal, reportPortalFormatParamName, false, "Format for Report Portal")
-	createReportCmd.Flags().StringArrayVar(&stepsToSkip, stepsToSkipParamName, []string{"redhat-appstudio-report"}, "List of CI steps to skip when gathering artifacts")
+	createReportCmd.Flags().Int64Var(&maxArtifactSizeKB, maxArtifactSizeParamName, 0, "Maximum size (in KB) of each artifact's content kept in memory (0 means no limit)")
+	createReportCmd.Flags().BoolVar(&tailLogs, tailLogsParamName, false, "Keep the end of build logs (instead of the beginning) when they exceed --"+maxArtifactSizeParamName)
+	createReportCmd.Flags().StringVar(&spoolDir, spoolDirParamName, "", "Directory for storing full content of artifacts exceeding --"+maxArtifactSizeParamName)
+	createReportCmd.Flags().IntVar(&concurrency, concurrencyParamName, prow.DefaultConcurrency, "Maximum number of artifacts downloaded in parallel")
+	createReportCmd.Flags().DurationVar(&scanTimeout, scanTimeoutParamName, prow.DefaultTimeout, "Deadline for scanning and downloading all artifacts of the job")
+	createReportCmd.Flags().StringVar(&knownIssuesFile, types.KnownIssuesFileParamName, "", "Path to the YAML/JSON file with known issues matched against the failures")
+	createReportCmd.Flags().StringVar(&provisioningStep, provisioningStepParamName, timeline.DefaultProvisioningStepPattern, "Regular expression matching names of openshift-ci steps provisioning the test cluster (used in the timeline)")
+	createReportCmd.Flags().StringVar(&testStep, testStepParamName, timeline.DefaultTestStepPattern, "Regular expression matching names of openshift-ci steps executing the tests (used in the timeline)")
+	createReportCmd.Flags().BoolVar(&githubCheck, githubCheckParamName, false, "Publish the results as a GitHub check run on the head commit of the PR (requires "+types.GithubTokenEnv+" env var with a token of a GitHub App allowed to create check runs)")
+	createReportCmd.Flags().StringVar(&githubCheckName, githubCheckNameParamName, "", "Name of the GitHub check run (defaults to the job name)")
+	createReportCmd.Flags().String(jobSpecParamName, "", "Job spec (JSON) with the PR refs used for the GitHub check run (or "+types.JobSpecEnv+" env var)")
+	createReportCmd.Flags().StringArrayVar(&stepsToSkip, stepsToSkipParamName, []string{reportStepName}, "List of CI steps to skip when gathering artifacts")
 
 	_ = viper.BindPFlag(types.ArtifactDirParamName, createReportCmd.Flags().Lookup(types.ArtifactDirParamName))
 	_ = viper.BindPFlag(types.ProwJobIDParamName, createReportCmd.Flags().Lookup(types.ProwJobIDParamName))
+	_ = viper.BindPFlag(prowJobURLParamName, createReportCmd.Flags().Lookup(prowJobURLParamName))
+	_ = viper.BindPFlag(jobNameParamName, createReportCmd.Flags().Lookup(jobNameParamName))
+	_ = viper.BindPFlag(sinceParamName, createReportCmd.Flags().Lookup(sinceParamName))
 	_ = viper.BindPFlag(reportPortalFormatParamName, createReportCmd.Flags().Lookup(reportPortalFormatParamName))
 	_ = viper.BindPFlag(stepsToSkipParamName, createReportCmd.Flags().Lookup(stepsToSkipParamName))
+	_ = viper.BindPFlag(maxArtifactSizeParamName, createReportCmd.Flags().Lookup(maxArtifactSizeParamName))
+	_ = viper.BindPFlag(tailLogsParamName, createReportCmd.Flags().Lookup(tailLogsParamName))
+	_ = viper.BindPFlag(spoolDirParamName, createReportCmd.Flags().Lookup(spoolDirParamName))
+	_ = viper.BindPFlag(concurrencyParamName, createReportCmd.Flags().Lookup(concurrencyParamName))
+	_ = viper.BindPFlag(scanTimeoutParamName, createReportCmd.Flags().Lookup(scanTimeoutParamName))
+	_ = viper.BindPFlag(types.KnownIssuesFileParamName, createReportCmd.Flags().Lookup(types.KnownIssuesFileParamName))
+	_ = viper.BindPFlag(provisioningStepParamName, createReportCmd.Flags().Lookup(provisioningStepParamName))
+	_ = viper.BindPFlag(testStepParamName, createReportCmd.Flags().Lookup(testStepParamName))
 	// Bind environment variables to viper (in case the associated command's parameter is not provided)
 	_ = viper.BindEnv(types.ProwJobIDParamName, types.ProwJobIDEnv)
 	_ = viper.BindEnv(types.ArtifactDirParamName, types.ArtifactDirEnv)
+	_ = viper.BindPFlag(jobSpecParamName, createReportCmd.Flags().Lookup(jobSpecParamName))
+	_ = viper.BindEnv(jobSpecParamName, types.JobSpecEnv)
 }
@@ -0,0 +1,140 @@
+package prowjob
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/konflux-ci/qe-tools/cmd/prowconfig"
+	"github.com/konflux-ci/qe-tools/pkg/flakes"
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+	"github.com/konflux-ci/qe-tools/pkg/types"
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+const (
+	flipWindowParamName = "flip-window"
+	minRunsParamName    = "min-runs"
+
+	outputFormatMarkdown = "markdown"
+	outputFormatJUnit    = "junit"
+)
+
+var (
+	flakesJobName    string
+	flakesSince      time.Duration
+	flakesLimit      int
+	flakesFlipWindow time.Duration
+	flakesMinRuns    int
+	flakesOutput     string
+	flakesOutputFile string
+)
+
+// flakesCmd represents the flakes command
+var flakesCmd = &cobra.Command{
+	Use:   "flakes",
+	Short: "Detect flaky tests across recent runs of a Prow job",
+	Long: `Detect flaky tests across recent runs of a Prow job.
+
+JUnit reports (and openshift-ci steps) of the recent finished runs of the job are analyzed and test cases,
+whose outcome flipped between runs testing the same revision or between runs started within
+the --` + flipWindowParamName + ` (or that both failed and passed within a single run), are reported - ranked by their flakiness score.
+
+Examples:
+  - Flaky tests within the last 20 runs of a periodic job, as a Markdown table:
+      qe-tools prowjob flakes --job-name periodic-ci-org-repo-main-e2e --limit 20 --output markdown
+`,
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		if flakesJobName == "" {
+			_ = cmd.Usage()
+			return fmt.Errorf("parameter %q not provided", jobNameParamName)
+		}
+		switch flakesOutput {
+		case outputFormatJSON, outputFormatMarkdown, outputFormatJUnit:
+			return nil
+		}
+		return fmt.Errorf("unsupported output format %q (supported: %s, %s, %s)", flakesOutput, outputFormatJSON, outputFormatMarkdown, outputFormatJUnit)
+	},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		instance, err := prowconfig.Instance()
+		if err != nil {
+			return err
+		}
+		source, err := prowconfig.NewSource(instance)
+		if err != nil {
+			return err
+		}
+		cfg, err := newScannerConfig(instance, source, []string{reportStepName})
+		if err != nil {
+			return err
+		}
+
+		filter := prow.JobRunFilter{Limit: flakesLimit, Results: []prow.JobResult{prow.JobResultSuccess, prow.JobResultFailure}}
+		if flakesSince > 0 {
+			filter.Since = time.Now().Add(-flakesSince)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), prow.DefaultTimeout)
+		defer cancel()
+		jobRuns, err := prow.ListJobRuns(ctx, source, instance, flakesJobName, filter)
+		if err != nil {
+			return err
+		}
+		klog.Infof("analyzing %d run(s) of job %s", len(jobRuns), flakesJobName)
+
+		var runs []flakes.Run
+		for _, jobRun := range jobRuns {
+			jobCfg := cfg
+			jobCfg.ProwJobURL = jobRun.URL
+			report, err := createJobReport(jobCfg, jobReportOptions{})
+			if err != nil {
+				klog.Warningf("skipping run %s: %+v", jobRun.ID, err)
+				continue
+			}
+			runs = append(runs, flakes.NewRun(jobRun.ID, jobRun.URL, jobRun.Revision, jobRun.Started, report.Suites))
+		}
+
+		flaky := flakes.Detect(runs, flakes.Options{Window: flakesFlipWindow, MinRuns: flakesMinRuns})
+		klog.Infof("found %d flaky test(s) within %d analyzed run(s)", len(flaky), len(runs))
+
+		var output []byte
+		switch flakesOutput {
+		case outputFormatJSON:
+			if output, err = flakes.FormatJSON(flaky); err != nil {
+				return fmt.Errorf("failed to marshal flaky tests: %+v", err)
+			}
+		case outputFormatMarkdown:
+			output = []byte(flakes.FormatMarkdown(flakesJobName, len(runs), flaky))
+		case outputFormatJUnit:
+			suites := reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{flakes.JUnitTestSuite(flaky)}}
+			if output, err = xml.MarshalIndent(suites, "", "  "); err != nil {
+				return fmt.Errorf("failed to marshal flaky tests: %+v", err)
+			}
+			output = append([]byte(xml.Header), output...)
+		}
+
+		if flakesOutputFile == "" {
+			fmt.Println(string(output))
+			return nil
+		}
+		if err := os.WriteFile(flakesOutputFile, output, 0o600); err != nil {
+			return fmt.Errorf("failed to create a file with flaky tests: %+v", err)
+		}
+		klog.Infof("flaky tests saved to %s", flakesOutputFile)
+		return nil
+	},
+}
+
+func init() {
+	flakesCmd.Flags().StringVar(&flakesJobName, jobNameParamName, "", "Name of the Prow job")
+	flakesCmd.Flags().DurationVar(&flakesSince, sinceParamName, 7*24*time.Hour, "Time window for selecting runs of the job (0 means no limit)")
+	flakesCmd.Flags().IntVar(&flakesLimit, limitParamName, 20, "Maximum number of analyzed runs (0 means no limit)")
+	flakesCmd.Flags().DurationVar(&flakesFlipWindow, flipWindowParamName, 24*time.Hour, "Maximum time between two consecutive runs for their outcome flip to be considered a flake (0 means no limit)")
+	flakesCmd.Flags().IntVar(&flakesMinRuns, minRunsParamName, 2, "Minimum number of runs a test has to be executed in to be analyzed")
+	flakesCmd.Flags().StringVar(&flakesOutput, outputParamName, outputFormatMarkdown, "Output format (json, markdown, junit)")
+	flakesCmd.Flags().StringVar(&flakesOutputFile, types.OutputFilenameParamName, "", "A name of the file to store the flaky tests in (printed to stdout if empty)")
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/google/go-github/v56/github"
 	"github.com/konflux-ci/qe-tools/pkg/status"
+	"github.com/konflux-ci/qe-tools/pkg/utils"
 )
 
 const (
@@ -132,7 +133,7 @@ var healthCheckCmd = &cobra.Command{
 
 			if viper.GetBool(notifyOnPRParamName) {
 				prMessage := buildPRMessage(hcStatus, failIfUnhealthy)
-				githubClient := github.NewClient(http.DefaultClient).WithAuthToken(viper.GetString(types.GithubTokenEnv))
+				githubClient := utils.NewGithubClient(viper.GetString(types.GithubTokenEnv))
 				prNumberInt, _ := strconv.Atoi(viper.GetString(prowUtils.PullNumberEnv))
 				comment, _, err := githubClient.Issues.CreateComment(
 					context.Background(),
@@ -0,0 +1,81 @@
+package prowjob
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/konflux-ci/qe-tools/pkg/history"
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+	"github.com/konflux-ci/qe-tools/pkg/testresults"
+	"github.com/konflux-ci/qe-tools/pkg/types"
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+var resultsDB string
+
+// ingestJobReports stores results of the job reports into the results database, so that they can be queried
+// later via "qe-tools results query"
+func ingestJobReports(dbPath string, jobReports []*jobReport) error {
+	var spec *prow.OpenshiftJobSpec
+	if jobSpec := viper.GetString(jobSpecParamName); jobSpec != "" {
+		var err error
+		if spec, err = prow.ParseJobSpec(jobSpec); err != nil {
+			klog.Warningf("failed to parse job spec - the job metadata won't be stored: %+v", err)
+		}
+	}
+
+	store, err := history.Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	for _, report := range jobReports {
+		testCases, err := historyTestCases(report.Suites)
+		if err != nil {
+			return fmt.Errorf("failed to convert results of job %s: %+v", report.ID, err)
+		}
+		run := history.Run{
+			ID:        report.ID,
+			Source:    history.CreateReportSource,
+			JobName:   report.Name,
+			URL:       report.URL,
+			TestCases: testCases,
+		}
+		if report.Timeline != nil {
+			run.Timestamp = report.Timeline.Started
+		}
+		// the job spec describes only the job create-report is executed in
+		if spec != nil && spec.Job == report.Name {
+			run.SetJobSpec(spec)
+		}
+		if err := store.Ingest(run); err != nil {
+			return fmt.Errorf("failed to store results of job %s: %+v", report.ID, err)
+		}
+		klog.Infof("results of job %s stored in %s", report.ID, dbPath)
+	}
+	return nil
+}
+
+// historyTestCases returns results of the test cases within the job report. The JUnit types of pkg/history are
+// shared with pkg/testresults, so the report is converted to them through its XML representation
+func historyTestCases(suites *reporters.JUnitTestSuites) ([]history.TestCase, error) {
+	content, err := xml.Marshal(suites)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := testresults.DecodeJUnit(string(content))
+	if err != nil {
+		return nil, err
+	}
+	return history.TestCasesFromJUnit(converted), nil
+}
+
+func init() {
+	createReportCmd.Flags().StringVar(&resultsDB, types.ResultsDBParamName, "", "Path to the results database the results of the job(s) should be stored into (or "+types.ResultsDBEnv+" env var)")
+
+	_ = viper.BindPFlag(types.ResultsDBParamName, createReportCmd.Flags().Lookup(types.ResultsDBParamName))
+	_ = viper.BindEnv(types.ResultsDBParamName, types.ResultsDBEnv)
+}
@@ -0,0 +1,319 @@
+package prowjob
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/testgrid/metadata"
+	"github.com/konflux-ci/qe-tools/pkg/classifier"
+	"github.com/konflux-ci/qe-tools/pkg/junitutil"
+	"github.com/konflux-ci/qe-tools/pkg/knownissues"
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+	"github.com/konflux-ci/qe-tools/pkg/timeline"
+	"github.com/konflux-ci/qe-tools/pkg/types"
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+	ginkgoTypes "github.com/onsi/ginkgo/v2/types"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// jobRef identifies a Prow job run by either its ID or URL
+type jobRef struct {
+	ID  string
+	URL string
+}
+
+// jobReport holds the JUnit report created from the artifacts of a single Prow job run
+type jobReport struct {
+	ID     string
+	Name   string
+	URL    string
+	Suites *reporters.JUnitTestSuites
+	// HTMLReportURL links the HTML report published in the job's artifacts
+	HTMLReportURL string
+	// Timeline of the openshift-ci steps of the job
+	Timeline *timeline.Timeline
+}
+
+// jobReportOptions configure the analysis of the job's artifacts within createJobReport
+type jobReportOptions struct {
+	Timeline timeline.Options
+	// Classifier is used for classifying failed steps and test cases - no classification is done if nil
+	Classifier *classifier.Classifier
+	// KnownIssues are matched against failed steps and test cases - no matching is done if nil
+	KnownIssues *knownissues.Store
+}
+
+// getJobsToReport returns the Prow job runs selected via command line parameters: either
+// the list of Prow job IDs/URLs, or the job name and the time window
+func getJobsToReport(cfg prow.ScannerConfig) ([]jobRef, error) {
+	var jobs []jobRef
+	for _, id := range viper.GetStringSlice(types.ProwJobIDParamName) {
+		jobs = append(jobs, jobRef{ID: id})
+	}
+	for _, url := range viper.GetStringSlice(prowJobURLParamName) {
+		jobs = append(jobs, jobRef{URL: url})
+	}
+
+	if jobName := viper.GetString(jobNameParamName); jobName != "" {
+		since := time.Now().Add(-viper.GetDuration(sinceParamName))
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+		defer cancel()
+		runs, err := prow.ListJobRuns(ctx, cfg.Source, cfg.Instance, jobName, prow.JobRunFilter{Since: since})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list runs of job %s: %+v", jobName, err)
+		}
+		if len(runs) == 0 {
+			return nil, fmt.Errorf("no runs of job %s found since %s", jobName, since.Format(time.RFC3339))
+		}
+		klog.Infof("found %d run(s) of job %s since %s", len(runs), jobName, since.Format(time.RFC3339))
+		for _, run := range runs {
+			jobs = append(jobs, jobRef{URL: run.URL})
+		}
+	}
+
+	return jobs, nil
+}
+
+// createJobReport scans the artifacts of the Prow job defined within the given config and creates
+// a JUnit report containing all JUnit suites found in the artifacts, plus the "openshift-ci job" suite
+// with a test case for every openshift-ci step (including its duration, if started.json of the step is available)
+func createJobReport(cfg prow.ScannerConfig, opts jobReportOptions) (*jobReport, error) {
+	jobID := cfg.ProwJobID
+	if jobID == "" {
+		// Prow job URL ends with the build ID, e.g. ".../pull-ci-org-repo-main-e2e/1234567890"
+		jobID = path.Base(strings.TrimSuffix(cfg.ProwJobURL, "/"))
+	}
+
+	scanner, err := prow.NewArtifactScanner(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize artifact scanner: %+v", err)
+	}
+
+	// the deadline of the scan applies to reading the full content of truncated JUnit files as well
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+	if err := scanner.RunWithContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to scan artifacts for prow job %s: %+v", jobID, err)
+	}
+
+	overallJUnitSuites := &reporters.JUnitTestSuites{}
+	openshiftCiJunit := reporters.JUnitTestSuite{Name: openshiftCITestSuiteName, Properties: reporters.JUnitProperties{Properties: []reporters.JUnitProperty{}}}
+
+	htmlReportLink := cfg.Instance.ArtifactBrowserURL + scanner.ArtifactDirectoryPrefix + reportStepName + "/artifacts/junit-summary.html"
+	openshiftCiJunit.Properties.Properties = append(openshiftCiJunit.Properties.Properties, reporters.JUnitProperty{Name: "html-report-link", Value: htmlReportLink})
+
+	var steps []timeline.Step
+	var failedSteps []failedItem
+	for stepName, artifactsFilenameMap := range scanner.ArtifactStepMap {
+		for artifactFilename, artifact := range artifactsFilenameMap {
+			if artifactFilename == finishedFilename {
+				if strings.Contains(string(stepName), "gather") {
+					openshiftCiJunit.Properties.Properties = append(openshiftCiJunit.Properties.Properties, reporters.JUnitProperty{Name: string(stepName), Value: cfg.Instance.ArtifactBrowserURL + strings.TrimSuffix(artifact.FullName, finishedFilename) + "artifacts"})
+				}
+
+				finished := metadata.Finished{}
+				err = yaml.Unmarshal([]byte(artifact.Content), &finished)
+				if err != nil {
+					return nil, fmt.Errorf("cannot unmarshal %s into finished struct: %+v", artifact.Content, err)
+				}
+
+				var buildLog string
+				if val, ok := artifactsFilenameMap[buildLogFilename]; ok {
+					buildLog = val.Content
+				}
+
+				step := stepTiming(string(stepName), artifactsFilenameMap[startedFilename], finished)
+				steps = append(steps, step)
+
+				if *finished.Passed {
+					openshiftCiJunit.TestCases = append(openshiftCiJunit.TestCases, reporters.JUnitTestCase{Name: string(stepName), Status: ginkgoTypes.SpecStatePassed.String(), SystemErr: buildLog, Time: step.Duration.Seconds()})
+				} else {
+					failedSteps = append(failedSteps, failedItem{name: string(stepName), buildLog: buildLog, step: true})
+					failure := &reporters.JUnitFailure{Message: fmt.Sprintf("%s has failed", stepName)}
+					tc := reporters.JUnitTestCase{Name: string(stepName), Status: ginkgoTypes.SpecStateFailed.String(), Failure: failure, SystemErr: buildLog, Time: step.Duration.Seconds()}
+					openshiftCiJunit.Failures++
+					openshiftCiJunit.TestCases = append(openshiftCiJunit.TestCases, tc)
+				}
+				openshiftCiJunit.Tests++
+			} else if strings.Contains(string(artifactFilename), ".xml") {
+				suites, err := decodeJUnitArtifact(ctx, artifact)
+				if err != nil {
+					klog.Errorf("cannot decode JUnit suite %q into xml: %+v", artifactFilename, err)
+					continue
+				}
+				klog.Infof("merging %d JUnit suite(s) from %s/%s", len(suites.TestSuites), stepName, artifactFilename)
+				junitutil.MergeSuites(overallJUnitSuites, suites)
+			}
+		}
+	}
+
+	jobTimeline, err := timeline.New(steps, opts.Timeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create timeline of prow job %s: %+v", jobID, err)
+	}
+	jobTimeline.JobID, jobTimeline.JobName, jobTimeline.JobURL = jobID, scanner.JobName, scanner.ProwJobURL
+	openshiftCiJunit.Time = jobTimeline.Duration.Seconds()
+
+	// Add timestamp to openshift-ci job
+	switch {
+	case !jobTimeline.Started.IsZero():
+		openshiftCiJunit.Timestamp = jobTimeline.Started.UTC().Format("2006-01-02T15:04:05")
+	case len(overallJUnitSuites.TestSuites) > 0:
+		openshiftCiJunit.Timestamp = overallJUnitSuites.TestSuites[0].Timestamp
+	default:
+		openshiftCiJunit.Timestamp = time.Now().Format("2006-01-02T15:04:05")
+	}
+
+	newFailures, knownFailures := annotateFailures(opts, &openshiftCiJunit, failedSteps)
+	for i := range overallJUnitSuites.TestSuites {
+		suite := &overallJUnitSuites.TestSuites[i]
+		var failedTestCases []failedItem
+		for _, tc := range suite.TestCases {
+			if tc.Failure != nil || tc.Error != nil {
+				failedTestCases = append(failedTestCases, failedItem{name: tc.Name, message: junitutil.FailureDetails(tc), buildLog: tc.SystemErr})
+			}
+		}
+		n, k := annotateFailures(opts, suite, failedTestCases)
+		newFailures, knownFailures = newFailures+n, knownFailures+k
+	}
+	if opts.KnownIssues != nil {
+		openshiftCiJunit.Properties.Properties = append(openshiftCiJunit.Properties.Properties,
+			reporters.JUnitProperty{Name: "new-failures", Value: strconv.Itoa(newFailures)},
+			reporters.JUnitProperty{Name: "known-failures", Value: strconv.Itoa(knownFailures)},
+		)
+	}
+
+	overallJUnitSuites.TestSuites = append(overallJUnitSuites.TestSuites, openshiftCiJunit)
+	overallJUnitSuites.Failures += openshiftCiJunit.Failures
+	overallJUnitSuites.Errors += openshiftCiJunit.Errors
+	overallJUnitSuites.Tests += openshiftCiJunit.Tests
+
+	// Omit system-err from passed test cases
+	for i := range overallJUnitSuites.TestSuites {
+		for j := range overallJUnitSuites.TestSuites[i].TestCases {
+			tc := &overallJUnitSuites.TestSuites[i].TestCases[j]
+			if tc.Status == "passed" {
+				tc.SystemErr = ""
+			}
+		}
+	}
+
+	return &jobReport{ID: jobID, Name: scanner.JobName, URL: scanner.ProwJobURL, Suites: overallJUnitSuites, HTMLReportURL: htmlReportLink, Timeline: jobTimeline}, nil
+}
+
+// failedItem represents a failed openshift-ci step or test case
+type failedItem struct {
+	name     string
+	message  string
+	buildLog string
+	// step is set for failed openshift-ci steps, whose names are matched by the step-name classification rules
+	step bool
+}
+
+// annotateFailures adds the classification and matching known issues of every given failure (of a test case
+// or step within the suite) to the properties of the suite. Numbers of new and known failures are returned
+func annotateFailures(opts jobReportOptions, suite *reporters.JUnitTestSuite, failures []failedItem) (newFailures, knownFailures int) {
+	for _, f := range failures {
+		if opts.Classifier != nil {
+			evidence := classifier.Evidence{FailureMessage: f.message, BuildLog: f.buildLog}
+			if f.step {
+				evidence.StepName = f.name
+			}
+			c := opts.Classifier.Classify(evidence)
+			suite.Properties.Properties = append(suite.Properties.Properties, reporters.JUnitProperty{Name: "classification/" + f.name, Value: c.String()})
+		}
+		message := f.message
+		if message == "" {
+			message = f.buildLog
+		}
+		issues := opts.KnownIssues.Match(f.name, message)
+		if len(issues) == 0 {
+			newFailures++
+			continue
+		}
+		knownFailures++
+		var matched []string
+		for _, issue := range issues {
+			matched = append(matched, issue.String())
+		}
+		suite.Properties.Properties = append(suite.Properties.Properties, reporters.JUnitProperty{Name: "known-issues/" + f.name, Value: strings.Join(matched, "; ")})
+	}
+	return newFailures, knownFailures
+}
+
+// stepTiming returns the timing of the openshift-ci step based on its started.json and finished.json.
+// The start (and the duration) of the step is unknown if the started.json is missing
+func stepTiming(name string, startedArtifact prow.Artifact, finished metadata.Finished) timeline.Step {
+	step := timeline.Step{Name: name, Passed: finished.Passed != nil && *finished.Passed}
+	if finished.Timestamp != nil {
+		step.Finished = time.Unix(*finished.Timestamp, 0)
+	}
+	if startedArtifact.Content != "" {
+		started := metadata.Started{}
+		if err := yaml.Unmarshal([]byte(startedArtifact.Content), &started); err != nil {
+			klog.Warningf("cannot unmarshal %s of step %s: %+v", startedFilename, name, err)
+		} else if started.Timestamp > 0 {
+			step.Started = time.Unix(started.Timestamp, 0)
+		}
+	}
+	if !step.Started.IsZero() && !step.Finished.IsZero() {
+		step.Duration = step.Finished.Sub(step.Started)
+	}
+	return step
+}
+
+// aggregateJobReports combines reports of multiple Prow job runs into a single JUnit report.
+// Suites of each job are grouped by the "<job-name> #<job-id>" prefix of their name and the
+// additional "aggregate summary" suite contains a test case with the result of every job
+func aggregateJobReports(reports []*jobReport) *reporters.JUnitTestSuites {
+	aggregated := &reporters.JUnitTestSuites{}
+	summary := reporters.JUnitTestSuite{
+		Name:       types.AggregateTestSuiteName,
+		Timestamp:  time.Now().Format("2006-01-02T15:04:05"),
+		Properties: reporters.JUnitProperties{Properties: []reporters.JUnitProperty{}},
+	}
+
+	for _, report := range reports {
+		group := fmt.Sprintf("%s #%s", report.Name, report.ID)
+		jobProperties := []reporters.JUnitProperty{
+			{Name: "job-name", Value: report.Name},
+			{Name: "job-id", Value: report.ID},
+			{Name: "job-url", Value: report.URL},
+		}
+
+		for _, suite := range report.Suites.TestSuites {
+			suite.Name = group + ": " + suite.Name
+			suite.Properties.Properties = append(jobProperties, suite.Properties.Properties...)
+			aggregated.TestSuites = append(aggregated.TestSuites, suite)
+		}
+		aggregated.Tests += report.Suites.Tests
+		aggregated.Disabled += report.Suites.Disabled
+		aggregated.Errors += report.Suites.Errors
+		aggregated.Failures += report.Suites.Failures
+		aggregated.Time += report.Suites.Time
+
+		tc := reporters.JUnitTestCase{Name: group, Classname: report.Name, SystemOut: report.URL}
+		if failed := report.Suites.Failures + report.Suites.Errors; failed > 0 {
+			tc.Status = ginkgoTypes.SpecStateFailed.String()
+			tc.Failure = &reporters.JUnitFailure{Message: fmt.Sprintf("%d of %d test case(s) didn't pass - see %s", failed, report.Suites.Tests, report.URL)}
+			summary.Failures++
+		} else {
+			tc.Status = ginkgoTypes.SpecStatePassed.String()
+		}
+		summary.TestCases = append(summary.TestCases, tc)
+		summary.Tests++
+		summary.Properties.Properties = append(summary.Properties.Properties, reporters.JUnitProperty{Name: group, Value: report.URL})
+	}
+
+	aggregated.TestSuites = append([]reporters.JUnitTestSuite{summary}, aggregated.TestSuites...)
+	aggregated.Tests += summary.Tests
+	aggregated.Failures += summary.Failures
+
+	return aggregated
+}
@@ -0,0 +1,26 @@
+package prowjob
+
+import (
+	"context"
+
+	"github.com/konflux-ci/qe-tools/pkg/junitnormalize"
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+// decodeJUnitArtifact decodes JUnit XML from the artifact (the full content is streamed within the given
+// context if the artifact's content was truncated by the scanner). Reports of any producer supported by
+// junitnormalize are converted to the Ginkgo flavour the report is built from
+func decodeJUnitArtifact(ctx context.Context, artifact prow.Artifact) (*reporters.JUnitTestSuites, error) {
+	rc, err := artifact.Open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	report, err := junitnormalize.Decode(rc)
+	if err != nil {
+		return nil, err
+	}
+	return report.Ginkgo(), nil
+}
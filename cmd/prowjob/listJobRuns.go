@@ -0,0 +1,109 @@
+package prowjob
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/konflux-ci/qe-tools/cmd/prowconfig"
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+	"github.com/spf13/cobra"
+)
+
+const (
+	resultParamName = "result"
+	limitParamName  = "limit"
+	outputParamName = "output"
+
+	outputFormatTable = "table"
+	outputFormatJSON  = "json"
+)
+
+var (
+	listJobName string
+	listSince   time.Duration
+	listResults []string
+	listLimit   int
+	listOutput  string
+)
+
+// listJobRunsCmd represents the list command
+var listJobRunsCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recent runs of a periodic/presubmit Prow job",
+	Long: `List recent runs of a periodic/presubmit Prow job, newest first.
+
+Runs are read from the bucket of the Prow instance ("logs/<job>/" for periodic jobs,
+"pr-logs/directory/<job>/" for presubmit jobs).
+
+Examples:
+  - Failed runs of a periodic job within the last week:
+      qe-tools prowjob list --job-name periodic-ci-org-repo-main-e2e --since 168h --result failure
+  - The latest 5 runs of a presubmit job in JSON:
+      qe-tools prowjob list --job-name pull-ci-org-repo-main-e2e --limit 5 --output json
+`,
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		if listJobName == "" {
+			_ = cmd.Usage()
+			return fmt.Errorf("parameter %q not provided", jobNameParamName)
+		}
+		if listOutput != outputFormatTable && listOutput != outputFormatJSON {
+			return fmt.Errorf("unsupported output format %q (supported: %s, %s)", listOutput, outputFormatTable, outputFormatJSON)
+		}
+		return nil
+	},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		instance, err := prowconfig.Instance()
+		if err != nil {
+			return err
+		}
+		source, err := prowconfig.NewSource(instance)
+		if err != nil {
+			return err
+		}
+
+		filter := prow.JobRunFilter{Limit: listLimit}
+		if listSince > 0 {
+			filter.Since = time.Now().Add(-listSince)
+		}
+		for _, r := range listResults {
+			filter.Results = append(filter.Results, prow.JobResult(strings.ToUpper(r)))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), prow.DefaultTimeout)
+		defer cancel()
+		runs, err := prow.ListJobRuns(ctx, source, instance, listJobName, filter)
+		if err != nil {
+			return err
+		}
+
+		if listOutput == outputFormatJSON {
+			o, err := json.MarshalIndent(runs, "", "    ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal job runs: %+v", err)
+			}
+			fmt.Println(string(o))
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tSTARTED\tDURATION\tRESULT\tURL")
+		for _, run := range runs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", run.ID, run.Started.Format(time.RFC3339), run.Duration().Round(time.Second), run.Result, run.URL)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	listJobRunsCmd.Flags().StringVar(&listJobName, jobNameParamName, "", "Name of the Prow job")
+	listJobRunsCmd.Flags().DurationVar(&listSince, sinceParamName, 24*time.Hour, "Time window for selecting runs of the job (0 means no limit)")
+	listJobRunsCmd.Flags().StringSliceVar(&listResults, resultParamName, nil, "Result(s) of the listed runs (success, failure, aborted, pending)")
+	listJobRunsCmd.Flags().IntVar(&listLimit, limitParamName, 0, "Maximum number of listed runs (0 means no limit)")
+	listJobRunsCmd.Flags().StringVar(&listOutput, outputParamName, outputFormatTable, "Output format (table, json)")
+}
@@ -1,22 +1,49 @@
 package prowjob
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
-	"regexp"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/konflux-ci/qe-tools/cmd/prowconfig"
+	"github.com/konflux-ci/qe-tools/pkg/jobsummary"
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+	"github.com/konflux-ci/qe-tools/pkg/utils"
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+	"k8s.io/klog/v2"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+const (
+	outputFormatText  = "text"
+	outputFormatSlack = "slack"
+)
+
+var (
+	periodicReportJobName string
+	periodicReportOutput  string
+)
+
 // periodicReportCmd returns the periodic-report command
 var periodicReportCmd = &cobra.Command{
 	Use:   "periodic-report",
-	Short: "Analyzes the build log from latest ci jobs and returns a short job summary",
+	Short: "Analyzes the results of the latest ci job and returns a short job summary",
 	PreRunE: func(cmd *cobra.Command, args []string) error {
+		switch periodicReportOutput {
+		case outputFormatText, outputFormatMarkdown, outputFormatSlack, outputFormatJSON:
+		default:
+			return fmt.Errorf("unsupported output format %q (supported: %s, %s, %s, %s)", periodicReportOutput, outputFormatText, outputFormatMarkdown, outputFormatSlack, outputFormatJSON)
+		}
+		if periodicReportJobName != "" {
+			return nil
+		}
 		requiredEnvVars := []string{"prow_url"}
 
 		for _, e := range requiredEnvVars {
@@ -29,11 +56,6 @@ var periodicReportCmd = &cobra.Command{
 	RunE: run,
 }
 
-func removeANSIEscapeSequences(text string) string {
-	regex := regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]`)
-	return regex.ReplaceAllString(text, "")
-}
-
 func fetchTextContent(url string) (string, error) {
 	// #nosec G107
 	resp, err := http.Get(url)
@@ -49,77 +71,144 @@ func fetchTextContent(url string) (string, error) {
 
 	bodyString := string(bodyBytes)
 
-	cleanedString := removeANSIEscapeSequences(bodyString)
+	cleanedString := utils.RemoveANSIEscapeSequences(bodyString)
 
 	return cleanedString, nil
 }
 
-func constructMessage(bodyString string) (string, bool) {
-	failureMatches := regexp.MustCompile(`(?s)(Summarizing.*?Test Suite Failed)`).FindStringSubmatch(bodyString)
-
-	if isJobFailed(bodyString) || failureMatches != nil {
-		message := "Test Suite Summary:\n"
-		message += extractTestResultsAndSummary(bodyString)
-		message += extractDuration(bodyString)
-		message += formatFailures(failureMatches[1])
-		return message, false
+// fetchBuildLog returns the content of the build log from the given location. Locations within
+// the bucket of the configured Prow instance are read via the configured artifact source, others via HTTP
+func fetchBuildLog(cfg prow.ScannerConfig, buildLogURL string) (string, error) {
+	objectPath, err := cfg.Instance.ObjectPathFromURL(buildLogURL)
+	if err != nil {
+		return fetchTextContent(buildLogURL)
 	}
-	return "Job Succeeded", true
-}
 
-func isJobFailed(body string) bool {
-	stateRegexp := regexp.MustCompile(`Reporting job state '(\w+)'`)
-	stateMatches := stateRegexp.FindStringSubmatch(body)
-
-	return len(stateMatches) == 2 && stateMatches[1] == "failed"
-}
-
-func extractTestResultsAndSummary(body string) string {
-	pattern := `Ran (\d+) of (\d+) Specs in ([\d.]+) seconds\nFAIL! -- (\d+) Passed \| (\d+) Failed \| (\d+) Pending \| (\d+) Skipped`
-	matches := regexp.MustCompile(pattern).FindStringSubmatch(body)
-
-	if matches == nil {
-		return "Infrastructure setup issues or failures unrelated to tests were found\n"
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+	rc, err := cfg.Source.Open(ctx, objectPath)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	bodyBytes, err := io.ReadAll(rc)
+	if err != nil {
+		return "", fmt.Errorf("error reading the build log content: %w", err)
 	}
 
-	return fmt.Sprintf("Test Results: %s Passed | %s Failed | %s Pending | %s Skipped\nRan %s of %s Specs in %s seconds\n",
-		matches[4], matches[5], matches[6], matches[7], matches[1], matches[2], matches[3])
+	return utils.RemoveANSIEscapeSequences(string(bodyBytes)), nil
 }
 
-func extractDuration(body string) string {
-	matches := regexp.MustCompile(`Ran for ([\dhms]+)`).FindStringSubmatch(body)
-	if matches == nil {
-		return ""
+// getPeriodicReportJobRun returns details of the job run with the given URL, or of the latest run of the given job if the URL is empty
+func getPeriodicReportJobRun(cfg prow.ScannerConfig, jobURL, job string) (*prow.JobRun, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+	if jobURL == "" {
+		return prow.LatestJobRun(ctx, cfg.Source, cfg.Instance, job)
 	}
-	return fmt.Sprintf("Total Duration: %s\n", matches[1])
+	return prow.GetJobRun(ctx, cfg.Source, cfg.Instance, jobURL)
 }
 
-func formatFailures(failures string) string {
-	var formattedFailures strings.Builder
-	formattedFailures.WriteString("Failures:\n")
+// createJobSummary creates the summary of the given job run. Results from JUnit artifacts are preferred -
+// the build log is parsed only if there are no JUnit artifacts available
+func createJobSummary(cfg prow.ScannerConfig, jobURL string) (*jobsummary.Summary, error) {
+	cfg.ProwJobURL = jobURL
+	report, err := createJobReport(cfg, jobReportOptions{})
+	if err != nil {
+		klog.Warningf("failed to collect JUnit artifacts, falling back to the build log: %+v", err)
+		report = &jobReport{Suites: &reporters.JUnitTestSuites{}}
+	}
 
-	for _, line := range strings.Split(failures, "\n") {
-		if strings.Contains(line, "[FAIL]") {
-			formattedFailures.WriteString("- " + strings.TrimSpace(line) + "\n")
+	var failedSteps []string
+	junitSuites := &reporters.JUnitTestSuites{}
+	for _, suite := range report.Suites.TestSuites {
+		if suite.Name != openshiftCITestSuiteName {
+			junitSuites.TestSuites = append(junitSuites.TestSuites, suite)
+			continue
+		}
+		for _, tc := range suite.TestCases {
+			if tc.Failure != nil {
+				failedSteps = append(failedSteps, tc.Name)
+			}
 		}
 	}
-
-	if formattedFailures.String() == "Failures:\n" {
-		return "No specific failures captured in the report.\n"
+	sort.Strings(failedSteps)
+
+	var summary *jobsummary.Summary
+	if len(junitSuites.TestSuites) > 0 {
+		summary = jobsummary.FromJUnit(junitSuites)
+	} else {
+		buildLog, err := fetchBuildLog(cfg, jobURL+"/build-log.txt")
+		if err != nil {
+			return nil, err
+		}
+		summary = jobsummary.FromBuildLog(buildLog)
 	}
-
-	return formattedFailures.String()
+	summary.SetFailedSteps(failedSteps)
+	return summary, nil
 }
 
 func run(cmd *cobra.Command, args []string) error {
-	// Required GCS build.log PATH for latest build
-	prowURL := os.Getenv("PROW_URL") + "/build-log.txt"
-	bodyString, err := fetchTextContent(prowURL)
+	instance, err := prowconfig.Instance()
+	if err != nil {
+		return err
+	}
+	source, err := prowconfig.NewSource(instance)
 	if err != nil {
 		return err
 	}
+	// the same source and timeout are used for all requests of the report
+	cfg, err := newScannerConfig(instance, source, []string{reportStepName})
+	if err != nil {
+		return err
+	}
+	cfg.Timeout = prow.DefaultTimeout
 
-	message, _ := constructMessage(bodyString)
-	fmt.Println(message)
+	jobURL := strings.TrimSuffix(os.Getenv("PROW_URL"), "/")
+	jobRun, err := getPeriodicReportJobRun(cfg, jobURL, periodicReportJobName)
+	if err != nil {
+		if jobURL == "" {
+			return err
+		}
+		klog.Warningf("failed to get details of job run %s: %+v", jobURL, err)
+	} else {
+		jobURL = jobRun.URL
+	}
+
+	summary, err := createJobSummary(cfg, jobURL)
+	if err != nil {
+		return err
+	}
+	if jobRun != nil {
+		summary.JobName = jobRun.Job
+		if jobRun.Finished != nil {
+			summary.Duration = jobRun.Duration().Round(time.Second)
+		}
+	}
+	summary.JobURL = jobURL
+
+	switch periodicReportOutput {
+	case outputFormatMarkdown:
+		fmt.Println(summary.Markdown())
+	case outputFormatSlack:
+		o, err := summary.SlackJSON()
+		if err != nil {
+			return fmt.Errorf("failed to marshal job summary: %+v", err)
+		}
+		fmt.Println(string(o))
+	case outputFormatJSON:
+		o, err := summary.JSON()
+		if err != nil {
+			return fmt.Errorf("failed to marshal job summary: %+v", err)
+		}
+		fmt.Println(string(o))
+	default:
+		fmt.Println(summary.Text())
+	}
 	return nil
 }
+
+func init() {
+	periodicReportCmd.Flags().StringVar(&periodicReportJobName, jobNameParamName, "", "Name of the Prow job whose latest run should be analyzed (alternative to PROW_URL env var)")
+	periodicReportCmd.Flags().StringVar(&periodicReportOutput, outputParamName, outputFormatText, "Output format (text, markdown, slack, json)")
+}
@@ -1,6 +1,7 @@
 package prowjob
 
 import (
+	"github.com/konflux-ci/qe-tools/cmd/prowconfig"
 	"github.com/konflux-ci/qe-tools/pkg/types"
 	"github.com/spf13/cobra"
 )
@@ -14,19 +15,25 @@ var (
 	artifactDir     string
 	failIfUnhealthy bool
 	notifyOnPR      bool
-	prowJobID       string
 )
 
 // ProwjobCmd represents the prowjob command
 var ProwjobCmd = &cobra.Command{
 	Use:   "prowjob",
 	Short: "Commands for processing Prow jobs",
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		return prowconfig.BindFlags(cmd.Flags())
+	},
 }
 
 func init() {
 	ProwjobCmd.AddCommand(periodicReportCmd)
 	ProwjobCmd.AddCommand(createReportCmd)
 	ProwjobCmd.AddCommand(healthCheckCmd)
+	ProwjobCmd.AddCommand(listJobRunsCmd)
+	ProwjobCmd.AddCommand(flakesCmd)
+
+	prowconfig.AddFlags(ProwjobCmd.PersistentFlags())
 
 	createReportCmd.Flags().StringVar(&artifactDir, types.ArtifactDirParamName, "", "Path to the folder where to store produced files")
 	healthCheckCmd.Flags().StringVar(&artifactDir, types.ArtifactDirParamName, "", "Path to the folder where to store produced files")
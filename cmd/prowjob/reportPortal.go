@@ -0,0 +1,93 @@
+package prowjob
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+	"github.com/konflux-ci/qe-tools/pkg/reportportal"
+	"github.com/konflux-ci/qe-tools/pkg/types"
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+const (
+	reportPortalUploadParamName = "report-portal-upload"
+	reportPortalLaunchParamName = "report-portal-launch"
+)
+
+var (
+	reportPortalUpload  bool
+	reportPortalURL     string
+	reportPortalProject string
+	reportPortalLaunch  string
+)
+
+// checkReportPortalParams validates the parameters required for uploading the report to Report Portal
+func checkReportPortalParams() error {
+	if viper.GetString(types.ReportPortalURLParamName) == "" {
+		return fmt.Errorf("%q flag provided, but parameter %q not provided, neither %s env var was set", reportPortalUploadParamName, types.ReportPortalURLParamName, types.ReportPortalURLEnv)
+	}
+	if viper.GetString(types.ReportPortalProjectParamName) == "" {
+		return fmt.Errorf("%q flag provided, but parameter %q not provided, neither %s env var was set", reportPortalUploadParamName, types.ReportPortalProjectParamName, types.ReportPortalProjectEnv)
+	}
+	if viper.GetString(types.ReportPortalTokenEnv) == "" {
+		return fmt.Errorf("%q flag provided, but %q env var not set", reportPortalUploadParamName, types.ReportPortalTokenEnv)
+	}
+	return nil
+}
+
+// uploadToReportPortal reports the JUnit suites as a Report Portal launch, with the HTML report
+// attached and the attributes describing the openshift-ci job create-report is executed in
+func uploadToReportPortal(suites *reporters.JUnitTestSuites, artifactDir string, jobReports []*jobReport) error {
+	launchName := reportPortalLaunch
+	if launchName == "" {
+		launchName = jobReports[0].Name
+		if len(jobReports) > 1 {
+			launchName = viper.GetString(jobNameParamName)
+		}
+		if launchName == "" {
+			launchName = types.AggregateTestSuiteName
+		}
+	}
+
+	opts := reportportal.UploadOptions{LaunchName: launchName}
+	if jobSpec := viper.GetString(jobSpecParamName); jobSpec != "" {
+		spec, err := prow.ParseJobSpec(jobSpec)
+		if err != nil {
+			klog.Warningf("failed to parse job spec - the launch attributes won't be set: %+v", err)
+		}
+		opts.Attributes = reportportal.AttributesFromJobSpec(spec)
+	}
+	if len(jobReports) == 1 && jobReports[0].URL != "" {
+		opts.Description = fmt.Sprintf("[Prow job %s](%s)", jobReports[0].ID, jobReports[0].URL)
+	}
+	htmlReport, err := os.ReadFile(filepath.Clean(artifactDir + "/junit-summary.html"))
+	if err != nil {
+		return fmt.Errorf("failed to read HTML file with test summary: %+v", err)
+	}
+	opts.Attachments = []reportportal.Attachment{{Name: "junit-summary.html", ContentType: "text/html", Content: htmlReport}}
+
+	client := reportportal.NewClient(viper.GetString(types.ReportPortalURLParamName), viper.GetString(types.ReportPortalProjectParamName), viper.GetString(types.ReportPortalTokenEnv))
+	finished, err := reportportal.Upload(context.Background(), client, suites, opts)
+	if err != nil {
+		return fmt.Errorf("failed to upload the report to Report Portal: %+v", err)
+	}
+	klog.Infof("report uploaded to Report Portal as launch #%d: %s", finished.Number, finished.Link)
+	return nil
+}
+
+func init() {
+	createReportCmd.Flags().BoolVar(&reportPortalUpload, reportPortalUploadParamName, false, "Upload the report to Report Portal as a launch (requires --"+types.ReportPortalURLParamName+", --"+types.ReportPortalProjectParamName+" and "+types.ReportPortalTokenEnv+" env var with an API key; failures of the upload are only logged)")
+	createReportCmd.Flags().StringVar(&reportPortalURL, types.ReportPortalURLParamName, "", "URL of the Report Portal instance the report should be uploaded to (or "+types.ReportPortalURLEnv+" env var)")
+	createReportCmd.Flags().StringVar(&reportPortalProject, types.ReportPortalProjectParamName, "", "Report Portal project the launch should be reported into (or "+types.ReportPortalProjectEnv+" env var)")
+	createReportCmd.Flags().StringVar(&reportPortalLaunch, reportPortalLaunchParamName, "", "Name of the Report Portal launch (defaults to the job name)")
+
+	_ = viper.BindPFlag(types.ReportPortalURLParamName, createReportCmd.Flags().Lookup(types.ReportPortalURLParamName))
+	_ = viper.BindPFlag(types.ReportPortalProjectParamName, createReportCmd.Flags().Lookup(types.ReportPortalProjectParamName))
+	_ = viper.BindEnv(types.ReportPortalURLParamName, types.ReportPortalURLEnv)
+	_ = viper.BindEnv(types.ReportPortalProjectParamName, types.ReportPortalProjectEnv)
+}
@@ -0,0 +1,15 @@
+package reportportal
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ReportPortalCmd represents the report-portal command
+var ReportPortalCmd = &cobra.Command{
+	Use:   "report-portal",
+	Short: "Commands for reporting test results to Report Portal",
+}
+
+func init() {
+	ReportPortalCmd.AddCommand(uploadCmd)
+}
@@ -0,0 +1,145 @@
+package reportportal
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/konflux-ci/qe-tools/pkg/junitnormalize"
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+	"github.com/konflux-ci/qe-tools/pkg/reportportal"
+	"github.com/konflux-ci/qe-tools/pkg/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+const (
+	junitFileParamName   = "junit-file"
+	launchNameParamName  = "launch-name"
+	descriptionParamName = "description"
+	attributeParamName   = "attribute"
+	attachmentParamName  = "attachment"
+	maxLogSizeParamName  = "max-log-size-kb"
+)
+
+var (
+	rpURL        string
+	project      string
+	junitFile    string
+	launchName   string
+	description  string
+	attributes   []string
+	attachments  []string
+	maxLogSizeKB int
+)
+
+// uploadCmd represents the upload command
+var uploadCmd = &cobra.Command{
+	Use:   "upload",
+	Short: "Upload a JUnit report to Report Portal as a launch",
+	Long: `Upload a JUnit report to Report Portal as a launch - every JUnit suite is reported as a suite
+with a step for each of its test cases, including failure messages and the output of the test cases.
+
+Besides Ginkgo, JUnit reports of go test (gotestsum), pytest, Jest, Maven Surefire and Tekton are supported.
+
+If the ` + types.JobSpecEnv + ` env var is set (e.g. in openshift-ci), the launch attributes describe the job
+(job name and type, repository, PR number, author and commit). The API key is read from the
+` + types.ReportPortalTokenEnv + ` env var.
+
+Examples:
+  - Upload the report created by "prowjob create-report":
+      qe-tools report-portal upload --report-portal-url https://reportportal.example.com --report-portal-project konflux --junit-file ./tmp/1234/junit.xml --launch-name e2e-tests
+  - Upload the report with additional attributes and the HTML report attached:
+      qe-tools report-portal upload --junit-file junit.xml --launch-name e2e-tests --attribute cluster:ocp-4.15 --attachment junit-summary.html
+`,
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		if rpURL == "" {
+			rpURL = viper.GetString(types.ReportPortalURLEnv)
+		}
+		if project == "" {
+			project = viper.GetString(types.ReportPortalProjectEnv)
+		}
+		params := []struct{ name, value, env string }{
+			{types.ReportPortalURLParamName, rpURL, types.ReportPortalURLEnv},
+			{types.ReportPortalProjectParamName, project, types.ReportPortalProjectEnv},
+		}
+		for _, p := range params {
+			if p.value == "" {
+				_ = cmd.Usage()
+				return fmt.Errorf("parameter %q not provided, neither %s env var was set", p.name, p.env)
+			}
+		}
+		for _, param := range []string{junitFileParamName, launchNameParamName} {
+			if cmd.Flag(param).Value.String() == "" {
+				_ = cmd.Usage()
+				return fmt.Errorf("parameter %q not provided", param)
+			}
+		}
+		if viper.GetString(types.ReportPortalTokenEnv) == "" {
+			return fmt.Errorf("%q env var not set", types.ReportPortalTokenEnv)
+		}
+		return nil
+	},
+	SilenceUsage: true,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		report, err := junitnormalize.DecodeFile(junitFile)
+		if err != nil {
+			return err
+		}
+		klog.Infof("detected producer of the JUnit report: %s", report.Producer)
+
+		opts := reportportal.UploadOptions{
+			LaunchName:  launchName,
+			Description: description,
+			MaxLogSize:  maxLogSizeKB * 1024,
+		}
+		if jobSpec := viper.GetString(types.JobSpecEnv); jobSpec != "" {
+			spec, err := prow.ParseJobSpec(jobSpec)
+			if err != nil {
+				klog.Warningf("failed to parse job spec - the job attributes won't be set: %+v", err)
+			}
+			opts.Attributes = reportportal.AttributesFromJobSpec(spec)
+		}
+		for _, attribute := range attributes {
+			key, value, found := strings.Cut(attribute, ":")
+			if !found {
+				key, value = "", attribute
+			}
+			opts.Attributes = append(opts.Attributes, reportportal.Attribute{Key: key, Value: value})
+		}
+		for _, path := range attachments {
+			content, err := os.ReadFile(filepath.Clean(path))
+			if err != nil {
+				return fmt.Errorf("failed to read attachment: %+v", err)
+			}
+			opts.Attachments = append(opts.Attachments, reportportal.Attachment{
+				Name:        filepath.Base(path),
+				ContentType: mime.TypeByExtension(filepath.Ext(path)),
+				Content:     content,
+			})
+		}
+
+		client := reportportal.NewClient(rpURL, project, viper.GetString(types.ReportPortalTokenEnv))
+		finished, err := reportportal.Upload(context.Background(), client, report.Ginkgo(), opts)
+		if err != nil {
+			return err
+		}
+		klog.Infof("report uploaded to Report Portal as launch #%d: %s", finished.Number, finished.Link)
+		return nil
+	},
+}
+
+func init() {
+	uploadCmd.Flags().StringVar(&rpURL, types.ReportPortalURLParamName, "", "URL of the Report Portal instance (or "+types.ReportPortalURLEnv+" env var)")
+	uploadCmd.Flags().StringVar(&project, types.ReportPortalProjectParamName, "", "Report Portal project the launch should be reported into (or "+types.ReportPortalProjectEnv+" env var)")
+	uploadCmd.Flags().StringVar(&junitFile, junitFileParamName, "", "Path to the JUnit file to upload")
+	uploadCmd.Flags().StringVar(&launchName, launchNameParamName, "", "Name of the launch")
+	uploadCmd.Flags().StringVar(&description, descriptionParamName, "", "Description of the launch")
+	uploadCmd.Flags().StringArrayVar(&attributes, attributeParamName, nil, "Attribute of the launch in the key:value format (can be repeated)")
+	uploadCmd.Flags().StringArrayVar(&attachments, attachmentParamName, nil, "Path to a file attached to the launch, e.g. the HTML report (can be repeated)")
+	uploadCmd.Flags().IntVar(&maxLogSizeKB, maxLogSizeParamName, reportportal.DefaultMaxLogSize/1024, "Maximum size (in KB) of the test case's output uploaded as a log message - larger output is uploaded as an attachment")
+}
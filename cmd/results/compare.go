@@ -0,0 +1,124 @@
+package results
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/konflux-ci/qe-tools/cmd/prowconfig"
+	"github.com/konflux-ci/qe-tools/pkg/history"
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+	"github.com/konflux-ci/qe-tools/pkg/testresults"
+	"github.com/konflux-ci/qe-tools/pkg/types"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+const (
+	baselineParamName          = "baseline"
+	candidateParamName         = "candidate"
+	durationThresholdParamName = "duration-threshold"
+	minDurationDeltaParamName  = "min-duration-delta"
+)
+
+var (
+	baselineRef       string
+	candidateRef      string
+	durationThreshold float64
+	minDurationDelta  time.Duration
+	jUnitPattern      string
+)
+
+// compareCmd represents the compare command
+var compareCmd = &cobra.Command{
+	Use:   "compare",
+	Short: "Compare test results of a candidate run with a baseline run",
+	Long: `Compare test results of a candidate run with a baseline run and report test cases whose duration grew
+beyond the threshold, test cases whose status changed, and test cases that were added or removed.
+
+The baseline and the candidate can be a path to a JUnit file (e.g. produced by "prowjob create-report"),
+a Prow job ID, or an OCI artifact reference. All JUnit files matching --` + types.JUnitFilenameParamName + ` within
+the Prow job's or the OCI artifact's files are merged.
+
+Examples:
+  - Compare JUnit files of two runs:
+      qe-tools results compare --baseline ./baseline/junit.xml --candidate ./candidate/junit.xml
+  - Compare a PR job with the last periodic job, reporting test cases at least 50% (and 30s) slower:
+      qe-tools results compare --baseline 1234 --candidate 5678 --duration-threshold 50 --min-duration-delta 30s
+  - Compare test results stored in OCI artifacts, as JSON:
+      qe-tools results compare --baseline quay.io/org/repo:tag-1 --candidate quay.io/org/repo:tag-2 --output json
+`,
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		if err := prowconfig.BindFlags(cmd.Flags()); err != nil {
+			return err
+		}
+		for _, param := range []string{baselineParamName, candidateParamName} {
+			if cmd.Flag(param).Value.String() == "" {
+				_ = cmd.Usage()
+				return fmt.Errorf("parameter %q not provided", param)
+			}
+		}
+		if durationThreshold < 0 {
+			return fmt.Errorf("parameter %q cannot be negative", durationThresholdParamName)
+		}
+		return checkOutputFormat()
+	},
+	SilenceUsage: true,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		pattern, err := testresults.NewFilePattern(jUnitPattern)
+		if err != nil {
+			return err
+		}
+		prowConfig, err := prowconfig.ScannerConfig()
+		if err != nil {
+			return err
+		}
+		baseline, err := loadRun(baselineRef, pattern, prowConfig)
+		if err != nil {
+			return err
+		}
+		candidate, err := loadRun(candidateRef, pattern, prowConfig)
+		if err != nil {
+			return err
+		}
+
+		comparison := history.Compare(baseline, candidate, history.CompareOptions{
+			DurationThreshold: durationThreshold / 100,
+			MinDurationDelta:  minDurationDelta,
+		})
+		klog.Infof("found %d duration regression(s), %d status change(s), %d new and %d removed test(s)",
+			len(comparison.DurationRegressions), len(comparison.StatusChanges), len(comparison.NewTests), len(comparison.RemovedTests))
+
+		var result []byte
+		if output == outputFormatJSON {
+			if result, err = history.FormatComparisonJSON(comparison); err != nil {
+				return err
+			}
+		} else {
+			result = []byte(history.FormatComparisonMarkdown(comparison))
+		}
+		return writeResult(result, "comparison")
+	},
+}
+
+// loadRun loads test cases from the JUnit file, the Prow job or the OCI artifact the reference points to
+func loadRun(ref string, pattern *testresults.FilePattern, prowConfig prow.ScannerConfig) (history.Run, error) {
+	suites, err := testresults.LoadJUnit(ref, pattern, prowConfig)
+	if err != nil {
+		return history.Run{}, err
+	}
+	return history.Run{
+		ID:        ref,
+		TestCases: history.TestCasesFromJUnit(suites),
+	}, nil
+}
+
+func init() {
+	compareCmd.Flags().StringVar(&baselineRef, baselineParamName, "", "The baseline test results - a path to a JUnit file, a Prow job ID or an OCI artifact reference")
+	compareCmd.Flags().StringVar(&candidateRef, candidateParamName, "", "The candidate test results - a path to a JUnit file, a Prow job ID or an OCI artifact reference")
+	compareCmd.Flags().Float64Var(&durationThreshold, durationThresholdParamName, history.DefaultDurationThreshold*100, "Minimum growth (in percent) of a test case's duration reported as a regression")
+	compareCmd.Flags().DurationVar(&minDurationDelta, minDurationDeltaParamName, history.DefaultMinDurationDelta, "Minimum absolute growth of a test case's duration reported as a regression")
+	compareCmd.Flags().StringVar(&jUnitPattern, types.JUnitFilenameParamName, testresults.DefaultJUnitPattern, "A name or glob pattern (or regular expression prefixed with \"re:\") of the JUnit file(s) within the Prow job's or the OCI artifact's files")
+	compareCmd.Flags().StringVar(&output, outputParamName, outputFormatMarkdown, "Output format (markdown, json)")
+	compareCmd.Flags().StringVar(&outputFile, types.OutputFilenameParamName, "", "A name of the file to store the comparison in (printed to stdout if empty)")
+	prowconfig.AddFlags(compareCmd.Flags())
+}
@@ -0,0 +1,87 @@
+package results
+
+import (
+	"fmt"
+
+	"github.com/konflux-ci/qe-tools/cmd/prowconfig"
+	"github.com/konflux-ci/qe-tools/pkg/junitdiff"
+	"github.com/konflux-ci/qe-tools/pkg/testresults"
+	"github.com/konflux-ci/qe-tools/pkg/types"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "List specs newly failing, newly passing, still failing, added and removed compared with a baseline run",
+	Long: `List specs newly failing, newly passing, still failing, added and removed in a candidate run (e.g. a PR job)
+compared with a baseline run (e.g. the last green periodic job).
+
+The baseline and the candidate can be a path to a JUnit file (e.g. produced by "prowjob create-report"),
+a Prow job ID, or an OCI artifact reference. All JUnit files matching --` + types.JUnitFilenameParamName + ` within
+the Prow job's or the OCI artifact's files are merged.
+
+The same diff can be included in the output of "analyze-test-results" via its --` + baselineParamName + ` parameter.
+
+Examples:
+  - Diff of a PR job and the last green periodic job:
+      qe-tools results diff --baseline 1234 --candidate 5678
+  - Diff of two JUnit files, as JSON:
+      qe-tools results diff --baseline ./periodic/junit.xml --candidate ./pr/junit.xml --output json
+`,
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		if err := prowconfig.BindFlags(cmd.Flags()); err != nil {
+			return err
+		}
+		for _, param := range []string{baselineParamName, candidateParamName} {
+			if cmd.Flag(param).Value.String() == "" {
+				_ = cmd.Usage()
+				return fmt.Errorf("parameter %q not provided", param)
+			}
+		}
+		return checkOutputFormat()
+	},
+	SilenceUsage: true,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		pattern, err := testresults.NewFilePattern(jUnitPattern)
+		if err != nil {
+			return err
+		}
+		prowConfig, err := prowconfig.ScannerConfig()
+		if err != nil {
+			return err
+		}
+		baseline, err := testresults.LoadJUnit(baselineRef, pattern, prowConfig)
+		if err != nil {
+			return err
+		}
+		candidate, err := testresults.LoadJUnit(candidateRef, pattern, prowConfig)
+		if err != nil {
+			return err
+		}
+
+		diff := junitdiff.Compare(baseline, candidate)
+		diff.Baseline = baselineRef
+		klog.Infof("%s: %s", diff.Title(), diff.Summary())
+
+		var result []byte
+		if output == outputFormatJSON {
+			if result, err = diff.JSON(); err != nil {
+				return err
+			}
+		} else {
+			result = []byte(diff.Markdown())
+		}
+		return writeResult(result, "diff")
+	},
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&baselineRef, baselineParamName, "", "The baseline test results (e.g. of the last green periodic job) - a path to a JUnit file, a Prow job ID or an OCI artifact reference")
+	diffCmd.Flags().StringVar(&candidateRef, candidateParamName, "", "The candidate test results (e.g. of a PR job) - a path to a JUnit file, a Prow job ID or an OCI artifact reference")
+	diffCmd.Flags().StringVar(&jUnitPattern, types.JUnitFilenameParamName, testresults.DefaultJUnitPattern, "A name or glob pattern (or regular expression prefixed with \"re:\") of the JUnit file(s) within the Prow job's or the OCI artifact's files")
+	diffCmd.Flags().StringVar(&output, outputParamName, outputFormatMarkdown, "Output format (markdown, json)")
+	diffCmd.Flags().StringVar(&outputFile, types.OutputFilenameParamName, "", "A name of the file to store the diff in (printed to stdout if empty)")
+	prowconfig.AddFlags(diffCmd.Flags())
+}
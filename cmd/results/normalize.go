@@ -0,0 +1,80 @@
+package results
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/konflux-ci/qe-tools/pkg/junitnormalize"
+	"github.com/konflux-ci/qe-tools/pkg/types"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+const (
+	junitFileParamName = "junit-file"
+	flavourParamName   = "flavour"
+
+	flavourGinkgo       = "ginkgo"
+	flavourReportPortal = "report-portal"
+)
+
+var (
+	junitFile string
+	flavour   string
+)
+
+// normalizeCmd represents the normalize command
+var normalizeCmd = &cobra.Command{
+	Use:   "normalize",
+	Short: "Normalize a JUnit report of any supported test framework into the Ginkgo or the Report Portal flavour",
+	Long: `Normalize a JUnit report of any supported test framework into the Ginkgo or the Report Portal flavour.
+
+Test frameworks follow different JUnit conventions, e.g. for reporting skipped and disabled tests or errors.
+Reports of Ginkgo, go test (gotestsum, go-junit-report), pytest, Jest, Maven Surefire and Tekton are detected
+and normalized, and written as either:
+  - ` + flavourGinkgo + `: every test case has the "status" attribute as written by Ginkgo (disabled tests are pending,
+    tests with errors are panicked) - as expected by "prowjob create-report" and the other commands
+  - ` + flavourReportPortal + `: without the "status" attribute, disabled tests are reported as skipped
+
+Examples:
+  - Normalize a pytest report for "prowjob create-report":
+      qe-tools results normalize --junit-file ./pytest/junit.xml --output-file ./artifacts/junit-pytest.xml
+  - Convert a Maven Surefire report for the Report Portal import:
+      qe-tools results normalize --junit-file ./target/surefire-reports/TEST-AppTest.xml --flavour report-portal
+`,
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		if junitFile == "" {
+			_ = cmd.Usage()
+			return fmt.Errorf("parameter %q not provided", junitFileParamName)
+		}
+		switch flavour {
+		case flavourGinkgo, flavourReportPortal:
+			return nil
+		}
+		return fmt.Errorf("unsupported flavour %q (supported: %s, %s)", flavour, flavourGinkgo, flavourReportPortal)
+	},
+	SilenceUsage: true,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		report, err := junitnormalize.DecodeFile(junitFile)
+		if err != nil {
+			return err
+		}
+		klog.Infof("detected producer of the JUnit report: %s", report.Producer)
+
+		var v any = report.Ginkgo()
+		if flavour == flavourReportPortal {
+			v = report.ReportPortal()
+		}
+		var buf bytes.Buffer
+		if err := junitnormalize.EncodeXML(&buf, v); err != nil {
+			return err
+		}
+		return writeResult(buf.Bytes(), "normalized JUnit report")
+	},
+}
+
+func init() {
+	normalizeCmd.Flags().StringVar(&junitFile, junitFileParamName, "", "Path to the JUnit file to normalize")
+	normalizeCmd.Flags().StringVar(&flavour, flavourParamName, flavourGinkgo, "Flavour of the written JUnit report (ginkgo, report-portal)")
+	normalizeCmd.Flags().StringVar(&outputFile, types.OutputFilenameParamName, "", "A name of the file to store the normalized JUnit report in (printed to stdout if empty)")
+}
@@ -0,0 +1,187 @@
+package results
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/konflux-ci/qe-tools/pkg/history"
+	"github.com/konflux-ci/qe-tools/pkg/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+const (
+	sinceParamName    = "since"
+	jobNameParamName  = "job-name"
+	repoParamName     = "repo"
+	sourceParamName   = "source"
+	limitParamName    = "limit"
+	intervalParamName = "interval"
+	outputParamName   = "output"
+
+	outputFormatMarkdown = "markdown"
+	outputFormatJSON     = "json"
+)
+
+var (
+	resultsDB    string
+	since        time.Duration
+	jobName      string
+	repo         string
+	source       string
+	limit        int
+	interval     time.Duration
+	output       string
+	outputFile   string
+	queryFilters history.Filter
+)
+
+// queryCmd represents the query command
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Query trends in the history of test results",
+	Long: `Query trends in the history of test results ingested by "prowjob create-report" and "analyze-test-results"
+(when their --` + types.ResultsDBParamName + ` parameter or ` + types.ResultsDBEnv + ` env var is set).
+
+Examples:
+  - Daily pass-rate of an e2e job within the last 2 weeks:
+      qe-tools results query pass-rate --results-db results.db --job-name periodic-ci-org-repo-main-e2e --since 336h
+  - The 10 most failing tests of a repository, as JSON:
+      qe-tools results query failing-tests --results-db results.db --repo org/repo --limit 10 --output json
+`,
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		if resultsDB == "" {
+			resultsDB = viper.GetString(types.ResultsDBEnv)
+		}
+		if resultsDB == "" {
+			_ = cmd.Usage()
+			return fmt.Errorf("parameter %q not provided, neither %s env var was set", types.ResultsDBParamName, types.ResultsDBEnv)
+		}
+		if err := checkOutputFormat(); err != nil {
+			return err
+		}
+		queryFilters = history.Filter{JobName: jobName, Repo: repo, Source: source}
+		if since > 0 {
+			queryFilters.Since = time.Now().Add(-since)
+		}
+		return nil
+	},
+	SilenceUsage: true,
+}
+
+var passRateCmd = &cobra.Command{
+	Use:   "pass-rate",
+	Short: "Pass-rate of the test cases over time",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return query(func(runs []history.Run) ([]byte, error) {
+			passRates := history.PassRateOverTime(runs, interval)
+			if output == outputFormatJSON {
+				return history.FormatJSON(passRates)
+			}
+			return []byte(history.FormatPassRateMarkdown(passRates, interval)), nil
+		})
+	},
+}
+
+var slowestTestsCmd = &cobra.Command{
+	Use:   "slowest-tests",
+	Short: "Test cases with the longest average duration",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return query(func(runs []history.Run) ([]byte, error) {
+			stats := history.SlowestTests(runs, limit)
+			if output == outputFormatJSON {
+				return history.FormatJSON(stats)
+			}
+			return []byte(history.FormatTestStatsMarkdown("Slowest tests", stats)), nil
+		})
+	},
+}
+
+var failingTestsCmd = &cobra.Command{
+	Use:   "failing-tests",
+	Short: "Test cases with the most failures",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return query(func(runs []history.Run) ([]byte, error) {
+			stats := history.MostFailingTests(runs, limit)
+			if output == outputFormatJSON {
+				return history.FormatJSON(stats)
+			}
+			return []byte(history.FormatTestStatsMarkdown("Most failing tests", stats)), nil
+		})
+	},
+}
+
+var reposCmd = &cobra.Command{
+	Use:   "repos",
+	Short: "Results of the runs broken down per tested repository",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return query(func(runs []history.Run) ([]byte, error) {
+			stats := history.RepoBreakdown(runs)
+			if output == outputFormatJSON {
+				return history.FormatJSON(stats)
+			}
+			return []byte(history.FormatRepoStatsMarkdown(stats)), nil
+		})
+	},
+}
+
+// query reads the runs matching the filters from the results database and prints (or saves) the formatted result
+func query(format func(runs []history.Run) ([]byte, error)) error {
+	store, err := history.Open(resultsDB)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	runs, err := store.Runs(queryFilters)
+	if err != nil {
+		return fmt.Errorf("failed to read runs from %s: %+v", resultsDB, err)
+	}
+	klog.Infof("found %d run(s) matching the filters", len(runs))
+
+	result, err := format(runs)
+	if err != nil {
+		return err
+	}
+	return writeResult(result, "query result")
+}
+
+// checkOutputFormat checks the value of the --output parameter
+func checkOutputFormat() error {
+	switch output {
+	case outputFormatMarkdown, outputFormatJSON:
+		return nil
+	}
+	return fmt.Errorf("unsupported output format %q (supported: %s, %s)", output, outputFormatMarkdown, outputFormatJSON)
+}
+
+// writeResult prints the result, or saves it into the file specified via --output-file
+func writeResult(result []byte, description string) error {
+	if outputFile == "" {
+		fmt.Println(string(result))
+		return nil
+	}
+	if err := os.WriteFile(outputFile, result, 0o600); err != nil {
+		return fmt.Errorf("failed to create a file with the %s: %+v", description, err)
+	}
+	klog.Infof("%s saved to %s", description, outputFile)
+	return nil
+}
+
+func init() {
+	queryCmd.PersistentFlags().StringVar(&resultsDB, types.ResultsDBParamName, "", "Path to the results database file (or "+types.ResultsDBEnv+" env var)")
+	queryCmd.PersistentFlags().DurationVar(&since, sinceParamName, 30*24*time.Hour, "Time window for selecting the runs (0 means no limit)")
+	queryCmd.PersistentFlags().StringVar(&jobName, jobNameParamName, "", "Select only runs of the job with the given name")
+	queryCmd.PersistentFlags().StringVar(&repo, repoParamName, "", "Select only runs testing the given repository (\"org/repo\" or \"repo\")")
+	queryCmd.PersistentFlags().StringVar(&source, sourceParamName, "", fmt.Sprintf("Select only runs ingested by the given command (%s, %s)", history.CreateReportSource, history.AnalyzeTestResultsSource))
+	queryCmd.PersistentFlags().StringVar(&output, outputParamName, outputFormatMarkdown, "Output format (markdown, json)")
+	queryCmd.PersistentFlags().StringVar(&outputFile, types.OutputFilenameParamName, "", "A name of the file to store the query result in (printed to stdout if empty)")
+
+	passRateCmd.Flags().DurationVar(&interval, intervalParamName, 24*time.Hour, "Length of the intervals the runs are grouped into")
+	slowestTestsCmd.Flags().IntVar(&limit, limitParamName, 20, "Maximum number of listed test cases (0 means no limit)")
+	failingTestsCmd.Flags().IntVar(&limit, limitParamName, 20, "Maximum number of listed test cases (0 means no limit)")
+
+	queryCmd.AddCommand(passRateCmd, slowestTestsCmd, failingTestsCmd, reposCmd)
+}
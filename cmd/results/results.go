@@ -0,0 +1,15 @@
+package results
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ResultsCmd represents the results command
+var ResultsCmd = &cobra.Command{
+	Use:   "results",
+	Short: "Commands for comparing test results and working with their history",
+}
+
+func init() {
+	ResultsCmd.AddCommand(queryCmd, compareCmd, diffCmd, normalizeCmd)
+}
@@ -6,7 +6,10 @@ import (
 
 	"github.com/konflux-ci/qe-tools/cmd/analyzetestresults"
 	"github.com/konflux-ci/qe-tools/cmd/estimate"
+	"github.com/konflux-ci/qe-tools/cmd/issues"
 	download "github.com/konflux-ci/qe-tools/cmd/oci"
+	"github.com/konflux-ci/qe-tools/cmd/reportportal"
+	"github.com/konflux-ci/qe-tools/cmd/results"
 	"github.com/konflux-ci/qe-tools/cmd/webhook"
 
 	"github.com/spf13/cobra"
@@ -54,6 +57,9 @@ func init() {
 	rootCmd.AddCommand(estimate.EstimateTimeToReviewCmd)
 	rootCmd.AddCommand(download.Init())
 	rootCmd.AddCommand(analyzetestresults.AnalyzeTestResultsCmd)
+	rootCmd.AddCommand(issues.IssuesCmd)
+	rootCmd.AddCommand(results.ResultsCmd)
+	rootCmd.AddCommand(reportportal.ReportPortalCmd)
 }
 
 // initConfig reads in config file and ENV variables if set.
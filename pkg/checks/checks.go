@@ -0,0 +1,197 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+	"github.com/konflux-ci/qe-tools/pkg/types"
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+// Conclusion is the conclusion of a completed check run
+type Conclusion string
+
+const (
+	// SuccessConclusion is set when all the test cases passed
+	SuccessConclusion Conclusion = "success"
+	// FailureConclusion is set when any test case failed
+	FailureConclusion Conclusion = "failure"
+
+	// maxAnnotationsPerRequest is the maximum number of annotations accepted by the GitHub API in a single request
+	maxAnnotationsPerRequest = 50
+	maxTitleLength           = 255
+	maxTextLength            = 65535
+	maxAnnotationTextLength  = 64000
+
+	// defaultAnnotationPath is used for failures without a known location within the repository
+	defaultAnnotationPath = "."
+)
+
+// locationRegexp matches the location of the failure reported by Ginkgo, e.g. "In [It] at: /go/src/github.com/org/repo/tests/build.go:123"
+var locationRegexp = regexp.MustCompile(`at: (\S+\.go):(\d+)`)
+
+// Annotation points to a failure within the check run
+type Annotation struct {
+	Path    string
+	Line    int
+	Title   string
+	Message string
+}
+
+// CheckRun represents a completed GitHub check run with the results of the tests
+type CheckRun struct {
+	Name       string
+	HeadSHA    string
+	DetailsURL string
+	Conclusion Conclusion
+	Title      string
+	Summary    string
+	// Annotations of failures - the GitHub API accepts only 50 annotations per request, so they're sent in batches
+	Annotations []Annotation
+}
+
+// FromJUnit creates a check run from the JUnit suites. Failures are annotated with their location
+// if it's within the given repository (e.g. "e2e-tests"), otherwise with the root of the repository
+func FromJUnit(name, headSHA, detailsURL, repo string, suites *reporters.JUnitTestSuites) CheckRun {
+	run := CheckRun{Name: name, HeadSHA: headSHA, DetailsURL: detailsURL, Conclusion: SuccessConclusion}
+
+	var failed []string
+	var tests, skipped int
+	for _, suite := range suites.TestSuites {
+		// test cases of the aggregate suite only summarize results of the jobs reported by the other suites
+		if suite.Name == types.AggregateTestSuiteName {
+			continue
+		}
+		for _, tc := range suite.TestCases {
+			tests++
+			var message string
+			switch {
+			case tc.Failure != nil:
+				message = strings.TrimSpace(tc.Failure.Message + "\n" + tc.Failure.Description)
+			case tc.Error != nil:
+				message = strings.TrimSpace(tc.Error.Message + "\n" + tc.Error.Description)
+			default:
+				if tc.Skipped != nil {
+					skipped++
+				}
+				continue
+			}
+			failed = append(failed, fmt.Sprintf("%s: %s", suite.Name, tc.Name))
+			path, line := failureLocation(message, repo)
+			run.Annotations = append(run.Annotations, Annotation{
+				Path:    path,
+				Line:    line,
+				Title:   truncate(fmt.Sprintf("[%s] %s", suite.Name, tc.Name), maxTitleLength),
+				Message: truncate(message, maxAnnotationTextLength),
+			})
+		}
+	}
+
+	if len(failed) > 0 {
+		run.Conclusion = FailureConclusion
+		run.Title = fmt.Sprintf("%d of %d test case(s) failed", len(failed), tests)
+	} else {
+		run.Title = fmt.Sprintf("All %d test case(s) passed", tests-skipped)
+	}
+
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "**%s** (%d skipped)\n", run.Title, skipped)
+	if detailsURL != "" {
+		fmt.Fprintf(&summary, "\nSee the [HTML report](%s) for more details.\n", detailsURL)
+	}
+	if len(failed) > 0 {
+		summary.WriteString("\n### Failed test cases\n")
+		for _, f := range failed {
+			fmt.Fprintf(&summary, "- %s\n", f)
+		}
+	}
+	run.Summary = truncate(summary.String(), maxTextLength)
+	return run
+}
+
+// failureLocation returns the path (relative to the given repository) and the line of the failure
+func failureLocation(message, repo string) (string, int) {
+	m := locationRegexp.FindStringSubmatch(message)
+	if m == nil || repo == "" {
+		return defaultAnnotationPath, 1
+	}
+	_, path, found := strings.Cut(m[1], "/"+repo+"/")
+	if !found {
+		return defaultAnnotationPath, 1
+	}
+	line, _ := strconv.Atoi(m[2])
+	return path, line
+}
+
+func truncate(s string, maxLength int) string {
+	if len(s) <= maxLength {
+		return s
+	}
+	return strings.ToValidUTF8(s[:maxLength-3], "") + "..."
+}
+
+// Publish creates the completed check run in the given repository
+func Publish(ctx context.Context, client *github.Client, owner, repo string, run CheckRun) (*github.CheckRun, error) {
+	annotations := toGitHubAnnotations(run.Annotations)
+	first := annotations
+	if len(first) > maxAnnotationsPerRequest {
+		first = first[:maxAnnotationsPerRequest]
+	}
+
+	opts := github.CreateCheckRunOptions{
+		Name:        run.Name,
+		HeadSHA:     run.HeadSHA,
+		Status:      github.String("completed"),
+		Conclusion:  github.String(string(run.Conclusion)),
+		CompletedAt: &github.Timestamp{Time: time.Now()},
+		Output: &github.CheckRunOutput{
+			Title:       github.String(run.Title),
+			Summary:     github.String(run.Summary),
+			Annotations: first,
+		},
+	}
+	if run.DetailsURL != "" {
+		opts.DetailsURL = github.String(run.DetailsURL)
+	}
+	checkRun, _, err := client.Checks.CreateCheckRun(ctx, owner, repo, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create check run %q for %s/%s@%s: %+v", run.Name, owner, repo, run.HeadSHA, err)
+	}
+
+	// Additional annotations are appended by updating the check run
+	for i := maxAnnotationsPerRequest; i < len(annotations); i += maxAnnotationsPerRequest {
+		batch := annotations[i:min(i+maxAnnotationsPerRequest, len(annotations))]
+		_, _, err := client.Checks.UpdateCheckRun(ctx, owner, repo, checkRun.GetID(), github.UpdateCheckRunOptions{
+			Name: run.Name,
+			Output: &github.CheckRunOutput{
+				Title:       github.String(run.Title),
+				Summary:     github.String(run.Summary),
+				Annotations: batch,
+			},
+		})
+		if err != nil {
+			return checkRun, fmt.Errorf("failed to add annotations to check run %d: %+v", checkRun.GetID(), err)
+		}
+	}
+	return checkRun, nil
+}
+
+func toGitHubAnnotations(annotations []Annotation) []*github.CheckRunAnnotation {
+	var result []*github.CheckRunAnnotation
+	for _, a := range annotations {
+		result = append(result, &github.CheckRunAnnotation{
+			Path:            github.String(a.Path),
+			StartLine:       github.Int(a.Line),
+			EndLine:         github.Int(a.Line),
+			AnnotationLevel: github.String("failure"),
+			Title:           github.String(a.Title),
+			Message:         github.String(a.Message),
+		})
+	}
+	return result
+}
@@ -0,0 +1,108 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v56/github"
+	"github.com/konflux-ci/qe-tools/pkg/types"
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+// TestFromJUnit tests creating the check run with annotations of the failures
+func TestFromJUnit(t *testing.T) {
+	suites := &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{
+		{
+			Name: "build-service",
+			TestCases: []reporters.JUnitTestCase{
+				{Name: "creates a build", Failure: &reporters.JUnitFailure{Message: "Expected success", Description: "[FAILED] Expected success\nIn [It] at: /go/src/github.com/org/e2e-tests/tests/build/build.go:123 @ 01/01/24"}},
+				{Name: "skipped", Skipped: &reporters.JUnitSkipped{Message: "skipped"}},
+				{Name: "passed"},
+			},
+		},
+		{
+			Name:      "openshift-ci job",
+			TestCases: []reporters.JUnitTestCase{{Name: "ipi-install-install", Failure: &reporters.JUnitFailure{Message: "ipi-install-install has failed"}}},
+		},
+		{
+			// summarizes the results of the other suites, so it's not counted
+			Name:      types.AggregateTestSuiteName,
+			TestCases: []reporters.JUnitTestCase{{Name: "e2e", Failure: &reporters.JUnitFailure{Message: "2 of 4 test case(s) didn't pass"}}},
+		},
+	}}
+
+	run := FromJUnit("e2e", "abc123", "https://example.com/report.html", "e2e-tests", suites)
+
+	if run.Conclusion != FailureConclusion || run.Title != "2 of 4 test case(s) failed" {
+		t.Errorf("unexpected conclusion %q and title %q", run.Conclusion, run.Title)
+	}
+	if !strings.Contains(run.Summary, "[HTML report](https://example.com/report.html)") || !strings.Contains(run.Summary, "- openshift-ci job: ipi-install-install") {
+		t.Errorf("unexpected summary:\n%s", run.Summary)
+	}
+	expected := []Annotation{
+		{Path: "tests/build/build.go", Line: 123, Title: "[build-service] creates a build"},
+		{Path: ".", Line: 1, Title: "[openshift-ci job] ipi-install-install"},
+	}
+	if len(run.Annotations) != len(expected) {
+		t.Fatalf("expected %d annotations, got %+v", len(expected), run.Annotations)
+	}
+	for i, e := range expected {
+		a := run.Annotations[i]
+		if a.Path != e.Path || a.Line != e.Line || a.Title != e.Title || a.Message == "" {
+			t.Errorf("expected annotation %+v, got %+v", e, a)
+		}
+	}
+
+	run = FromJUnit("e2e", "abc123", "", "e2e-tests", &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{{TestCases: []reporters.JUnitTestCase{{Name: "passed"}}}}})
+	if run.Conclusion != SuccessConclusion || len(run.Annotations) != 0 {
+		t.Errorf("expected successful check run without annotations, got %+v", run)
+	}
+}
+
+// TestPublish tests sending the annotations in batches
+func TestPublish(t *testing.T) {
+	var created github.CreateCheckRunOptions
+	var updates []github.UpdateCheckRunOptions
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/org/e2e-tests/check-runs":
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			_ = json.NewEncoder(w).Encode(github.CheckRun{ID: github.Int64(1), HTMLURL: github.String("https://github.com/org/e2e-tests/runs/1")})
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/org/e2e-tests/check-runs/1":
+			update := github.UpdateCheckRunOptions{}
+			_ = json.NewDecoder(r.Body).Decode(&update)
+			updates = append(updates, update)
+			_ = json.NewEncoder(w).Encode(github.CheckRun{ID: github.Int64(1)})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	run := CheckRun{Name: "e2e", HeadSHA: "abc123", Conclusion: FailureConclusion, Title: "failed", Summary: "summary"}
+	for i := 0; i < 120; i++ {
+		run.Annotations = append(run.Annotations, Annotation{Path: ".", Line: 1, Title: fmt.Sprintf("test %d", i), Message: "failed"})
+	}
+
+	checkRun, err := Publish(context.Background(), client, "org", "e2e-tests", run)
+	if err != nil {
+		t.Fatalf("failed to publish check run: %v", err)
+	}
+	if checkRun.GetID() != 1 || created.HeadSHA != "abc123" || created.GetConclusion() != "failure" || len(created.Output.Annotations) != 50 {
+		t.Errorf("unexpected check run created: %+v", created)
+	}
+	if len(updates) != 2 || len(updates[0].Output.Annotations) != 50 || len(updates[1].Output.Annotations) != 20 {
+		t.Errorf("expected the remaining annotations to be sent in 2 batches, got %d update(s)", len(updates))
+	}
+	if updates[1].Output.Annotations[19].GetTitle() != "test 119" {
+		t.Errorf("expected the last annotation to be sent last, got %q", updates[1].Output.Annotations[19].GetTitle())
+	}
+}
@@ -0,0 +1,215 @@
+package classifier
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Category represents the kind of the failure cause
+type Category string
+
+const (
+	// InfraCategory represents failures caused by the infrastructure (e.g. exceeded quota, cloud provider issues)
+	InfraCategory Category = "infra"
+	// ImagePullCategory represents failures caused by pulling container images
+	ImagePullCategory Category = "image-pull"
+	// TimeoutCategory represents failures caused by an operation not finishing in time
+	TimeoutCategory Category = "timeout"
+	// ProductBugCategory represents failures caused by a bug in the tested product
+	ProductBugCategory Category = "product-bug"
+	// TestBugCategory represents failures caused by a bug in the test code
+	TestBugCategory Category = "test-bug"
+	// UnknownCategory is assigned if no rule matches the failure
+	UnknownCategory Category = "unknown"
+)
+
+// Target represents the part of the failure evidence a rule is matched against
+type Target string
+
+const (
+	// BuildLogTarget is the build log of the failed step (or the log of the test run)
+	BuildLogTarget Target = "build-log"
+	// FailureMessageTarget is the failure message of the failed JUnit test case
+	FailureMessageTarget Target = "failure-message"
+	// StepNameTarget is the name of the failed openshift-ci step
+	StepNameTarget Target = "step-name"
+)
+
+// ConfigKey is the key of the rules within the config file, e.g.:
+//
+//	failureClassificationRules:
+//	  - name: quota
+//	    category: infra
+//	    patterns: ["(?i)quota exceeded"]
+//	    targets: ["build-log", "failure-message"]
+//	    weight: 0.9
+const ConfigKey = "failureClassificationRules"
+
+// defaultWeight is the confidence of a rule that doesn't define its weight
+const defaultWeight = 0.5
+
+// Rule assigns the Category to failures whose evidence matches any of its patterns or substrings
+type Rule struct {
+	Name     string   `json:"name"`
+	Category Category `json:"category"`
+	// Patterns are regular expressions and Substrings are plain strings matched against the Targets
+	Patterns   []string `json:"patterns"`
+	Substrings []string `json:"substrings"`
+	// Targets the rule is matched against - all targets if empty
+	Targets []Target `json:"targets"`
+	// Weight (0..1] is the confidence of the classification if the rule matches
+	Weight float64 `json:"weight"`
+}
+
+// DefaultRules are used when no rules are provided
+var DefaultRules = []Rule{
+	{Name: "quota", Category: InfraCategory, Patterns: []string{`(?i)quota(s)? (exceeded|limit)`, `(?i)exceeded quota`, `(?i)insufficient (cpu|memory|resources)`}, Weight: 0.9},
+	{Name: "cloud-provider", Category: InfraCategory, Patterns: []string{`(?i)(RequestLimitExceeded|ServiceUnavailable|InternalError).*(aws|gcp|azure)`, `(?i)failed to (create|provision|destroy) (the )?cluster`}, Weight: 0.7},
+	{Name: "cluster-provisioning-step", Category: InfraCategory, Patterns: []string{`ipi-install|provision|cluster-install|create-cluster`}, Targets: []Target{StepNameTarget}, Weight: 0.6},
+	{Name: "network", Category: InfraCategory, Patterns: []string{`(?i)(connection refused|connection reset by peer|no route to host|i/o timeout|TLS handshake timeout)`}, Weight: 0.5},
+	{Name: "image-pull", Category: ImagePullCategory, Patterns: []string{`ImagePullBackOff`, `ErrImagePull`, `(?i)(failed|unable) to pull image`, `(?i)manifest unknown`, `(?i)toomanyrequests`}, Weight: 0.9},
+	{Name: "timeout", Category: TimeoutCategory, Patterns: []string{`(?i)timed out`, `(?i)context deadline exceeded`, `(?i)timeout (expired|exceeded)`, `\[TIMEDOUT\]`}, Weight: 0.6},
+	{Name: "test-code-panic", Category: TestBugCategory, Patterns: []string{`(?i)\[PANICKED\]`, `(?i)nil pointer dereference.*_test\.go`, `(?i)index out of range.*_test\.go`}, Weight: 0.7},
+	{Name: "test-setup", Category: TestBugCategory, Patterns: []string{`(?i)(BeforeAll|BeforeEach|BeforeSuite|AfterAll|AfterEach|AfterSuite).*fail`}, Targets: []Target{FailureMessageTarget}, Weight: 0.4},
+	{Name: "assertion", Category: ProductBugCategory, Patterns: []string{`(?i)expected\s.*\s(to equal|to be|to contain|to have|not to)`, `(?i)unexpected (status|response|result)`}, Targets: []Target{FailureMessageTarget}, Weight: 0.5},
+	{Name: "controller-error", Category: ProductBugCategory, Patterns: []string{`(?i)reconcil(e|er|iation).*(error|failed)`, `(?i)webhook.*denied the request`}, Weight: 0.5},
+}
+
+// Evidence contains the data about the failure used for its classification
+type Evidence struct {
+	StepName       string
+	BuildLog       string
+	FailureMessage string
+}
+
+func (e Evidence) get(t Target) string {
+	switch t {
+	case BuildLogTarget:
+		return e.BuildLog
+	case FailureMessageTarget:
+		return e.FailureMessage
+	case StepNameTarget:
+		return e.StepName
+	}
+	return ""
+}
+
+// Classification is the result of classifying a failure
+type Classification struct {
+	Category Category `json:"category"`
+	// Confidence (0..1) of the classification - combined weights of all matched rules of the Category
+	Confidence float64 `json:"confidence"`
+	// Rules contains names of the matched rules of the Category
+	Rules []string `json:"rules,omitempty"`
+}
+
+// String returns the classification in a human-readable form, e.g. "infra (90%, rules: quota)"
+func (c Classification) String() string {
+	if c.Category == UnknownCategory {
+		return string(UnknownCategory)
+	}
+	return fmt.Sprintf("%s (%.0f%%, rules: %s)", c.Category, c.Confidence*100, strings.Join(c.Rules, ", "))
+}
+
+type compiledRule struct {
+	Rule
+	regexps []*regexp.Regexp
+}
+
+// Classifier assigns categories to failures based on its rules
+type Classifier struct {
+	rules []compiledRule
+}
+
+// FromConfig creates a Classifier with the rules defined under ConfigKey in the config file loaded
+// by viper - DefaultRules are used if no rules are defined
+func FromConfig() (*Classifier, error) {
+	var rules []Rule
+	if err := viper.UnmarshalKey(ConfigKey, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse %q from config: %+v", ConfigKey, err)
+	}
+	return New(rules)
+}
+
+// New creates a Classifier with the given rules - DefaultRules are used if no rules are provided
+func New(rules []Rule) (*Classifier, error) {
+	if len(rules) == 0 {
+		rules = DefaultRules
+	}
+	c := &Classifier{}
+	for _, rule := range rules {
+		if rule.Category == "" {
+			return nil, fmt.Errorf("category of the failure classification rule %q is not defined", rule.Name)
+		}
+		if rule.Weight <= 0 || rule.Weight > 1 {
+			rule.Weight = defaultWeight
+		}
+		cr := compiledRule{Rule: rule}
+		for _, p := range rule.Patterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q of the failure classification rule %q: %+v", p, rule.Name, err)
+			}
+			cr.regexps = append(cr.regexps, re)
+		}
+		c.rules = append(c.rules, cr)
+	}
+	return c, nil
+}
+
+// Classify returns the most probable category of the failure with the given evidence
+func (c *Classifier) Classify(e Evidence) Classification {
+	// Confidence of a category is the probability that at least one of its matched rules is right
+	missProbability := map[Category]float64{}
+	matched := map[Category][]string{}
+	var categories []Category
+	for _, rule := range c.rules {
+		if !rule.matches(e) {
+			continue
+		}
+		if _, ok := missProbability[rule.Category]; !ok {
+			missProbability[rule.Category] = 1
+			categories = append(categories, rule.Category)
+		}
+		missProbability[rule.Category] *= 1 - rule.Weight
+		matched[rule.Category] = append(matched[rule.Category], rule.Name)
+	}
+	if len(categories) == 0 {
+		return Classification{Category: UnknownCategory}
+	}
+
+	// Categories of the rules defined earlier win ties
+	sort.SliceStable(categories, func(i, j int) bool {
+		return missProbability[categories[i]] < missProbability[categories[j]]
+	})
+	best := categories[0]
+	return Classification{Category: best, Confidence: 1 - missProbability[best], Rules: matched[best]}
+}
+
+func (r compiledRule) matches(e Evidence) bool {
+	targets := r.Targets
+	if len(targets) == 0 {
+		targets = []Target{BuildLogTarget, FailureMessageTarget, StepNameTarget}
+	}
+	for _, t := range targets {
+		value := e.get(t)
+		if value == "" {
+			continue
+		}
+		for _, s := range r.Substrings {
+			if strings.Contains(value, s) {
+				return true
+			}
+		}
+		for _, re := range r.regexps {
+			if re.MatchString(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
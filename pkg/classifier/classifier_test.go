@@ -0,0 +1,86 @@
+package classifier
+
+import (
+	"testing"
+)
+
+// TestClassifyWithDefaultRules tests classifying failures with the default rules
+func TestClassifyWithDefaultRules(t *testing.T) {
+	c, err := New(nil)
+	if err != nil {
+		t.Fatalf("failed to create classifier: %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		evidence         Evidence
+		expectedCategory Category
+	}{
+		{
+			name:             "Exceeded quota in the provisioning step",
+			evidence:         Evidence{StepName: "ipi-install-install", BuildLog: "level=error msg=Error: googleapi: Quota exceeded for quota metric 'CPUS'"},
+			expectedCategory: InfraCategory,
+		},
+		{
+			name:             "Image pull failure",
+			evidence:         Evidence{FailureMessage: "pod my-pod is in ImagePullBackOff state"},
+			expectedCategory: ImagePullCategory,
+		},
+		{
+			name:             "Timeout",
+			evidence:         Evidence{FailureMessage: "Timed out after 300.000s.\nExpected PipelineRun to succeed"},
+			expectedCategory: TimeoutCategory,
+		},
+		{
+			name:             "Failed assertion",
+			evidence:         Evidence{FailureMessage: "Expected\n    <string>: Failed\nto equal\n    <string>: Succeeded"},
+			expectedCategory: ProductBugCategory,
+		},
+		{
+			name:             "No rule matches",
+			evidence:         Evidence{StepName: "e2e", FailureMessage: "something went wrong"},
+			expectedCategory: UnknownCategory,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.Classify(tt.evidence)
+			if got.Category != tt.expectedCategory {
+				t.Errorf("expected category %q, got %+v", tt.expectedCategory, got)
+			}
+			if tt.expectedCategory != UnknownCategory && (got.Confidence <= 0 || got.Confidence > 1 || len(got.Rules) == 0) {
+				t.Errorf("unexpected confidence or rules of the classification: %+v", got)
+			}
+		})
+	}
+}
+
+// TestClassifyWithCustomRules tests targets of rules and combining confidence of multiple matched rules
+func TestClassifyWithCustomRules(t *testing.T) {
+	c, err := New([]Rule{
+		{Name: "flaky-step", Category: TestBugCategory, Substrings: []string{"flaky"}, Targets: []Target{StepNameTarget}, Weight: 0.6},
+		{Name: "oom", Category: InfraCategory, Patterns: []string{"OOMKilled"}, Weight: 0.5},
+		{Name: "exit-code", Category: InfraCategory, Substrings: []string{"exit code 137"}, Weight: 0.5},
+	})
+	if err != nil {
+		t.Fatalf("failed to create classifier: %v", err)
+	}
+
+	got := c.Classify(Evidence{StepName: "flaky-e2e", BuildLog: "container OOMKilled with exit code 137"})
+	if got.Category != InfraCategory || got.Confidence != 0.75 || len(got.Rules) != 2 {
+		t.Errorf("expected infra category with combined confidence 0.75, got %+v", got)
+	}
+
+	got = c.Classify(Evidence{BuildLog: "the flaky test has failed"})
+	if got.Category != UnknownCategory {
+		t.Errorf("expected the step name rule not to match the build log, got %+v", got)
+	}
+
+	if _, err := New([]Rule{{Name: "invalid", Category: InfraCategory, Patterns: []string{"("}}}); err == nil {
+		t.Errorf("expected an error for invalid pattern")
+	}
+	if _, err := New([]Rule{{Name: "no-category", Patterns: []string{"x"}}}); err == nil {
+		t.Errorf("expected an error for rule without category")
+	}
+}
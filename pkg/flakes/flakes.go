@@ -0,0 +1,177 @@
+package flakes
+
+import (
+	"sort"
+	"time"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+	ginkgoTypes "github.com/onsi/ginkgo/v2/types"
+)
+
+// Outcome represents the result of a test case within a single run
+type Outcome string
+
+const (
+	// OutcomePassed represents a test case that passed
+	OutcomePassed Outcome = "passed"
+	// OutcomeFailed represents a test case that failed (or errored, panicked, timed out, ...)
+	OutcomeFailed Outcome = "failed"
+	// OutcomeFlaky represents a test case that both failed and passed within a single run (e.g. it was retried)
+	OutcomeFlaky Outcome = "flaky"
+)
+
+// Run holds outcomes of all test cases executed within a single job run
+type Run struct {
+	ID       string
+	URL      string
+	Started  time.Time
+	Revision string
+	// Outcomes maps test IDs (see TestID) to their outcome - skipped test cases are omitted
+	Outcomes map[string]Outcome
+}
+
+// RunResult is the outcome of a test case within a single run
+type RunResult struct {
+	RunID   string  `json:"runID"`
+	URL     string  `json:"url"`
+	Outcome Outcome `json:"outcome"`
+}
+
+// FlakyTest represents a test case whose outcome flipped across the analyzed runs
+type FlakyTest struct {
+	Name string `json:"name"`
+	// Runs is the number of runs the test case was executed in
+	Runs     int `json:"runs"`
+	Failures int `json:"failures"`
+	// Flips is the number of outcome changes between consecutive runs (plus runs the test case was flaky within)
+	Flips int `json:"flips"`
+	// SameRevisionFlips is the number of flips between runs testing the same revision
+	SameRevisionFlips int `json:"sameRevisionFlips"`
+	// Score is the flakiness score (0..1) used for ranking - the ratio of flips to possible flips
+	Score float64 `json:"score"`
+	// Sequence contains outcomes of the test case from the oldest run to the newest one
+	Sequence []RunResult `json:"sequence"`
+}
+
+// Options configure the flaky test detection
+type Options struct {
+	// Window is the maximum time between the starts of two consecutive runs for their outcome flip
+	// to be considered a flake - flips between runs testing the same revision are always considered
+	Window time.Duration
+	// MinRuns is the minimum number of runs a test case has to be executed in to be analyzed
+	MinRuns int
+}
+
+// TestID returns the identifier of the test case used for matching it across runs
+func TestID(suiteName, testCaseName string) string {
+	return suiteName + " / " + testCaseName
+}
+
+// NewRun creates a Run with outcomes of test cases from the given JUnit report
+func NewRun(id, url, revision string, started time.Time, suites *reporters.JUnitTestSuites) Run {
+	run := Run{ID: id, URL: url, Started: started, Revision: revision, Outcomes: map[string]Outcome{}}
+	for _, suite := range suites.TestSuites {
+		for _, tc := range suite.TestCases {
+			outcome, ok := testCaseOutcome(tc)
+			if !ok {
+				continue
+			}
+			testID := TestID(suite.Name, tc.Name)
+			if previous, exists := run.Outcomes[testID]; exists && previous != outcome {
+				outcome = OutcomeFlaky
+			}
+			run.Outcomes[testID] = outcome
+		}
+	}
+	return run
+}
+
+// testCaseOutcome returns the outcome of the test case - false is returned for test cases that weren't executed
+func testCaseOutcome(tc reporters.JUnitTestCase) (Outcome, bool) {
+	switch {
+	case tc.Failure != nil || tc.Error != nil:
+		return OutcomeFailed, true
+	case tc.Skipped != nil:
+		return "", false
+	}
+	switch tc.Status {
+	case ginkgoTypes.SpecStateSkipped.String(), ginkgoTypes.SpecStatePending.String(), "disabled":
+		return "", false
+	case ginkgoTypes.SpecStateFailed.String(), ginkgoTypes.SpecStatePanicked.String(), ginkgoTypes.SpecStateTimedout.String(),
+		ginkgoTypes.SpecStateInterrupted.String(), ginkgoTypes.SpecStateAborted.String():
+		return OutcomeFailed, true
+	}
+	return OutcomePassed, true
+}
+
+// Detect analyzes outcomes of test cases across the given runs and returns
+// flaky test cases ranked by their flakiness score (the flakiest first)
+func Detect(runs []Run, opts Options) []FlakyTest {
+	sorted := make([]Run, len(runs))
+	copy(sorted, runs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Started.Before(sorted[j].Started)
+	})
+
+	var testIDs []string
+	seen := map[string]bool{}
+	for _, run := range sorted {
+		for testID := range run.Outcomes {
+			if !seen[testID] {
+				seen[testID] = true
+				testIDs = append(testIDs, testID)
+			}
+		}
+	}
+
+	var flaky []FlakyTest
+	for _, testID := range testIDs {
+		ft := FlakyTest{Name: testID}
+		var previous *Run
+		for i := range sorted {
+			run := &sorted[i]
+			outcome, ok := run.Outcomes[testID]
+			if !ok {
+				continue
+			}
+			ft.Runs++
+			ft.Sequence = append(ft.Sequence, RunResult{RunID: run.ID, URL: run.URL, Outcome: outcome})
+			if outcome != OutcomePassed {
+				ft.Failures++
+			}
+			if outcome == OutcomeFlaky {
+				ft.Flips++
+				ft.SameRevisionFlips++
+			}
+			if previous != nil && previous.Outcomes[testID] != outcome {
+				sameRevision := run.Revision != "" && run.Revision == previous.Revision
+				if sameRevision {
+					ft.Flips++
+					ft.SameRevisionFlips++
+				} else if opts.Window <= 0 || run.Started.Sub(previous.Started) <= opts.Window {
+					ft.Flips++
+				}
+			}
+			previous = run
+		}
+
+		if ft.Flips == 0 || ft.Runs < opts.MinRuns {
+			continue
+		}
+		// Every run can be flaky on its own and there are (runs - 1) transitions between the runs
+		ft.Score = float64(ft.Flips) / float64(2*ft.Runs-1)
+		flaky = append(flaky, ft)
+	}
+
+	sort.SliceStable(flaky, func(i, j int) bool {
+		if flaky[i].Score != flaky[j].Score {
+			return flaky[i].Score > flaky[j].Score
+		}
+		if flaky[i].SameRevisionFlips != flaky[j].SameRevisionFlips {
+			return flaky[i].SameRevisionFlips > flaky[j].SameRevisionFlips
+		}
+		return flaky[i].Name < flaky[j].Name
+	})
+
+	return flaky
+}
@@ -0,0 +1,89 @@
+package flakes
+
+import (
+	"testing"
+	"time"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+func newTestRun(id, revision string, started time.Time, outcomes map[string]Outcome) Run {
+	return Run{ID: id, URL: "https://prow/" + id, Revision: revision, Started: started, Outcomes: outcomes}
+}
+
+// TestDetect tests detecting and ranking flaky tests across runs
+func TestDetect(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	runs := []Run{
+		// Intentionally out of order - runs are sorted by their start time
+		newTestRun("3", "b", start.Add(2*time.Hour), map[string]Outcome{"stable": OutcomePassed, "same-revision": OutcomeFailed, "window": OutcomePassed, "broken": OutcomeFailed}),
+		newTestRun("1", "a", start, map[string]Outcome{"stable": OutcomePassed, "same-revision": OutcomePassed, "window": OutcomePassed, "retried": OutcomeFlaky}),
+		newTestRun("2", "b", start.Add(time.Hour), map[string]Outcome{"stable": OutcomePassed, "same-revision": OutcomePassed, "window": OutcomeFailed, "broken": OutcomeFailed}),
+		newTestRun("4", "c", start.Add(72*time.Hour), map[string]Outcome{"stable": OutcomePassed, "same-revision": OutcomePassed, "window": OutcomeFailed, "broken": OutcomeFailed}),
+	}
+
+	flaky := Detect(runs, Options{Window: 24 * time.Hour})
+
+	var names []string
+	for _, ft := range flaky {
+		names = append(names, ft.Name)
+	}
+	// "retried": flaky within its only run, "window": flips within the window and on the same revision,
+	// "same-revision": flip on the same revision only (the later flip is out of the window)
+	expected := []string{"retried", "window", "same-revision"}
+	if len(names) != len(expected) {
+		t.Fatalf("expected flaky tests %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("expected flaky tests %v, got %v", expected, names)
+		}
+	}
+
+	if window := flaky[1]; window.Flips != 2 || window.SameRevisionFlips != 1 || window.Failures != 2 {
+		t.Errorf("unexpected stats of the %q test: %+v", window.Name, window)
+	}
+	sameRevision := flaky[2]
+	if sameRevision.Flips != 1 || sameRevision.SameRevisionFlips != 1 || sameRevision.Failures != 1 || sameRevision.Runs != 4 {
+		t.Errorf("unexpected stats of the %q test: %+v", sameRevision.Name, sameRevision)
+	}
+	if len(sameRevision.Sequence) != 4 || sameRevision.Sequence[0].RunID != "1" || sameRevision.Sequence[3].RunID != "4" {
+		t.Errorf("expected the sequence to be sorted from the oldest run, got %+v", sameRevision.Sequence)
+	}
+
+	if flaky := Detect(runs, Options{Window: 24 * time.Hour, MinRuns: 2}); len(flaky) != 2 {
+		t.Errorf("expected tests executed in a single run to be ignored, got %+v", flaky)
+	}
+}
+
+// TestNewRun tests collecting outcomes of test cases from a JUnit report
+func TestNewRun(t *testing.T) {
+	suites := &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{
+		{Name: "suite", TestCases: []reporters.JUnitTestCase{
+			{Name: "passed", Status: "passed"},
+			{Name: "failed", Status: "failed", Failure: &reporters.JUnitFailure{Message: "boom"}},
+			{Name: "timedout", Status: "timedout"},
+			{Name: "skipped", Status: "skipped", Skipped: &reporters.JUnitSkipped{}},
+			{Name: "pending", Status: "pending"},
+			{Name: "retried", Status: "failed", Failure: &reporters.JUnitFailure{Message: "boom"}},
+			{Name: "retried", Status: "passed"},
+		}},
+	}}
+
+	run := NewRun("1", "url", "rev", time.Now(), suites)
+
+	expected := map[string]Outcome{
+		TestID("suite", "passed"):   OutcomePassed,
+		TestID("suite", "failed"):   OutcomeFailed,
+		TestID("suite", "timedout"): OutcomeFailed,
+		TestID("suite", "retried"):  OutcomeFlaky,
+	}
+	if len(run.Outcomes) != len(expected) {
+		t.Fatalf("expected outcomes %v, got %v", expected, run.Outcomes)
+	}
+	for testID, outcome := range expected {
+		if run.Outcomes[testID] != outcome {
+			t.Errorf("expected outcome %q of %q, got %q", outcome, testID, run.Outcomes[testID])
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package flakes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+// JUnitTestSuiteName is the name of the test suite holding the flaky tests as its properties
+const JUnitTestSuiteName = "flaky tests"
+
+var outcomeSymbols = map[Outcome]string{
+	OutcomePassed: ":white_check_mark:",
+	OutcomeFailed: ":x:",
+	OutcomeFlaky:  ":warning:",
+}
+
+// FormatJSON returns the flaky tests as an indented JSON
+func FormatJSON(flaky []FlakyTest) ([]byte, error) {
+	if flaky == nil {
+		flaky = []FlakyTest{}
+	}
+	return json.MarshalIndent(flaky, "", "    ")
+}
+
+// FormatMarkdown returns the flaky tests as a Markdown table, the flakiest first
+func FormatMarkdown(job string, analyzedRuns int, flaky []FlakyTest) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## Flaky tests of %s\n\n", job)
+	if len(flaky) == 0 {
+		fmt.Fprintf(&sb, "No flaky tests found within %d analyzed run(s).\n", analyzedRuns)
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "Found %d flaky test(s) within %d analyzed run(s) (outcomes from the oldest run to the newest one):\n\n", len(flaky), analyzedRuns)
+	sb.WriteString("| # | Test | Score | Flips (same revision) | Failures / Runs | Outcomes |\n")
+	sb.WriteString("|---|------|-------|-----------------------|-----------------|----------|\n")
+	for i, ft := range flaky {
+		var outcomes []string
+		for _, r := range ft.Sequence {
+			outcomes = append(outcomes, fmt.Sprintf("[%s](%s)", outcomeSymbols[r.Outcome], r.URL))
+		}
+		fmt.Fprintf(&sb, "| %d | %s | %.2f | %d (%d) | %d / %d | %s |\n", i+1, strings.ReplaceAll(ft.Name, "|", `\|`), ft.Score, ft.Flips, ft.SameRevisionFlips, ft.Failures, ft.Runs, strings.Join(outcomes, " "))
+	}
+	return sb.String()
+}
+
+// JUnitTestSuite returns a test suite with a property for every flaky test (the flakiest first),
+// which can be appended to an existing JUnit report
+func JUnitTestSuite(flaky []FlakyTest) reporters.JUnitTestSuite {
+	suite := reporters.JUnitTestSuite{Name: JUnitTestSuiteName, Properties: reporters.JUnitProperties{Properties: []reporters.JUnitProperty{}}}
+	for _, ft := range flaky {
+		suite.Properties.Properties = append(suite.Properties.Properties, reporters.JUnitProperty{
+			Name:  ft.Name,
+			Value: fmt.Sprintf("score=%.2f flips=%d same-revision-flips=%d failures=%d runs=%d", ft.Score, ft.Flips, ft.SameRevisionFlips, ft.Failures, ft.Runs),
+		})
+	}
+	return suite
+}
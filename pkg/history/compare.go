@@ -0,0 +1,125 @@
+package history
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultDurationThreshold is the default relative growth of a test case's duration reported as a regression
+	DefaultDurationThreshold = 0.2
+	// DefaultMinDurationDelta is the default minimum absolute growth of a test case's duration reported as a regression
+	DefaultMinDurationDelta = time.Second
+)
+
+// CompareOptions configure the comparison of two runs
+type CompareOptions struct {
+	// DurationThreshold is the minimum relative growth of the duration (e.g. 0.2 for 20%) reported as a regression
+	DurationThreshold float64
+	// MinDurationDelta is the minimum absolute growth of the duration reported as a regression,
+	// so that small fluctuations of short test cases aren't reported
+	MinDurationDelta time.Duration
+}
+
+// Comparison holds differences between the baseline and the candidate run
+type Comparison struct {
+	Baseline  string `json:"baseline"`
+	Candidate string `json:"candidate"`
+	// DurationThreshold and MinDurationDelta (in seconds) are the options the duration regressions were detected with
+	DurationThreshold float64 `json:"durationThreshold"`
+	MinDurationDelta  float64 `json:"minDurationDelta"`
+
+	DurationRegressions []DurationChange `json:"durationRegressions"`
+	StatusChanges       []StatusChange   `json:"statusChanges"`
+	NewTests            []TestCase       `json:"newTests"`
+	RemovedTests        []TestCase       `json:"removedTests"`
+}
+
+// DurationChange represents a test case whose duration grew between the runs
+type DurationChange struct {
+	Suite string `json:"suite"`
+	Name  string `json:"name"`
+	// Baseline and Candidate are the durations in seconds
+	Baseline  float64 `json:"baseline"`
+	Candidate float64 `json:"candidate"`
+	// Growth is the relative growth of the duration, e.g. 0.5 for 50%
+	Growth float64 `json:"growth"`
+}
+
+// StatusChange represents a test case whose status differs between the runs
+type StatusChange struct {
+	Suite     string `json:"suite"`
+	Name      string `json:"name"`
+	Baseline  Status `json:"baseline"`
+	Candidate Status `json:"candidate"`
+}
+
+// Compare compares test cases of the candidate run with the baseline run. Test cases are matched by their suite
+// and name - if a test case is reported multiple times within a run (e.g. it was retried), its last occurrence is used.
+// Durations are compared only for test cases executed in both runs
+func Compare(baseline, candidate Run, opts CompareOptions) Comparison {
+	c := Comparison{
+		Baseline:          baseline.ID,
+		Candidate:         candidate.ID,
+		DurationThreshold: opts.DurationThreshold,
+		MinDurationDelta:  opts.MinDurationDelta.Seconds(),
+	}
+
+	baselineTests, baselineOrder := IndexTestCases(baseline.TestCases)
+	candidateTests, candidateOrder := IndexTestCases(candidate.TestCases)
+
+	for _, k := range candidateOrder {
+		tc := candidateTests[k]
+		base, ok := baselineTests[k]
+		if !ok {
+			c.NewTests = append(c.NewTests, tc)
+			continue
+		}
+		if base.Status != tc.Status {
+			c.StatusChanges = append(c.StatusChanges, StatusChange{Suite: tc.Suite, Name: tc.Name, Baseline: base.Status, Candidate: tc.Status})
+		}
+		if base.Status == SkippedStatus || tc.Status == SkippedStatus {
+			continue
+		}
+		delta := tc.Duration - base.Duration
+		if delta <= 0 || delta < c.MinDurationDelta {
+			continue
+		}
+		// test cases without the baseline duration are always considered regressed (if MinDurationDelta is exceeded)
+		var growth float64
+		if base.Duration > 0 {
+			if growth = delta / base.Duration; growth < opts.DurationThreshold {
+				continue
+			}
+		}
+		c.DurationRegressions = append(c.DurationRegressions, DurationChange{Suite: tc.Suite, Name: tc.Name, Baseline: base.Duration, Candidate: tc.Duration, Growth: growth})
+	}
+	for _, k := range baselineOrder {
+		if _, ok := candidateTests[k]; !ok {
+			c.RemovedTests = append(c.RemovedTests, baselineTests[k])
+		}
+	}
+
+	sort.SliceStable(c.DurationRegressions, func(i, j int) bool {
+		return c.DurationRegressions[i].Candidate-c.DurationRegressions[i].Baseline > c.DurationRegressions[j].Candidate-c.DurationRegressions[j].Baseline
+	})
+	return c
+}
+
+// TestCaseKey identifies a test case within a run by its suite and name
+type TestCaseKey struct{ Suite, Name string }
+
+// IndexTestCases maps the test cases by their suite and name, keeping the order of their first occurrence.
+// If a test case is reported multiple times (e.g. it was retried), its last occurrence is kept
+func IndexTestCases(testCases []TestCase) (map[TestCaseKey]TestCase, []TestCaseKey) {
+	index := map[TestCaseKey]TestCase{}
+	var order []TestCaseKey
+	for _, tc := range testCases {
+		k := TestCaseKey{tc.Suite, tc.Name}
+		if _, ok := index[k]; !ok {
+			order = append(order, k)
+		}
+		index[k] = tc
+	}
+	return index, order
+}
@@ -0,0 +1,82 @@
+package history
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCompare tests detecting duration regressions, status changes, new and removed tests between two runs
+func TestCompare(t *testing.T) {
+	baseline := Run{ID: "baseline", TestCases: []TestCase{
+		{Suite: "build", Name: "creates a build", Status: PassedStatus, Duration: 100},
+		{Suite: "build", Name: "deletes a build", Status: PassedStatus, Duration: 10},
+		{Suite: "build", Name: "short", Status: PassedStatus, Duration: 0.1},
+		{Suite: "build", Name: "retried", Status: FailedStatus, Duration: 5},
+		{Suite: "build", Name: "retried", Status: PassedStatus, Duration: 5},
+		{Suite: "build", Name: "skipped", Status: SkippedStatus},
+		{Suite: "build", Name: "removed", Status: PassedStatus, Duration: 1},
+	}}
+	candidate := Run{ID: "candidate", TestCases: []TestCase{
+		{Suite: "build", Name: "creates a build", Status: PassedStatus, Duration: 110},
+		{Suite: "build", Name: "deletes a build", Status: FailedStatus, Duration: 30},
+		{Suite: "build", Name: "short", Status: PassedStatus, Duration: 0.5},
+		{Suite: "build", Name: "retried", Status: PassedStatus, Duration: 5},
+		{Suite: "build", Name: "skipped", Status: PassedStatus, Duration: 60},
+		{Suite: "build", Name: "added", Status: PassedStatus, Duration: 1},
+	}}
+
+	c := Compare(baseline, candidate, CompareOptions{DurationThreshold: 0.2, MinDurationDelta: time.Second})
+	if c.Baseline != "baseline" || c.Candidate != "candidate" {
+		t.Errorf("unexpected compared runs: %s, %s", c.Baseline, c.Candidate)
+	}
+
+	// "creates a build" grew only by 10%, "short" only by 0.4s and "skipped" wasn't executed in the baseline
+	if len(c.DurationRegressions) != 1 {
+		t.Fatalf("expected 1 duration regression, got %+v", c.DurationRegressions)
+	}
+	if dc := c.DurationRegressions[0]; dc.Name != "deletes a build" || dc.Baseline != 10 || dc.Candidate != 30 || dc.Growth != 2 {
+		t.Errorf("unexpected duration regression: %+v", dc)
+	}
+
+	want := []StatusChange{
+		{Suite: "build", Name: "deletes a build", Baseline: PassedStatus, Candidate: FailedStatus},
+		{Suite: "build", Name: "skipped", Baseline: SkippedStatus, Candidate: PassedStatus},
+	}
+	if len(c.StatusChanges) != len(want) {
+		t.Fatalf("expected status changes %+v, got %+v", want, c.StatusChanges)
+	}
+	for i := range want {
+		if c.StatusChanges[i] != want[i] {
+			t.Errorf("expected status change %+v, got %+v", want[i], c.StatusChanges[i])
+		}
+	}
+
+	if len(c.NewTests) != 1 || c.NewTests[0].Name != "added" {
+		t.Errorf("expected the new test \"added\", got %+v", c.NewTests)
+	}
+	if len(c.RemovedTests) != 1 || c.RemovedTests[0].Name != "removed" {
+		t.Errorf("expected the removed test \"removed\", got %+v", c.RemovedTests)
+	}
+
+	md := FormatComparisonMarkdown(c)
+	for _, s := range []string{"### Duration regressions (growth of at least 20% and 1s)", "| build | deletes a build | 10s | 30s | +200.0% |", "### Status changes", "### New tests", "### Removed tests"} {
+		if !strings.Contains(md, s) {
+			t.Errorf("expected %q in the Markdown output:\n%s", s, md)
+		}
+	}
+	if md := FormatComparisonMarkdown(Compare(baseline, baseline, CompareOptions{})); !strings.Contains(md, "No differences found.") {
+		t.Errorf("expected no differences when comparing the run with itself, got:\n%s", md)
+	}
+}
+
+// TestFormatComparisonJSON tests that the JSON output contains empty lists instead of nulls
+func TestFormatComparisonJSON(t *testing.T) {
+	out, err := FormatComparisonJSON(Comparison{Baseline: "1", Candidate: "2"})
+	if err != nil {
+		t.Fatalf("failed to format comparison: %v", err)
+	}
+	if strings.Contains(string(out), "null") {
+		t.Errorf("expected no nulls in the JSON output, got:\n%s", out)
+	}
+}
@@ -0,0 +1,143 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FormatJSON returns the query results as an indented JSON
+func FormatJSON[T any](results []T) ([]byte, error) {
+	if results == nil {
+		results = []T{}
+	}
+	return json.MarshalIndent(results, "", "    ")
+}
+
+// FormatPassRateMarkdown returns the pass-rate over time as a Markdown table
+func FormatPassRateMarkdown(passRates []PassRate, interval time.Duration) string {
+	var sb strings.Builder
+	sb.WriteString("## Pass-rate over time\n\n")
+	if len(passRates) == 0 {
+		sb.WriteString("No runs found.\n")
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "| Interval start (%s) | Runs | Passed | Failed | Skipped | Pass-rate |\n", interval)
+	sb.WriteString("|----------------------|------|--------|--------|---------|-----------|\n")
+	for _, pr := range passRates {
+		fmt.Fprintf(&sb, "| %s | %d | %d | %d | %d | %.1f%% |\n", pr.Start.Format(time.RFC3339), pr.Runs, pr.Passed, pr.Failed, pr.Skipped, pr.PassRate*100)
+	}
+	return sb.String()
+}
+
+// FormatTestStatsMarkdown returns the statistics of test cases as a Markdown table with the given title
+func FormatTestStatsMarkdown(title string, stats []TestStats) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## %s\n\n", title)
+	if len(stats) == 0 {
+		sb.WriteString("No test cases found.\n")
+		return sb.String()
+	}
+	sb.WriteString("| # | Suite | Test | Failures / Runs | Failure rate | Avg duration | Max duration |\n")
+	sb.WriteString("|---|-------|------|-----------------|--------------|--------------|--------------|\n")
+	for i, ts := range stats {
+		fmt.Fprintf(&sb, "| %d | %s | %s | %d / %d | %.1f%% | %s | %s |\n", i+1, escapeCell(ts.Suite), escapeCell(ts.Name), ts.Failures, ts.Runs, ts.FailureRate*100, seconds(ts.AvgDuration), seconds(ts.MaxDuration))
+	}
+	return sb.String()
+}
+
+// FormatRepoStatsMarkdown returns the per-repository breakdown as a Markdown table
+func FormatRepoStatsMarkdown(stats []RepoStats) string {
+	var sb strings.Builder
+	sb.WriteString("## Results per repository\n\n")
+	if len(stats) == 0 {
+		sb.WriteString("No runs found.\n")
+		return sb.String()
+	}
+	sb.WriteString("| Repository | Runs | Failed runs | Passed | Failed | Pass-rate |\n")
+	sb.WriteString("|------------|------|-------------|--------|--------|-----------|\n")
+	for _, rs := range stats {
+		fmt.Fprintf(&sb, "| %s | %d | %d | %d | %d | %.1f%% |\n", escapeCell(rs.Repo), rs.Runs, rs.FailedRuns, rs.Passed, rs.Failed, rs.PassRate*100)
+	}
+	return sb.String()
+}
+
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func seconds(s float64) string {
+	return (time.Duration(s * float64(time.Second))).Round(time.Millisecond).String()
+}
+
+// FormatComparisonJSON returns differences between the baseline and the candidate run as an indented JSON
+func FormatComparisonJSON(c Comparison) ([]byte, error) {
+	// empty lists are easier to consume than nulls
+	for _, list := range []*[]TestCase{&c.NewTests, &c.RemovedTests} {
+		if *list == nil {
+			*list = []TestCase{}
+		}
+	}
+	if c.DurationRegressions == nil {
+		c.DurationRegressions = []DurationChange{}
+	}
+	if c.StatusChanges == nil {
+		c.StatusChanges = []StatusChange{}
+	}
+	return json.MarshalIndent(c, "", "    ")
+}
+
+// FormatComparisonMarkdown returns differences between the baseline and the candidate run as Markdown
+func FormatComparisonMarkdown(c Comparison) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## Comparison of %s (candidate) with %s (baseline)\n\n", c.Candidate, c.Baseline)
+	if len(c.DurationRegressions)+len(c.StatusChanges)+len(c.NewTests)+len(c.RemovedTests) == 0 {
+		sb.WriteString("No differences found.\n")
+		return sb.String()
+	}
+
+	if len(c.DurationRegressions) > 0 {
+		fmt.Fprintf(&sb, "### Duration regressions (growth of at least %.0f%% and %s)\n\n", c.DurationThreshold*100, seconds(c.MinDurationDelta))
+		sb.WriteString("| Suite | Test | Baseline | Candidate | Growth |\n")
+		sb.WriteString("|-------|------|----------|-----------|--------|\n")
+		for _, dc := range c.DurationRegressions {
+			growth := "n/a"
+			if dc.Baseline > 0 {
+				growth = fmt.Sprintf("+%.1f%%", dc.Growth*100)
+			}
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s |\n", escapeCell(dc.Suite), escapeCell(dc.Name), seconds(dc.Baseline), seconds(dc.Candidate), growth)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(c.StatusChanges) > 0 {
+		sb.WriteString("### Status changes\n\n")
+		sb.WriteString("| Suite | Test | Baseline | Candidate |\n")
+		sb.WriteString("|-------|------|----------|-----------|\n")
+		for _, sc := range c.StatusChanges {
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", escapeCell(sc.Suite), escapeCell(sc.Name), sc.Baseline, sc.Candidate)
+		}
+		sb.WriteString("\n")
+	}
+
+	for _, section := range []struct {
+		title     string
+		testCases []TestCase
+	}{
+		{"New tests", c.NewTests},
+		{"Removed tests", c.RemovedTests},
+	} {
+		if len(section.testCases) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "### %s\n\n", section.title)
+		sb.WriteString("| Suite | Test | Status | Duration |\n")
+		sb.WriteString("|-------|------|--------|----------|\n")
+		for _, tc := range section.testCases {
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", escapeCell(tc.Suite), escapeCell(tc.Name), tc.Status, seconds(tc.Duration))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,120 @@
+package history
+
+import (
+	"time"
+
+	"github.com/bsm/ginkgo/v2/reporters"
+	"github.com/konflux-ci/qe-tools/pkg/junitutil"
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+	ginkgoTypes "github.com/onsi/ginkgo/v2/types"
+)
+
+// Status represents the normalized result of a test case
+type Status string
+
+const (
+	// PassedStatus represents a test case that passed
+	PassedStatus Status = "passed"
+	// FailedStatus represents a test case that failed (or errored, panicked, timed out, ...)
+	FailedStatus Status = "failed"
+	// SkippedStatus represents a test case that wasn't executed (skipped, pending, disabled)
+	SkippedStatus Status = "skipped"
+)
+
+// Sources of the ingested runs
+const (
+	CreateReportSource       = "create-report"
+	AnalyzeTestResultsSource = "analyze-test-results"
+)
+
+// Run holds results of all test cases executed within a single job run, together with the job metadata
+type Run struct {
+	// ID identifies the run within the store (e.g. the Prow job ID) - ingesting a run with the same ID
+	// and Source replaces the stored one
+	ID string `json:"id"`
+	// Source is the command the run was ingested by (CreateReportSource, AnalyzeTestResultsSource)
+	Source       string    `json:"source"`
+	JobName      string    `json:"jobName,omitempty"`
+	JobType      string    `json:"jobType,omitempty"`
+	Organization string    `json:"org,omitempty"`
+	Repo         string    `json:"repo,omitempty"`
+	PullNumber   int       `json:"pullNumber,omitempty"`
+	URL          string    `json:"url,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+
+	TestCases []TestCase `json:"testCases"`
+}
+
+// TestCase represents the result of a single test case within the Run
+type TestCase struct {
+	Suite  string `json:"suite"`
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	// Duration of the test case in seconds
+	Duration float64 `json:"duration"`
+	// Message is the failure (or error) message of a failed test case
+	Message string `json:"message,omitempty"`
+}
+
+// SetJobSpec sets the job metadata of the run from the openshift-ci job spec
+func (r *Run) SetJobSpec(spec *prow.OpenshiftJobSpec) {
+	if spec == nil {
+		return
+	}
+	if r.JobName == "" {
+		r.JobName = spec.Job
+	}
+	r.JobType = spec.Type
+	r.Organization = spec.Refs.Organization
+	r.Repo = spec.Refs.Repo
+	if len(spec.Refs.Pulls) > 0 {
+		r.PullNumber = spec.Refs.Pulls[0].Number
+	}
+}
+
+// RepoName returns the full name of the repository the run tested, e.g. "org/repo"
+func (r Run) RepoName() string {
+	if r.Organization == "" {
+		return r.Repo
+	}
+	return r.Organization + "/" + r.Repo
+}
+
+// TestCasesFromJUnit returns results of the test cases within the JUnit report (e.g. created by "prowjob create-report"
+// or collected by "analyze-test-results")
+func TestCasesFromJUnit(suites *reporters.JUnitTestSuites) []TestCase {
+	if suites == nil {
+		return nil
+	}
+	var testCases []TestCase
+	for _, suite := range suites.TestSuites {
+		for _, tc := range suite.TestCases {
+			testCases = append(testCases, TestCase{
+				Suite:    suite.Name,
+				Name:     tc.Name,
+				Status:   TestCaseStatus(tc),
+				Duration: tc.Time,
+				Message:  junitutil.FailureMessage(tc),
+			})
+		}
+	}
+	return testCases
+}
+
+// TestCaseStatus normalizes the status of the test case reported by Ginkgo (or another JUnit producer)
+func TestCaseStatus(tc reporters.JUnitTestCase) Status {
+	switch {
+	case tc.Failure != nil || tc.Error != nil:
+		return FailedStatus
+	case tc.Skipped != nil:
+		return SkippedStatus
+	}
+	switch tc.Status {
+	case ginkgoTypes.SpecStateSkipped.String(), ginkgoTypes.SpecStatePending.String(), "disabled":
+		return SkippedStatus
+	case ginkgoTypes.SpecStateFailed.String(), ginkgoTypes.SpecStatePanicked.String(), ginkgoTypes.SpecStateTimedout.String(),
+		ginkgoTypes.SpecStateInterrupted.String(), ginkgoTypes.SpecStateAborted.String():
+		return FailedStatus
+	}
+	return PassedStatus
+}
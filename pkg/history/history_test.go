@@ -0,0 +1,147 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bsm/ginkgo/v2/reporters"
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+)
+
+var day = time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+func testRuns() []Run {
+	return []Run{
+		{ID: "1", Source: CreateReportSource, JobName: "e2e", Organization: "org", Repo: "build-service", Timestamp: day.Add(time.Hour), TestCases: []TestCase{
+			{Suite: "build", Name: "creates a build", Status: FailedStatus, Duration: 30},
+			{Suite: "build", Name: "deletes a build", Status: PassedStatus, Duration: 10},
+			{Suite: "build", Name: "pending", Status: SkippedStatus},
+		}},
+		{ID: "2", Source: CreateReportSource, JobName: "e2e", Organization: "org", Repo: "build-service", Timestamp: day.Add(2 * time.Hour), TestCases: []TestCase{
+			{Suite: "build", Name: "creates a build", Status: PassedStatus, Duration: 50},
+			{Suite: "build", Name: "deletes a build", Status: PassedStatus, Duration: 10},
+		}},
+		{ID: "3", Source: AnalyzeTestResultsSource, JobName: "integration", Organization: "org", Repo: "integration-service", Timestamp: day.Add(25 * time.Hour), TestCases: []TestCase{
+			{Suite: "integration", Name: "runs a pipeline", Status: FailedStatus, Duration: 100},
+			{Suite: "build", Name: "creates a build", Status: FailedStatus, Duration: 20},
+		}},
+	}
+}
+
+// TestStore tests ingesting runs and reading them back filtered
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	for _, run := range testRuns() {
+		if err := store.Ingest(run); err != nil {
+			t.Fatalf("failed to ingest run %s: %v", run.ID, err)
+		}
+	}
+	// Ingesting the same run again replaces it
+	if err := store.Ingest(testRuns()[0]); err != nil {
+		t.Fatalf("failed to ingest run again: %v", err)
+	}
+	if err := store.Ingest(Run{}); err == nil {
+		t.Errorf("expected an error for a run without ID")
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("failed to close store: %v", err)
+	}
+
+	store, err = Open(path)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	defer store.Close()
+
+	runs, err := store.Runs(Filter{})
+	if err != nil {
+		t.Fatalf("failed to read runs: %v", err)
+	}
+	if len(runs) != 3 || runs[0].ID != "1" || runs[2].ID != "3" || len(runs[0].TestCases) != 3 {
+		t.Errorf("expected all 3 runs, the oldest first, got %+v", runs)
+	}
+
+	tests := []struct {
+		filter   Filter
+		expected int
+	}{
+		{filter: Filter{Since: day.Add(24 * time.Hour)}, expected: 1},
+		{filter: Filter{Until: day.Add(90 * time.Minute)}, expected: 1},
+		{filter: Filter{JobName: "e2e"}, expected: 2},
+		{filter: Filter{Repo: "org/integration-service"}, expected: 1},
+		{filter: Filter{Repo: "build-service"}, expected: 2},
+		{filter: Filter{Repo: "other/build-service"}, expected: 0},
+		{filter: Filter{Source: AnalyzeTestResultsSource}, expected: 1},
+	}
+	for _, tt := range tests {
+		runs, err := store.Runs(tt.filter)
+		if err != nil {
+			t.Fatalf("failed to read runs: %v", err)
+		}
+		if len(runs) != tt.expected {
+			t.Errorf("expected %d run(s) for filter %+v, got %d", tt.expected, tt.filter, len(runs))
+		}
+	}
+}
+
+// TestQueries tests the aggregations of the stored runs
+func TestQueries(t *testing.T) {
+	runs := testRuns()
+
+	passRates := PassRateOverTime(runs, 24*time.Hour)
+	if len(passRates) != 2 || passRates[0].Runs != 2 || passRates[0].Passed != 3 || passRates[0].Failed != 1 || passRates[0].Skipped != 1 || passRates[0].PassRate != 0.75 ||
+		passRates[1].Start != day.Add(24*time.Hour) || passRates[1].PassRate != 0 {
+		t.Errorf("unexpected pass-rates: %+v", passRates)
+	}
+
+	slowest := SlowestTests(runs, 2)
+	if len(slowest) != 2 || slowest[0].Name != "runs a pipeline" || slowest[1].Name != "creates a build" || slowest[1].AvgDuration != 100.0/3 || slowest[1].MaxDuration != 50 {
+		t.Errorf("unexpected slowest tests: %+v", slowest)
+	}
+
+	failing := MostFailingTests(runs, 0)
+	if len(failing) != 2 || failing[0].Name != "creates a build" || failing[0].Failures != 2 || failing[0].Runs != 3 || failing[1].Name != "runs a pipeline" {
+		t.Errorf("unexpected most failing tests: %+v", failing)
+	}
+
+	repos := RepoBreakdown(runs)
+	if len(repos) != 2 || repos[0].Repo != "org/build-service" || repos[0].Runs != 2 || repos[0].FailedRuns != 1 || repos[0].PassRate != 0.75 ||
+		repos[1].Repo != "org/integration-service" || repos[1].FailedRuns != 1 {
+		t.Errorf("unexpected repo breakdown: %+v", repos)
+	}
+}
+
+// TestRunMetadata tests converting JUnit reports and job specs to runs
+func TestRunMetadata(t *testing.T) {
+	suites := &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{{
+		Name: "build",
+		TestCases: []reporters.JUnitTestCase{
+			{Name: "passed", Status: "passed", Time: 1.5},
+			{Name: "failed", Status: "failed", Failure: &reporters.JUnitFailure{Message: "failed"}},
+			{Name: "timed out", Status: "timedout"},
+			{Name: "pending", Status: "pending"},
+			{Name: "skipped", Skipped: &reporters.JUnitSkipped{Message: "skipped"}},
+		},
+	}}}
+	expected := []Status{PassedStatus, FailedStatus, FailedStatus, SkippedStatus, SkippedStatus}
+	testCases := TestCasesFromJUnit(suites)
+	if len(testCases) != len(expected) || testCases[0].Duration != 1.5 || testCases[1].Message != "failed" {
+		t.Fatalf("unexpected test cases: %+v", testCases)
+	}
+	for i, status := range expected {
+		if testCases[i].Status != status {
+			t.Errorf("expected status %q of %q, got %q", status, testCases[i].Name, testCases[i].Status)
+		}
+	}
+
+	run := Run{ID: "1"}
+	run.SetJobSpec(&prow.OpenshiftJobSpec{Type: "presubmit", Job: "pull-ci-org-repo-main-e2e", Refs: prow.Refs{Organization: "org", Repo: "repo", Pulls: []prow.Pull{{Number: 42}}}})
+	if run.JobName != "pull-ci-org-repo-main-e2e" || run.JobType != "presubmit" || run.RepoName() != "org/repo" || run.PullNumber != 42 {
+		t.Errorf("unexpected run metadata: %+v", run)
+	}
+}
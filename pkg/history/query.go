@@ -0,0 +1,224 @@
+package history
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Filter selects the runs used for the queries - empty fields don't filter anything
+type Filter struct {
+	Since time.Time
+	Until time.Time
+	// JobName must be equal to the name of the run's job
+	JobName string
+	// Repo is either the full name of the repository ("org/repo") or just its name ("repo")
+	Repo string
+	// Source is the command the run was ingested by
+	Source string
+}
+
+// Match reports whether the run matches the filter
+func (f Filter) Match(run Run) bool {
+	switch {
+	case !f.Since.IsZero() && run.Timestamp.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && run.Timestamp.After(f.Until):
+		return false
+	case f.JobName != "" && run.JobName != f.JobName:
+		return false
+	case f.Source != "" && run.Source != f.Source:
+		return false
+	case f.Repo != "" && run.RepoName() != f.Repo && (strings.Contains(f.Repo, "/") || run.Repo != f.Repo):
+		return false
+	}
+	return true
+}
+
+// PassRate is the pass-rate of the test cases executed within the runs started in the given interval
+type PassRate struct {
+	Start   time.Time `json:"start"`
+	Runs    int       `json:"runs"`
+	Passed  int       `json:"passed"`
+	Failed  int       `json:"failed"`
+	Skipped int       `json:"skipped"`
+	// PassRate is the ratio of passed test cases to executed (passed or failed) test cases
+	PassRate float64 `json:"passRate"`
+}
+
+// PassRateOverTime returns the pass-rate of the runs grouped into intervals of the given length (e.g. days), the oldest first
+func PassRateOverTime(runs []Run, interval time.Duration) []PassRate {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	byStart := map[time.Time]*PassRate{}
+	for _, run := range runs {
+		start := run.Timestamp.UTC().Truncate(interval)
+		pr, ok := byStart[start]
+		if !ok {
+			pr = &PassRate{Start: start}
+			byStart[start] = pr
+		}
+		pr.Runs++
+		for _, tc := range run.TestCases {
+			switch tc.Status {
+			case PassedStatus:
+				pr.Passed++
+			case FailedStatus:
+				pr.Failed++
+			case SkippedStatus:
+				pr.Skipped++
+			}
+		}
+	}
+
+	result := make([]PassRate, 0, len(byStart))
+	for _, pr := range byStart {
+		pr.PassRate = ratio(pr.Passed, pr.Passed+pr.Failed)
+		result = append(result, *pr)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+	return result
+}
+
+// TestStats holds statistics of a test case across the runs
+type TestStats struct {
+	Suite string `json:"suite"`
+	Name  string `json:"name"`
+	// Runs is the number of runs the test case was executed in (skipped test cases aren't counted)
+	Runs        int     `json:"runs"`
+	Failures    int     `json:"failures"`
+	FailureRate float64 `json:"failureRate"`
+	// AvgDuration and MaxDuration are in seconds
+	AvgDuration float64 `json:"avgDuration"`
+	MaxDuration float64 `json:"maxDuration"`
+}
+
+// testStatistics returns statistics of all test cases executed within the runs
+func testStatistics(runs []Run) []TestStats {
+	type key struct{ suite, name string }
+	byTest := map[key]*TestStats{}
+	var order []key
+	for _, run := range runs {
+		for _, tc := range run.TestCases {
+			if tc.Status == SkippedStatus {
+				continue
+			}
+			k := key{tc.Suite, tc.Name}
+			ts, ok := byTest[k]
+			if !ok {
+				ts = &TestStats{Suite: tc.Suite, Name: tc.Name}
+				byTest[k] = ts
+				order = append(order, k)
+			}
+			ts.Runs++
+			if tc.Status == FailedStatus {
+				ts.Failures++
+			}
+			ts.AvgDuration += tc.Duration
+			ts.MaxDuration = max(ts.MaxDuration, tc.Duration)
+		}
+	}
+
+	result := make([]TestStats, 0, len(order))
+	for _, k := range order {
+		ts := byTest[k]
+		ts.FailureRate = ratio(ts.Failures, ts.Runs)
+		ts.AvgDuration /= float64(ts.Runs)
+		result = append(result, *ts)
+	}
+	return result
+}
+
+// SlowestTests returns at most 'limit' test cases with the longest average duration (0 means no limit)
+func SlowestTests(runs []Run, limit int) []TestStats {
+	stats := testStatistics(runs)
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].AvgDuration > stats[j].AvgDuration
+	})
+	return truncate(stats, limit)
+}
+
+// MostFailingTests returns at most 'limit' test cases with the most failures (0 means no limit)
+func MostFailingTests(runs []Run, limit int) []TestStats {
+	var failing []TestStats
+	for _, ts := range testStatistics(runs) {
+		if ts.Failures > 0 {
+			failing = append(failing, ts)
+		}
+	}
+	sort.SliceStable(failing, func(i, j int) bool {
+		if failing[i].Failures != failing[j].Failures {
+			return failing[i].Failures > failing[j].Failures
+		}
+		return failing[i].FailureRate > failing[j].FailureRate
+	})
+	return truncate(failing, limit)
+}
+
+// RepoStats holds statistics of the runs testing a single repository
+type RepoStats struct {
+	Repo string `json:"repo"`
+	Runs int    `json:"runs"`
+	// FailedRuns is the number of runs with at least one failed test case
+	FailedRuns int     `json:"failedRuns"`
+	Passed     int     `json:"passed"`
+	Failed     int     `json:"failed"`
+	PassRate   float64 `json:"passRate"`
+}
+
+// RepoBreakdown returns statistics of the runs grouped by the tested repository, sorted by the repository name
+func RepoBreakdown(runs []Run) []RepoStats {
+	byRepo := map[string]*RepoStats{}
+	for _, run := range runs {
+		repo := run.RepoName()
+		if repo == "" {
+			repo = "unknown"
+		}
+		rs, ok := byRepo[repo]
+		if !ok {
+			rs = &RepoStats{Repo: repo}
+			byRepo[repo] = rs
+		}
+		rs.Runs++
+		failed := false
+		for _, tc := range run.TestCases {
+			switch tc.Status {
+			case PassedStatus:
+				rs.Passed++
+			case FailedStatus:
+				rs.Failed++
+				failed = true
+			}
+		}
+		if failed {
+			rs.FailedRuns++
+		}
+	}
+
+	result := make([]RepoStats, 0, len(byRepo))
+	for _, rs := range byRepo {
+		rs.PassRate = ratio(rs.Passed, rs.Passed+rs.Failed)
+		result = append(result, *rs)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Repo < result[j].Repo
+	})
+	return result
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+func truncate(stats []TestStats, limit int) []TestStats {
+	if limit > 0 && len(stats) > limit {
+		return stats[:limit]
+	}
+	return stats
+}
@@ -0,0 +1,83 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var runsBucket = []byte("runs")
+
+// openTimeout limits waiting for the lock of the database file held by another process
+const openTimeout = 10 * time.Second
+
+// Store is the embedded database (a single bbolt file) with the history of test results
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the store in the given file, creating it if it doesn't exist
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results database %s: %+v", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(runsBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize results database %s: %+v", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database file
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Ingest stores the run, replacing the previously ingested run with the same Source and ID
+func (s *Store) Ingest(run Run) error {
+	if run.ID == "" {
+		return fmt.Errorf("cannot ingest a run without ID")
+	}
+	if run.Timestamp.IsZero() {
+		run.Timestamp = time.Now()
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run %s: %+v", run.ID, err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(runsBucket).Put([]byte(run.Source+"/"+run.ID), data)
+	})
+}
+
+// Runs returns the stored runs matching the filter, the oldest first
+func (s *Store) Runs(filter Filter) ([]Run, error) {
+	var runs []Run
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(runsBucket).ForEach(func(k, v []byte) error {
+			var run Run
+			if err := json.Unmarshal(v, &run); err != nil {
+				return fmt.Errorf("failed to unmarshal run %s: %+v", k, err)
+			}
+			if filter.Match(run) {
+				runs = append(runs, run)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].Timestamp.Before(runs[j].Timestamp)
+	})
+	return runs, nil
+}
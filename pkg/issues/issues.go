@@ -0,0 +1,246 @@
+package issues
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bsm/ginkgo/v2/reporters"
+	"github.com/konflux-ci/qe-tools/pkg/logexcerpt"
+	"github.com/konflux-ci/qe-tools/pkg/types"
+)
+
+const (
+	// SignatureLabelPrefix is the prefix of the Jira label identifying issues filed for the same failure signature
+	SignatureLabelPrefix = "qe-tools-signature-"
+
+	maxSummaryLength = 250
+)
+
+var (
+	uuidRegexp   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	hexRegexp    = regexp.MustCompile(`\b[0-9a-fA-F]{7,}\b`)
+	numberRegexp = regexp.MustCompile(`[0-9]+`)
+	spaceRegexp  = regexp.MustCompile(`\s+`)
+)
+
+// Failure represents a failed test case (or openshift-ci step) to be filed as an issue
+type Failure struct {
+	TestName string
+	Message  string
+	// Log is an optional excerpt of the log of the failure
+	Log      string
+	Category string
+	JobName  string
+	JobURL   string
+}
+
+// logExcerptOptions keep the excerpt of the log small enough for the issue description
+var logExcerptOptions = logexcerpt.Options{ContextLines: 5, MaxBytes: 4000}
+
+// NewFailure creates a failure of the given test case (or step) keeping only the excerpt of its log around the errors
+func NewFailure(testName, message, log string) Failure {
+	f := Failure{TestName: testName, Message: message}
+	if log != "" {
+		excerpt, _ := logexcerpt.Extract(log, logExcerptOptions)
+		f.Log = excerpt.Text
+	}
+	return f
+}
+
+// Signature returns the identifier of the failure which is the same for all occurrences of the failure,
+// i.e. it doesn't depend on numbers, UUIDs, hashes etc. within the failure message
+func (f Failure) Signature() string {
+	sum := sha256.Sum256([]byte(f.TestName + "\n" + normalizeMessage(f.Message)))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func normalizeMessage(message string) string {
+	message = uuidRegexp.ReplaceAllString(message, "<uuid>")
+	message = hexRegexp.ReplaceAllString(message, "<hex>")
+	message = numberRegexp.ReplaceAllString(message, "<n>")
+	return strings.TrimSpace(spaceRegexp.ReplaceAllString(message, " "))
+}
+
+// FromJUnit returns failures of the test cases within the given JUnit suites, e.g. produced by "prowjob create-report"
+// or analyzed by "analyze-test-results". Classification and job URL are taken from properties of the suites if available
+// (defaulting to the given jobName and jobURL) and test cases matching known issues are skipped
+func FromJUnit(suites *reporters.JUnitTestSuites, jobName, jobURL string) []Failure {
+	var failures []Failure
+	for _, suite := range suites.TestSuites {
+		// test cases of the aggregate suite only summarize results of the jobs, so they're not filed as failures
+		if suite.Name == types.AggregateTestSuiteName {
+			continue
+		}
+		properties := map[string]string{}
+		for _, p := range suite.Properties.Properties {
+			properties[p.Name] = p.Value
+		}
+		suiteJobName, suiteJobURL := jobName, jobURL
+		if v, ok := properties["job-name"]; ok {
+			suiteJobName = v
+		}
+		if v, ok := properties["job-url"]; ok {
+			suiteJobURL = v
+		}
+
+		for _, tc := range suite.TestCases {
+			var message string
+			switch {
+			case tc.Failure != nil:
+				message = strings.TrimSpace(tc.Failure.Message + "\n" + tc.Failure.Description)
+			case tc.Error != nil:
+				message = strings.TrimSpace(tc.Error.Message + "\n" + tc.Error.Description)
+			default:
+				continue
+			}
+			if _, known := properties["known-issues/"+tc.Name]; known {
+				continue
+			}
+			failure := NewFailure(tc.Name, message, tc.SystemErr)
+			failure.Category = category(properties["classification/"+tc.Name])
+			failure.JobName, failure.JobURL = suiteJobName, suiteJobURL
+			failures = append(failures, failure)
+		}
+	}
+	return failures
+}
+
+// category returns the category from the classification stored in the JUnit property, e.g. "infra (80%, rules: quota)"
+func category(classification string) string {
+	if fields := strings.Fields(classification); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// Action is the action taken for failures with the same signature
+type Action string
+
+const (
+	// CreatedAction means a new issue was created
+	CreatedAction Action = "created"
+	// CommentedAction means the new occurrence was added as a comment to the existing issue
+	CommentedAction Action = "commented"
+)
+
+// Result describes the issue filed for failures with the same signature
+type Result struct {
+	Signature string
+	Action    Action
+	// IssueKey is empty if the issue would be created in the dry run
+	IssueKey string
+	IssueURL string
+	Failures []Failure
+}
+
+// Filer files failures as Jira issues. Failures are deduplicated by their signature - a new issue is created
+// only if there's no unresolved issue labeled with the signature, otherwise a comment is added to the existing one
+type Filer struct {
+	Client    *JiraClient
+	Project   string
+	IssueType string
+	// Labels are added to every created issue (in addition to the signature label)
+	Labels []string
+	// DryRun only searches for the existing issues - nothing is created or commented
+	DryRun bool
+}
+
+// File creates or updates issues for the given failures
+func (f *Filer) File(ctx context.Context, failures []Failure) ([]Result, error) {
+	var results []Result
+	bySignature := map[string]int{}
+	for _, failure := range failures {
+		sig := failure.Signature()
+		if i, ok := bySignature[sig]; ok {
+			results[i].Failures = append(results[i].Failures, failure)
+			continue
+		}
+		bySignature[sig] = len(results)
+		results = append(results, Result{Signature: sig, Failures: []Failure{failure}})
+	}
+
+	for i := range results {
+		r := &results[i]
+		jql := fmt.Sprintf("project = %q AND labels = %q AND statusCategory != Done ORDER BY created ASC", f.Project, SignatureLabelPrefix+r.Signature)
+		existing, err := f.Client.SearchIssues(ctx, jql)
+		if err != nil {
+			return results, err
+		}
+
+		if len(existing) > 0 {
+			r.Action, r.IssueKey, r.IssueURL = CommentedAction, existing[0].Key, f.Client.IssueURL(existing[0].Key)
+			if !f.DryRun {
+				if err := f.Client.AddComment(ctx, r.IssueKey, occurrencesText("New occurrence(s) of the failure", r.Failures)); err != nil {
+					return results, err
+				}
+			}
+			continue
+		}
+
+		r.Action = CreatedAction
+		if f.DryRun {
+			continue
+		}
+		key, err := f.Client.CreateIssue(ctx, f.issueFields(*r))
+		if err != nil {
+			return results, err
+		}
+		r.IssueKey, r.IssueURL = key, f.Client.IssueURL(key)
+	}
+	return results, nil
+}
+
+func (f *Filer) issueFields(r Result) IssueFields {
+	failure := r.Failures[0]
+	summary := fmt.Sprintf("%q failed", failure.TestName)
+	if failure.Category != "" {
+		summary = fmt.Sprintf("[%s] %s", failure.Category, summary)
+	}
+	// the limit of Jira is in characters, the summary is truncated on a rune boundary to keep it valid UTF-8
+	if runes := []rune(summary); len(runes) > maxSummaryLength {
+		summary = string(runes[:maxSummaryLength-3]) + "..."
+	}
+
+	var description strings.Builder
+	fmt.Fprintf(&description, "Test case (or step) *%s* has failed.\n\n", failure.TestName)
+	if failure.Category != "" {
+		fmt.Fprintf(&description, "Category: *%s*\n\n", failure.Category)
+	}
+	if failure.Message != "" {
+		fmt.Fprintf(&description, "Failure message:\n{code}\n%s\n{code}\n\n", failure.Message)
+	}
+	if failure.Log != "" {
+		fmt.Fprintf(&description, "Log excerpt:\n{noformat}\n%s\n{noformat}\n\n", failure.Log)
+	}
+	description.WriteString(occurrencesText("Occurrence(s)", r.Failures))
+	fmt.Fprintf(&description, "\n_Filed by qe-tools, failure signature: %s_", r.Signature)
+
+	labels := append(append([]string{}, f.Labels...), SignatureLabelPrefix+r.Signature)
+	return IssueFields{
+		Project:     &JiraProject{Key: f.Project},
+		IssueType:   &JiraIssueType{Name: f.IssueType},
+		Summary:     summary,
+		Description: description.String(),
+		Labels:      labels,
+	}
+}
+
+// occurrencesText returns the list of jobs the failures occurred in, formatted in Jira wiki markup
+func occurrencesText(title string, failures []Failure) string {
+	text := title + ":\n"
+	for _, failure := range failures {
+		job := failure.JobName
+		if job == "" {
+			job = "job"
+		}
+		if failure.JobURL != "" {
+			job = fmt.Sprintf("[%s|%s]", job, failure.JobURL)
+		}
+		text += fmt.Sprintf("* %s - %s\n", failure.TestName, job)
+	}
+	return text
+}
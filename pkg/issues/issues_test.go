@@ -0,0 +1,168 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bsm/ginkgo/v2/reporters"
+	"github.com/konflux-ci/qe-tools/pkg/types"
+)
+
+// fakeJira is a local stand-in of the Jira REST API keeping the issues in memory
+type fakeJira struct {
+	mu       sync.Mutex
+	issues   []JiraIssue
+	comments map[string][]string
+	auth     []string
+}
+
+func (j *fakeJira) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.auth = append(j.auth, r.Header.Get("Authorization"))
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/search":
+		var found []JiraIssue
+		for _, issue := range j.issues {
+			for _, label := range issue.Fields.Labels {
+				if strings.Contains(r.URL.Query().Get("jql"), fmt.Sprintf("labels = %q", label)) && issue.Fields.Status.Name != "Closed" {
+					found = append(found, issue)
+				}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"issues": found})
+	case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue":
+		issue := JiraIssue{}
+		if err := json.NewDecoder(r.Body).Decode(&issue); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		issue.Key = fmt.Sprintf("%s-%d", issue.Fields.Project.Key, len(j.issues)+1)
+		issue.Fields.Status = &JiraStatus{Name: "New"}
+		j.issues = append(j.issues, issue)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"key": issue.Key})
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/comment"):
+		key := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/"), "/comment")
+		comment := map[string]string{}
+		if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		j.comments[key] = append(j.comments[key], comment["body"])
+		w.WriteHeader(http.StatusCreated)
+	default:
+		http.Error(w, `{"errorMessages":["not found"]}`, http.StatusNotFound)
+	}
+}
+
+// TestFile tests creating issues for new failures and commenting on existing issues with the same signature
+func TestFile(t *testing.T) {
+	jira := &fakeJira{comments: map[string][]string{}}
+	server := httptest.NewServer(jira)
+	defer server.Close()
+
+	filer := &Filer{Client: NewJiraClient(server.URL, "", "secret"), Project: "QE", IssueType: "Bug", Labels: []string{"ci-fail"}}
+	failures := []Failure{
+		{TestName: "creates a build", Message: "timed out after 300s waiting for pod build-7f8d9c6b5", Category: "timeout", JobName: "e2e", JobURL: "https://prow/1"},
+		{TestName: "creates a build", Message: "timed out after 600s waiting for pod build-1a2b3c4d5", JobName: "e2e", JobURL: "https://prow/2"},
+		{TestName: "ipi-install-install", Message: "ipi-install-install has failed", JobName: "e2e", JobURL: "https://prow/2"},
+	}
+
+	results, err := filer.File(context.Background(), failures)
+	if err != nil {
+		t.Fatalf("failed to file issues: %v", err)
+	}
+	if len(results) != 2 || len(jira.issues) != 2 {
+		t.Fatalf("expected 2 issues to be created for 2 signatures, got results %+v and issues %+v", results, jira.issues)
+	}
+	for _, r := range results {
+		if r.Action != CreatedAction || r.IssueURL != server.URL+"/browse/"+r.IssueKey {
+			t.Errorf("unexpected result %+v", r)
+		}
+	}
+	created := jira.issues[0].Fields
+	if created.Summary != `[timeout] "creates a build" failed` || !strings.Contains(created.Description, "[e2e|https://prow/2]") {
+		t.Errorf("unexpected fields of the created issue: %+v", created)
+	}
+	if len(created.Labels) != 2 || created.Labels[0] != "ci-fail" || created.Labels[1] != SignatureLabelPrefix+results[0].Signature {
+		t.Errorf("unexpected labels of the created issue: %v", created.Labels)
+	}
+	if jira.auth[0] != "Bearer secret" {
+		t.Errorf("expected Bearer authentication, got %q", jira.auth[0])
+	}
+
+	// New occurrence of an existing issue
+	results, err = filer.File(context.Background(), []Failure{{TestName: "creates a build", Message: "timed out after 900s waiting for pod build-0e0e0e0e0", JobName: "e2e", JobURL: "https://prow/3"}})
+	if err != nil {
+		t.Fatalf("failed to file issues: %v", err)
+	}
+	if len(results) != 1 || results[0].Action != CommentedAction || results[0].IssueKey != "QE-1" || len(jira.issues) != 2 {
+		t.Fatalf("expected the existing issue to be commented, got %+v", results)
+	}
+	if comments := jira.comments["QE-1"]; len(comments) != 1 || !strings.Contains(comments[0], "[e2e|https://prow/3]") {
+		t.Errorf("unexpected comments of the existing issue: %v", comments)
+	}
+
+	// Closed issue isn't reused
+	jira.issues[1].Fields.Status.Name = "Closed"
+	filer.DryRun = true
+	results, err = filer.File(context.Background(), failures[2:])
+	if err != nil {
+		t.Fatalf("failed to file issues: %v", err)
+	}
+	if len(results) != 1 || results[0].Action != CreatedAction || results[0].IssueKey != "" || len(jira.issues) != 2 {
+		t.Errorf("expected a new issue to be created in the dry run, got %+v", results)
+	}
+}
+
+// TestIssueSummaryTruncation tests truncating long summaries on a rune boundary
+func TestIssueSummaryTruncation(t *testing.T) {
+	filer := &Filer{Project: "QE"}
+	fields := filer.issueFields(Result{Failures: []Failure{{TestName: strings.Repeat("ü", maxSummaryLength)}}})
+	if !utf8.ValidString(fields.Summary) || utf8.RuneCountInString(fields.Summary) != maxSummaryLength || !strings.HasSuffix(fields.Summary, "...") {
+		t.Errorf("expected the summary to be truncated to %d characters, got %q", maxSummaryLength, fields.Summary)
+	}
+}
+
+// TestFromJUnit tests extracting failures from the JUnit produced by "prowjob create-report"
+func TestFromJUnit(t *testing.T) {
+	suites := &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{
+		{
+			Name: types.AggregateTestSuiteName,
+			TestCases: []reporters.JUnitTestCase{
+				{Name: "e2e #1", Failure: &reporters.JUnitFailure{Message: "1 of 2 test case(s) didn't pass"}},
+			},
+		},
+		{
+			Name: "e2e #1: openshift-ci job",
+			Properties: reporters.JUnitProperties{Properties: []reporters.JUnitProperty{
+				{Name: "job-url", Value: "https://prow/1"},
+				{Name: "classification/ipi-install-install", Value: "infra (80%, rules: quota)"},
+				{Name: "known-issues/e2e-test", Value: "BUG-1 (Open)"},
+			}},
+			TestCases: []reporters.JUnitTestCase{
+				{Name: "ipi-install-install", Failure: &reporters.JUnitFailure{Message: "ipi-install-install has failed"}, SystemErr: "line\n"},
+				{Name: "e2e-test", Failure: &reporters.JUnitFailure{Message: "e2e-test has failed"}},
+				{Name: "gather", Status: "passed"},
+			},
+		},
+	}}
+
+	failures := FromJUnit(suites, "e2e", "")
+	if len(failures) != 1 {
+		t.Fatalf("expected 1 new failure, got %+v", failures)
+	}
+	expected := Failure{TestName: "ipi-install-install", Message: "ipi-install-install has failed", Log: "line", Category: "infra", JobName: "e2e", JobURL: "https://prow/1"}
+	if failures[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, failures[0])
+	}
+}
@@ -0,0 +1,152 @@
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const jiraAPIPath = "/rest/api/2"
+
+// JiraClient is a minimal client of the Jira REST API (v2) used for filing issues
+type JiraClient struct {
+	BaseURL string
+	// Token is sent as a Bearer token (personal access token), or as a password of the basic authentication if User is set
+	Token string
+	User  string
+
+	HTTPClient *http.Client
+}
+
+// NewJiraClient creates a client of the Jira instance available on the given URL
+func NewJiraClient(baseURL, user, token string) *JiraClient {
+	return &JiraClient{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		User:       user,
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// JiraIssue represents the subset of Jira issue fields used by qe-tools
+type JiraIssue struct {
+	Key    string      `json:"key,omitempty"`
+	Fields IssueFields `json:"fields"`
+}
+
+// IssueFields holds fields of a Jira issue
+type IssueFields struct {
+	Project     *JiraProject   `json:"project,omitempty"`
+	IssueType   *JiraIssueType `json:"issuetype,omitempty"`
+	Summary     string         `json:"summary,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Labels      []string       `json:"labels,omitempty"`
+	Status      *JiraStatus    `json:"status,omitempty"`
+}
+
+// JiraProject identifies the Jira project by its key
+type JiraProject struct {
+	Key string `json:"key"`
+}
+
+// JiraIssueType identifies the type of Jira issue by its name
+type JiraIssueType struct {
+	Name string `json:"name"`
+}
+
+// JiraStatus is the status of a Jira issue
+type JiraStatus struct {
+	Name string `json:"name"`
+}
+
+// IssueURL returns the URL of the issue with the given key in the Jira UI
+func (c *JiraClient) IssueURL(key string) string {
+	return c.BaseURL + "/browse/" + key
+}
+
+// SearchIssues returns issues matching the given JQL query
+func (c *JiraClient) SearchIssues(ctx context.Context, jql string) ([]JiraIssue, error) {
+	query := url.Values{}
+	query.Set("jql", jql)
+	query.Set("fields", "summary,status,labels")
+	var result struct {
+		Issues []JiraIssue `json:"issues"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/search?"+query.Encode(), nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to search issues with %q: %+v", jql, err)
+	}
+	return result.Issues, nil
+}
+
+// CreateIssue creates an issue with the given fields and returns its key
+func (c *JiraClient) CreateIssue(ctx context.Context, fields IssueFields) (string, error) {
+	var created JiraIssue
+	if err := c.do(ctx, http.MethodPost, "/issue", JiraIssue{Fields: fields}, &created); err != nil {
+		return "", fmt.Errorf("failed to create issue %q: %+v", fields.Summary, err)
+	}
+	return created.Key, nil
+}
+
+// AddComment adds a comment with the given body to the issue
+func (c *JiraClient) AddComment(ctx context.Context, key, body string) error {
+	if err := c.do(ctx, http.MethodPost, "/issue/"+url.PathEscape(key)+"/comment", map[string]string{"body": body}, nil); err != nil {
+		return fmt.Errorf("failed to add comment to issue %s: %+v", key, err)
+	}
+	return nil
+}
+
+func (c *JiraClient) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+jiraAPIPath+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	switch {
+	case c.User != "":
+		req.SetBasicAuth(c.User, c.Token)
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %s: %s", method, path, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("cannot decode response of %s %s: %+v", method, path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package jobsummary
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	jobStateRegexp       = regexp.MustCompile(`Reporting job state '(\w+)'`)
+	failureSectionRegexp = regexp.MustCompile(`(?s)(Summarizing.*?Test Suite Failed)`)
+	testResultsRegexp    = regexp.MustCompile(`Ran (\d+) of (\d+) Specs in ([\d.]+) seconds\n(?:FAIL|SUCCESS)! -- (\d+) Passed \| (\d+) Failed \| (\d+) Pending \| (\d+) Skipped`)
+	durationRegexp       = regexp.MustCompile(`Ran for ([\dhms]+)`)
+)
+
+// FromBuildLog creates a Summary by parsing the (Ginkgo) output within the given build log.
+// It should only be used if there are no JUnit artifacts available
+func FromBuildLog(log string) *Summary {
+	s := &Summary{Source: BuildLogSource}
+
+	stateMatches := jobStateRegexp.FindStringSubmatch(log)
+	failureMatches := failureSectionRegexp.FindStringSubmatch(log)
+	s.Succeeded = !(len(stateMatches) == 2 && stateMatches[1] == "failed") && failureMatches == nil
+
+	if matches := testResultsRegexp.FindStringSubmatch(log); matches != nil {
+		s.TestResultsFound = true
+		s.Ran, _ = strconv.Atoi(matches[1])
+		s.Specs, _ = strconv.Atoi(matches[2])
+		if seconds, err := strconv.ParseFloat(matches[3], 64); err == nil {
+			s.TestDuration = time.Duration(seconds * float64(time.Second))
+		}
+		s.Passed, _ = strconv.Atoi(matches[4])
+		s.Failed, _ = strconv.Atoi(matches[5])
+		s.Pending, _ = strconv.Atoi(matches[6])
+		s.Skipped, _ = strconv.Atoi(matches[7])
+	}
+
+	if matches := durationRegexp.FindStringSubmatch(log); matches != nil {
+		if d, err := time.ParseDuration(matches[1]); err == nil {
+			s.Duration = d
+		}
+	}
+
+	if failureMatches != nil {
+		for _, line := range strings.Split(failureMatches[1], "\n") {
+			if strings.Contains(line, "[FAIL]") {
+				s.FailedSpecs = append(s.FailedSpecs, FailedSpec{Name: strings.TrimSpace(line)})
+			}
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,146 @@
+package jobsummary
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// maxListedFailures limits the number of failed specs listed in Slack messages
+const maxListedFailures = 20
+
+// Text returns the summary as a plain text message
+func (s *Summary) Text() string {
+	if s.Succeeded {
+		return "Job Succeeded"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Test Suite Summary:\n")
+	sb.WriteString(s.resultsLine())
+	if s.TestResultsFound {
+		fmt.Fprintf(&sb, "Ran %d of %d Specs in %.3f seconds\n", s.Ran, s.Specs, s.TestDuration.Seconds())
+	}
+	if s.Duration > 0 {
+		fmt.Fprintf(&sb, "Total Duration: %s\n", s.Duration)
+	}
+	if s.FailingStep != "" {
+		fmt.Fprintf(&sb, "Failing Step: %s\n", s.FailingStep)
+	}
+	if len(s.FailedSpecs) == 0 {
+		sb.WriteString("No specific failures captured in the report.\n")
+		return sb.String()
+	}
+	sb.WriteString("Failures:\n")
+	for _, spec := range s.FailedSpecs {
+		sb.WriteString("- " + spec.Name + "\n")
+	}
+	return sb.String()
+}
+
+// Markdown returns the summary formatted in Markdown
+func (s *Summary) Markdown() string {
+	var sb strings.Builder
+	title := "Job Summary"
+	if s.JobName != "" {
+		title = s.JobName
+	}
+	if s.JobURL != "" {
+		title = fmt.Sprintf("[%s](%s)", title, s.JobURL)
+	}
+	status := ":white_check_mark: Succeeded"
+	if !s.Succeeded {
+		status = ":x: Failed"
+	}
+	fmt.Fprintf(&sb, "### %s: %s\n\n", title, status)
+	sb.WriteString(s.resultsLine() + "\n")
+	if s.TestResultsFound {
+		fmt.Fprintf(&sb, "Ran %d of %d Specs in %.3f seconds\n\n", s.Ran, s.Specs, s.TestDuration.Seconds())
+	}
+	if s.Duration > 0 {
+		fmt.Fprintf(&sb, "**Total Duration:** %s\n\n", s.Duration)
+	}
+	if len(s.FailedSteps) > 0 {
+		fmt.Fprintf(&sb, "**Failed Steps:** `%s`\n\n", strings.Join(s.FailedSteps, "`, `"))
+	}
+	if len(s.FailedSpecs) > 0 {
+		sb.WriteString("**Failures:**\n")
+		for _, spec := range s.FailedSpecs {
+			sb.WriteString("- " + spec.Name + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// SlackBlocks returns the summary as Slack message blocks
+func (s *Summary) SlackBlocks() []slack.Block {
+	status := ":white_check_mark: Job Succeeded"
+	if !s.Succeeded {
+		status = ":x: Job Failed"
+	}
+	header := status
+	if s.JobName != "" {
+		header = s.JobName + ": " + status
+	}
+	blocks := []slack.Block{slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, header, true, false))}
+
+	var fields []*slack.TextBlockObject
+	if s.TestResultsFound {
+		fields = append(fields,
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*Passed:* %d", s.Passed), false, false),
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*Failed:* %d", s.Failed), false, false),
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*Pending:* %d", s.Pending), false, false),
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*Skipped:* %d", s.Skipped), false, false),
+		)
+	}
+	if s.Duration > 0 {
+		fields = append(fields, slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*Total Duration:* %s", s.Duration), false, false))
+	}
+	if s.FailingStep != "" {
+		fields = append(fields, slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*Failing Step:* `%s`", s.FailingStep), false, false))
+	}
+	var text *slack.TextBlockObject
+	if !s.TestResultsFound {
+		text = slack.NewTextBlockObject(slack.MarkdownType, strings.TrimSpace(s.resultsLine()), false, false)
+	}
+	if text != nil || len(fields) > 0 {
+		blocks = append(blocks, slack.NewSectionBlock(text, fields, nil))
+	}
+
+	if len(s.FailedSpecs) > 0 {
+		var sb strings.Builder
+		sb.WriteString("*Failures:*\n")
+		for i, spec := range s.FailedSpecs {
+			if i == maxListedFailures {
+				fmt.Fprintf(&sb, "… and %d more\n", len(s.FailedSpecs)-maxListedFailures)
+				break
+			}
+			sb.WriteString("• " + spec.Name + "\n")
+		}
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, sb.String(), false, false), nil, nil))
+	}
+
+	if s.JobURL != "" {
+		blocks = append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("<%s|View the job>", s.JobURL), false, false)))
+	}
+	return blocks
+}
+
+// SlackJSON returns the summary as a JSON-encoded Slack message payload ({"blocks": [...]})
+func (s *Summary) SlackJSON() ([]byte, error) {
+	return json.MarshalIndent(map[string]any{"blocks": s.SlackBlocks()}, "", "    ")
+}
+
+// JSON returns the summary as an indented JSON
+func (s *Summary) JSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "    ")
+}
+
+func (s *Summary) resultsLine() string {
+	if !s.TestResultsFound {
+		return "Infrastructure setup issues or failures unrelated to tests were found\n"
+	}
+	return fmt.Sprintf("Test Results: %d Passed | %d Failed | %d Pending | %d Skipped\n", s.Passed, s.Failed, s.Pending, s.Skipped)
+}
@@ -0,0 +1,101 @@
+package jobsummary
+
+import (
+	"time"
+
+	"github.com/konflux-ci/qe-tools/pkg/junitutil"
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+	ginkgoTypes "github.com/onsi/ginkgo/v2/types"
+)
+
+// Source represents the data the Summary was created from
+type Source string
+
+const (
+	// JUnitSource means the summary was created from JUnit artifacts
+	JUnitSource Source = "junit"
+	// BuildLogSource means the summary was created by parsing the build log (no JUnit artifacts were found)
+	BuildLogSource Source = "build-log"
+)
+
+// FailedSpec represents a spec (test case) that didn't pass
+type FailedSpec struct {
+	Name    string `json:"name"`
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Summary is a short summary of a Prow job run
+type Summary struct {
+	JobName   string `json:"jobName,omitempty"`
+	JobURL    string `json:"jobURL,omitempty"`
+	Succeeded bool   `json:"succeeded"`
+	Source    Source `json:"source"`
+	// TestResultsFound is false if no test results were found (e.g. the job failed before running the tests)
+	TestResultsFound bool `json:"testResultsFound"`
+
+	// Specs is the total number of specs, Ran is the number of specs that were run (i.e. passed or failed)
+	Specs   int `json:"specs"`
+	Ran     int `json:"ran"`
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Pending int `json:"pending"`
+	Skipped int `json:"skipped"`
+
+	// TestDuration is the time spent running the specs, Duration is the duration of the whole job
+	TestDuration time.Duration `json:"testDuration,omitempty"`
+	Duration     time.Duration `json:"duration,omitempty"`
+
+	FailedSpecs []FailedSpec `json:"failedSpecs,omitempty"`
+	// FailedSteps contains names of openshift-ci steps that failed, FailingStep is the first of them
+	FailedSteps []string `json:"failedSteps,omitempty"`
+	FailingStep string   `json:"failingStep,omitempty"`
+}
+
+// FromJUnit creates a Summary from the given JUnit test suites
+func FromJUnit(suites *reporters.JUnitTestSuites) *Summary {
+	s := &Summary{Source: JUnitSource, TestResultsFound: len(suites.TestSuites) > 0}
+	var testTime float64
+	for _, suite := range suites.TestSuites {
+		testTime += suite.Time
+		for _, tc := range suite.TestCases {
+			s.Specs++
+			switch {
+			case tc.Failure != nil || tc.Error != nil || isFailedStatus(tc.Status):
+				s.Failed++
+				s.FailedSpecs = append(s.FailedSpecs, FailedSpec{Name: tc.Name, Status: tc.Status, Message: junitutil.FailureMessage(tc)})
+			case tc.Status == ginkgoTypes.SpecStatePending.String():
+				s.Pending++
+			case tc.Skipped != nil || tc.Status == ginkgoTypes.SpecStateSkipped.String() || tc.Status == "disabled":
+				s.Skipped++
+			default:
+				s.Passed++
+			}
+		}
+	}
+	s.Ran = s.Passed + s.Failed
+	if suites.Time > 0 {
+		testTime = suites.Time
+	}
+	s.TestDuration = time.Duration(testTime * float64(time.Second))
+	s.Succeeded = s.Failed == 0
+	return s
+}
+
+// SetFailedSteps records the openshift-ci steps that failed - the job didn't succeed if there are any
+func (s *Summary) SetFailedSteps(steps []string) {
+	s.FailedSteps = steps
+	if len(steps) > 0 {
+		s.FailingStep = steps[0]
+		s.Succeeded = false
+	}
+}
+
+func isFailedStatus(status string) bool {
+	switch status {
+	case ginkgoTypes.SpecStateFailed.String(), ginkgoTypes.SpecStatePanicked.String(), ginkgoTypes.SpecStateTimedout.String(),
+		ginkgoTypes.SpecStateInterrupted.String(), ginkgoTypes.SpecStateAborted.String():
+		return true
+	}
+	return false
+}
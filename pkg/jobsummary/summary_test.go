@@ -0,0 +1,93 @@
+package jobsummary
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+// TestFromBuildLog tests creating a summary by parsing the build log
+func TestFromBuildLog(t *testing.T) {
+	tests := []struct {
+		name              string
+		log               string
+		expectedSucceeded bool
+		expectedFound     bool
+		expectedFailed    int
+		expectedSpecs     []string
+		expectedDuration  time.Duration
+	}{
+		{
+			name:              "Succeeded job",
+			log:               "Ran 10 of 12 Specs in 100.5 seconds\nSUCCESS! -- 10 Passed | 0 Failed | 0 Pending | 2 Skipped\nReporting job state 'succeeded'\nRan for 1h2m3s",
+			expectedSucceeded: true,
+			expectedFound:     true,
+			expectedDuration:  time.Hour + 2*time.Minute + 3*time.Second,
+		},
+		{
+			name: "Failed specs",
+			log: "Summarizing 2 Failures:\n  [FAIL] suite spec-a\n  [FAIL] suite spec-b\n\nRan 10 of 12 Specs in 100.5 seconds\n" +
+				"FAIL! -- 8 Passed | 2 Failed | 0 Pending | 2 Skipped\nTest Suite Failed\nReporting job state 'failed'",
+			expectedFound:  true,
+			expectedFailed: 2,
+			expectedSpecs:  []string{"[FAIL] suite spec-a", "[FAIL] suite spec-b"},
+		},
+		{
+			name: "Failed job without the failure summary",
+			log:  "failed to provision a cluster\nReporting job state 'failed'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := FromBuildLog(tt.log)
+			if s.Succeeded != tt.expectedSucceeded || s.TestResultsFound != tt.expectedFound || s.Failed != tt.expectedFailed || s.Duration != tt.expectedDuration {
+				t.Errorf("unexpected summary: %+v", s)
+			}
+			if len(s.FailedSpecs) != len(tt.expectedSpecs) {
+				t.Fatalf("expected failed specs %v, got %+v", tt.expectedSpecs, s.FailedSpecs)
+			}
+			for i, name := range tt.expectedSpecs {
+				if s.FailedSpecs[i].Name != name {
+					t.Errorf("expected failed spec %q, got %q", name, s.FailedSpecs[i].Name)
+				}
+			}
+			if text := s.Text(); text == "" {
+				t.Errorf("expected non-empty text summary")
+			}
+		})
+	}
+}
+
+// TestFromJUnit tests creating a summary from JUnit test suites
+func TestFromJUnit(t *testing.T) {
+	suites := &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{
+		{Name: "suite", Time: 12.5, TestCases: []reporters.JUnitTestCase{
+			{Name: "passed", Status: "passed"},
+			{Name: "failed", Status: "failed", Failure: &reporters.JUnitFailure{Message: "boom"}},
+			{Name: "pending", Status: "pending"},
+			{Name: "skipped", Status: "skipped", Skipped: &reporters.JUnitSkipped{}},
+		}},
+	}}
+
+	s := FromJUnit(suites)
+	s.SetFailedSteps([]string{"e2e-tests"})
+
+	if s.Specs != 4 || s.Ran != 2 || s.Passed != 1 || s.Failed != 1 || s.Pending != 1 || s.Skipped != 1 {
+		t.Errorf("unexpected counts: %+v", s)
+	}
+	if s.Succeeded || s.FailingStep != "e2e-tests" || s.TestDuration != 12500*time.Millisecond {
+		t.Errorf("unexpected summary: %+v", s)
+	}
+	if len(s.FailedSpecs) != 1 || s.FailedSpecs[0].Message != "boom" {
+		t.Errorf("unexpected failed specs: %+v", s.FailedSpecs)
+	}
+	if text := s.Text(); !strings.Contains(text, "Test Results: 1 Passed | 1 Failed | 1 Pending | 1 Skipped") || !strings.Contains(text, "- failed") {
+		t.Errorf("unexpected text summary: %s", text)
+	}
+	if _, err := s.SlackJSON(); err != nil {
+		t.Errorf("failed to create Slack message: %v", err)
+	}
+}
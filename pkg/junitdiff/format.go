@@ -0,0 +1,94 @@
+package junitdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Section is a group of specs within the formatted Diff
+type Section struct {
+	Title string
+	Specs []Spec
+	// emoji precedes the title in Markdown
+	emoji string
+}
+
+// Sections returns the categories of the diff in the order they are formatted in
+func (d Diff) Sections() []Section {
+	return []Section{
+		{"Newly failing", d.NewlyFailing, ":x:"},
+		{"Newly passing", d.NewlyPassing, ":white_check_mark:"},
+		{"Still failing", d.StillFailing, ":warning:"},
+		{"Added", d.Added, ":heavy_plus_sign:"},
+		{"Removed", d.Removed, ":heavy_minus_sign:"},
+	}
+}
+
+// Title returns the title of the diff, e.g. "Spec(s) compared with periodic job 1234"
+func (d Diff) Title() string {
+	if d.Baseline == "" {
+		return "Spec(s) compared with the baseline run"
+	}
+	return "Spec(s) compared with " + d.Baseline
+}
+
+// Markdown returns the diff as GitHub-flavoured Markdown, suitable for embedding in PR comments
+func (d Diff) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString(":mag: **" + d.Title() + "**\n")
+	if d.Empty() {
+		sb.WriteString("\nNo differences found.\n")
+		return sb.String()
+	}
+	for _, s := range d.Sections() {
+		if len(s.Specs) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n<details><summary>%s %s (%d)</summary>\n\n", s.emoji, s.Title, len(s.Specs))
+		for _, spec := range s.Specs {
+			fmt.Fprintf(&sb, "- [**`%s`**] %s\n", spec.Status, spec.Name)
+		}
+		sb.WriteString("\n</details>\n")
+	}
+	return sb.String()
+}
+
+// Text returns the diff as plain text
+func (d Diff) Text() string {
+	var sb strings.Builder
+	sb.WriteString(d.Title() + ":\n")
+	if d.Empty() {
+		sb.WriteString("No differences found.\n")
+		return sb.String()
+	}
+	for _, s := range d.Sections() {
+		if len(s.Specs) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n%s (%d):\n", s.Title, len(s.Specs))
+		for _, spec := range s.Specs {
+			fmt.Fprintf(&sb, "- [%s] %s\n", spec.Status, spec.Name)
+		}
+	}
+	return sb.String()
+}
+
+// Summary returns a one-line summary of the diff, e.g. "2 newly failing, 1 newly passing"
+func (d Diff) Summary() string {
+	var parts []string
+	for _, s := range d.Sections() {
+		if len(s.Specs) > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", len(s.Specs), strings.ToLower(s.Title)))
+		}
+	}
+	if len(parts) == 0 {
+		return "no differences"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// JSON returns the diff as an indented JSON
+func (d Diff) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "    ")
+}
@@ -0,0 +1,63 @@
+package junitdiff
+
+import (
+	"github.com/bsm/ginkgo/v2/reporters"
+	"github.com/konflux-ci/qe-tools/pkg/history"
+)
+
+// Spec represents a spec (JUnit test case) within the Diff. Its Message is the failure message of the spec in
+// the candidate run (or in the baseline run for removed specs)
+type Spec = history.TestCase
+
+// Diff holds differences between the results of the baseline run (e.g. the last green periodic job)
+// and the candidate run (e.g. a PR job)
+type Diff struct {
+	// Baseline describes the baseline run (e.g. the Prow job ID) - it's set by the caller and used only for formatting
+	Baseline string `json:"baseline,omitempty"`
+
+	// NewlyFailing are specs that failed in the candidate run, but passed (or were skipped) in the baseline run
+	NewlyFailing []Spec `json:"newlyFailing"`
+	// NewlyPassing are specs that passed in the candidate run, but failed in the baseline run
+	NewlyPassing []Spec `json:"newlyPassing"`
+	// StillFailing are specs that failed in both runs
+	StillFailing []Spec `json:"stillFailing"`
+	// Added are specs present only in the candidate run
+	Added []Spec `json:"added"`
+	// Removed are specs present only in the baseline run
+	Removed []Spec `json:"removed"`
+}
+
+// Empty reports whether there are no differences (and no specs failing in both runs)
+func (d Diff) Empty() bool {
+	return len(d.NewlyFailing)+len(d.NewlyPassing)+len(d.StillFailing)+len(d.Added)+len(d.Removed) == 0
+}
+
+// Compare returns differences between the baseline and the candidate JUnit report. Specs are matched by their suite
+// and name - if a spec is reported multiple times within a report (e.g. it was retried), its last occurrence is used.
+// Specs skipped in the candidate run aren't reported as newly passing or failing
+func Compare(baseline, candidate *reporters.JUnitTestSuites) Diff {
+	d := Diff{NewlyFailing: []Spec{}, NewlyPassing: []Spec{}, StillFailing: []Spec{}, Added: []Spec{}, Removed: []Spec{}}
+	baselineSpecs, baselineOrder := history.IndexTestCases(history.TestCasesFromJUnit(baseline))
+	candidateSpecs, candidateOrder := history.IndexTestCases(history.TestCasesFromJUnit(candidate))
+
+	for _, k := range candidateOrder {
+		spec := candidateSpecs[k]
+		base, ok := baselineSpecs[k]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, spec)
+		case spec.Status == history.FailedStatus && base.Status == history.FailedStatus:
+			d.StillFailing = append(d.StillFailing, spec)
+		case spec.Status == history.FailedStatus:
+			d.NewlyFailing = append(d.NewlyFailing, spec)
+		case spec.Status == history.PassedStatus && base.Status == history.FailedStatus:
+			d.NewlyPassing = append(d.NewlyPassing, spec)
+		}
+	}
+	for _, k := range baselineOrder {
+		if _, ok := candidateSpecs[k]; !ok {
+			d.Removed = append(d.Removed, baselineSpecs[k])
+		}
+	}
+	return d
+}
@@ -0,0 +1,108 @@
+package junitdiff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bsm/ginkgo/v2/reporters"
+)
+
+func suites(testCases ...reporters.JUnitTestCase) *reporters.JUnitTestSuites {
+	return &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{{Name: "e2e", TestCases: testCases}}}
+}
+
+func passed(name string) reporters.JUnitTestCase {
+	return reporters.JUnitTestCase{Name: name, Status: "passed"}
+}
+
+func failed(name, message string) reporters.JUnitTestCase {
+	return reporters.JUnitTestCase{Name: name, Status: "failed", Failure: &reporters.JUnitFailure{Message: message}}
+}
+
+func skipped(name string) reporters.JUnitTestCase {
+	return reporters.JUnitTestCase{Name: name, Status: "skipped", Skipped: &reporters.JUnitSkipped{}}
+}
+
+func names(specs []Spec) string {
+	var n []string
+	for _, s := range specs {
+		n = append(n, s.Name)
+	}
+	return strings.Join(n, ",")
+}
+
+// TestCompare tests sorting specs into the categories of the diff
+func TestCompare(t *testing.T) {
+	baseline := suites(
+		passed("breaks"),
+		failed("gets fixed", "boom"),
+		failed("keeps failing", "boom"),
+		skipped("was skipped"),
+		passed("gets skipped"),
+		passed("unchanged"),
+		passed("gets removed"),
+	)
+	candidate := suites(
+		failed("breaks", "unexpected error"),
+		passed("gets fixed"),
+		failed("keeps failing", "boom again"),
+		failed("was skipped", "boom"),
+		skipped("gets skipped"),
+		passed("unchanged"),
+		failed("gets added", "boom"),
+	)
+
+	d := Compare(baseline, candidate)
+	for _, tc := range []struct {
+		category string
+		specs    []Spec
+		want     string
+	}{
+		{"newly failing", d.NewlyFailing, "breaks,was skipped"},
+		{"newly passing", d.NewlyPassing, "gets fixed"},
+		{"still failing", d.StillFailing, "keeps failing"},
+		{"added", d.Added, "gets added"},
+		{"removed", d.Removed, "gets removed"},
+	} {
+		if got := names(tc.specs); got != tc.want {
+			t.Errorf("expected %s specs %q, got %q", tc.category, tc.want, got)
+		}
+	}
+	if msg := d.NewlyFailing[0].Message; msg != "unexpected error" {
+		t.Errorf("expected the failure message from the candidate run, got %q", msg)
+	}
+	if got, want := d.Summary(), "2 newly failing, 1 newly passing, 1 still failing, 1 added, 1 removed"; got != want {
+		t.Errorf("expected summary %q, got %q", want, got)
+	}
+
+	d.Baseline = "periodic job 1234"
+	md := d.Markdown()
+	for _, s := range []string{"compared with periodic job 1234", "<summary>:x: Newly failing (2)</summary>", "- [**`failed`**] breaks"} {
+		if !strings.Contains(md, s) {
+			t.Errorf("expected %q in the Markdown output:\n%s", s, md)
+		}
+	}
+}
+
+// TestCompareEmpty tests comparing identical and missing reports
+func TestCompareEmpty(t *testing.T) {
+	report := suites(passed("a"), skipped("b"))
+	d := Compare(report, report)
+	if !d.Empty() {
+		t.Errorf("expected no differences between identical reports, got %+v", d)
+	}
+	if !strings.Contains(d.Text(), "No differences found.") {
+		t.Errorf("expected no differences in the text output, got:\n%s", d.Text())
+	}
+	out, err := d.JSON()
+	if err != nil {
+		t.Fatalf("failed to format diff as JSON: %v", err)
+	}
+	if strings.Contains(string(out), "null") {
+		t.Errorf("expected empty lists instead of nulls in the JSON output, got:\n%s", out)
+	}
+
+	if d := Compare(nil, report); names(d.Added) != "a,b" {
+		t.Errorf("expected all specs to be added when there's no baseline report, got %+v", d)
+	}
+}
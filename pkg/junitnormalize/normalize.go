@@ -0,0 +1,330 @@
+package junitnormalize
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+// Status is the normalized status of a test case
+type Status string
+
+// Normalized statuses of test cases
+const (
+	PassedStatus Status = "passed"
+	FailedStatus Status = "failed"
+	// ErrorStatus is the status of test cases that couldn't be completed because of an unexpected error
+	// (e.g. a panic, an exception in a fixture, an interruption), as opposed to a failed assertion
+	ErrorStatus Status = "error"
+	// SkippedStatus is the status of test cases skipped at runtime (e.g. t.Skip(), pytest.skip(), Skip())
+	SkippedStatus Status = "skipped"
+	// DisabledStatus is the status of test cases that were not run at all because they are disabled
+	// (e.g. Ginkgo's pending specs, JUnit's @Disabled/@Ignore)
+	DisabledStatus Status = "disabled"
+)
+
+// Report is a JUnit report normalized from any of the supported producers
+type Report struct {
+	// Producer is the detected producer of the JUnit report - the producer of its first suite
+	// with a known producer, if the report was merged from reports of different producers
+	Producer Producer
+	Suites   []Suite
+}
+
+// Suite is a normalized test suite
+type Suite struct {
+	// Producer is the detected producer of the suite
+	Producer Producer
+	Name     string
+	Package  string
+	// Timestamp is the start time of the suite as reported by the producer (ISO 8601)
+	Timestamp string
+	// ReportedTime is the duration of the suite in seconds as reported by the producer (0 if not reported) -
+	// it may include the time spent outside the test cases, e.g. in the suite setup and teardown
+	ReportedTime float64
+	Properties   []Property
+	TestCases    []TestCase
+
+	// rootIndex is the index of the suite within the root element, or -1 for nested suites
+	rootIndex int
+	// ginkgo is the suite as decoded from the report, if it was reported by Ginkgo - it's returned by Report.Ginkgo as it is
+	ginkgo *reporters.JUnitTestSuite
+}
+
+// Property is a key-value property of a test suite
+type Property struct {
+	Name  string
+	Value string
+}
+
+// TestCase is a normalized test case
+type TestCase struct {
+	Name      string
+	Classname string
+	// Time is the duration of the test case in seconds
+	Time   float64
+	Status Status
+	// ProducerStatus is the status reported by the producer in the "status" attribute, if any (e.g. Ginkgo's "timedout")
+	ProducerStatus string
+	// Message is the failure, error or skip message
+	Message string
+	// Type is the type of the failure or the error (e.g. the exception class)
+	Type string
+	// Details are the details of the failure or the error (e.g. the stack trace)
+	Details   string
+	SystemOut string
+	SystemErr string
+}
+
+// Counts returns the number of test cases by their status
+func (s Suite) Counts() map[Status]int {
+	counts := map[Status]int{}
+	for _, tc := range s.TestCases {
+		counts[tc.Status]++
+	}
+	return counts
+}
+
+// Time returns the duration of the suite in seconds - the reported one, or the sum of the durations
+// of its test cases if the producer didn't report it
+func (s Suite) Time() float64 {
+	if s.ReportedTime > 0 {
+		return s.ReportedTime
+	}
+	var total float64
+	for _, tc := range s.TestCases {
+		total += tc.Time
+	}
+	return total
+}
+
+// junitTestSuites and the related types describe the superset of JUnit elements and attributes written
+// by the supported producers, so that no information is lost before the normalization
+type junitTestSuites struct {
+	Name       string           `xml:"name,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Package    string          `xml:"package,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	TestCases  []junitTestCase `xml:"testcase"`
+	// TestSuites are nested suites (e.g. written by some Jest and Tekton reporters)
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+	// Text is the value of properties written as an element content
+	Text string `xml:",chardata"`
+}
+
+type junitTestCase struct {
+	Name      string       `xml:"name,attr"`
+	Classname string       `xml:"classname,attr"`
+	Time      string       `xml:"time,attr"`
+	Status    string       `xml:"status,attr"`
+	Failure   *junitResult `xml:"failure"`
+	Error     *junitResult `xml:"error"`
+	Skipped   *junitResult `xml:"skipped"`
+	SystemOut string       `xml:"system-out"`
+	SystemErr string       `xml:"system-err"`
+}
+
+type junitResult struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// DecodeFile reads and normalizes the JUnit file
+func DecodeFile(path string) (*Report, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open JUnit file: %+v", err)
+	}
+	defer f.Close()
+
+	report, err := Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode JUnit file %q: %+v", path, err)
+	}
+	return report, nil
+}
+
+// Decode normalizes JUnit XML with either <testsuites> or <testsuite> root element. The producer of the
+// report is detected, so that its conventions (e.g. the meaning of the "status" attribute) are respected
+func Decode(r io.Reader) (*Report, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	report, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	keepGinkgoSuites(report, data)
+	return report, nil
+}
+
+func decode(data []byte) (*Report, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("no JUnit root element found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		suites := junitTestSuites{}
+		switch start.Name.Local {
+		case "testsuites":
+			if err := decoder.DecodeElement(&suites, &start); err != nil {
+				return nil, err
+			}
+		case "testsuite":
+			suite := junitTestSuite{}
+			if err := decoder.DecodeElement(&suite, &start); err != nil {
+				return nil, err
+			}
+			suites.TestSuites = []junitTestSuite{suite}
+		default:
+			return nil, fmt.Errorf("unexpected JUnit root element <%s>", start.Name.Local)
+		}
+		return normalize(suites), nil
+	}
+}
+
+func normalize(suites junitTestSuites) *Report {
+	report := &Report{Producer: Unknown}
+	// nested suites without their own conventions inherit the producer of the parent suite
+	var add func(s junitTestSuite, parent Producer, rootIndex int)
+	add = func(s junitTestSuite, parent Producer, rootIndex int) {
+		producer := detectProducer(suites.Name, s)
+		if producer == Unknown {
+			producer = parent
+		}
+		if report.Producer == Unknown {
+			report.Producer = producer
+		}
+		if len(s.TestCases) > 0 || len(s.TestSuites) == 0 {
+			suite := producer.normalizeSuite(s)
+			suite.rootIndex = rootIndex
+			report.Suites = append(report.Suites, suite)
+		}
+		for _, nested := range s.TestSuites {
+			add(nested, producer, -1)
+		}
+	}
+	for i, s := range suites.TestSuites {
+		add(s, Unknown, i)
+	}
+	return report
+}
+
+// keepGinkgoSuites keeps the suites reported by Ginkgo as they are decoded by Ginkgo's own JUnit types,
+// so that Report.Ginkgo returns them without any loss (e.g. of the suite setup time or the owners of specs)
+func keepGinkgoSuites(report *Report, data []byte) {
+	var rootSuites []reporters.JUnitTestSuite
+	for i, s := range report.Suites {
+		if s.Producer != Ginkgo || s.rootIndex < 0 {
+			continue
+		}
+		if rootSuites == nil {
+			suites := reporters.JUnitTestSuites{}
+			if err := xml.Unmarshal(data, &suites); err != nil {
+				// the root element is <testsuite>
+				suite := reporters.JUnitTestSuite{}
+				if err := xml.Unmarshal(data, &suite); err != nil {
+					return
+				}
+				suites.TestSuites = []reporters.JUnitTestSuite{suite}
+			}
+			rootSuites = suites.TestSuites
+		}
+		if s.rootIndex < len(rootSuites) {
+			report.Suites[i].ginkgo = &rootSuites[s.rootIndex]
+		}
+	}
+}
+
+func (p Producer) normalizeSuite(s junitTestSuite) Suite {
+	suite := Suite{Producer: p, Name: s.Name, Package: s.Package, Timestamp: s.Timestamp, ReportedTime: parseTime(s.Time)}
+	for _, prop := range s.Properties {
+		value := prop.Value
+		if value == "" {
+			value = strings.TrimSpace(prop.Text)
+		}
+		suite.Properties = append(suite.Properties, Property{Name: prop.Name, Value: value})
+	}
+	for _, tc := range s.TestCases {
+		suite.TestCases = append(suite.TestCases, p.normalizeTestCase(tc))
+	}
+	return suite
+}
+
+func (p Producer) normalizeTestCase(tc junitTestCase) TestCase {
+	normalized := TestCase{
+		Name:           tc.Name,
+		Classname:      tc.Classname,
+		Time:           parseTime(tc.Time),
+		ProducerStatus: tc.Status,
+		SystemOut:      tc.SystemOut,
+		SystemErr:      tc.SystemErr,
+	}
+	// the result elements take precedence over the status attribute, which not every producer writes
+	var result *junitResult
+	switch {
+	case tc.Error != nil:
+		normalized.Status, result = ErrorStatus, tc.Error
+	case tc.Failure != nil:
+		normalized.Status, result = FailedStatus, tc.Failure
+	case tc.Skipped != nil:
+		normalized.Status, result = p.skippedStatus(tc.Status, tc.Skipped), tc.Skipped
+	default:
+		normalized.Status = p.status(tc.Status)
+	}
+	if result != nil {
+		normalized.Message = strings.TrimSpace(result.Message)
+		normalized.Type = result.Type
+		normalized.Details = strings.TrimSpace(result.Text)
+		// e.g. Jest writes the failure only as the element content
+		if normalized.Message == "" {
+			normalized.Message, _, _ = strings.Cut(normalized.Details, "\n")
+		}
+	}
+	// Ginkgo writes interrupted specs with the <error> element and aborted specs with the <failure> element,
+	// but counts both as errors
+	if p == Ginkgo && (tc.Status == "aborted" || tc.Status == "interrupted") {
+		normalized.Status = ErrorStatus
+	}
+	return normalized
+}
+
+// parseTime parses the duration in seconds - Maven Surefire formats durations above 1000s with
+// a thousands separator (e.g. "1,234.5")
+func parseTime(s string) float64 {
+	t, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s), ",", ""), 64)
+	if err != nil {
+		return 0
+	}
+	return t
+}
@@ -0,0 +1,297 @@
+package junitnormalize
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+const gotestsumJUnit = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1" errors="0" time="1.5">
+	<testsuite tests="3" failures="1" time="1.5" name="github.com/org/repo/pkg" timestamp="2024-05-01T10:00:00Z">
+		<properties>
+			<property name="go.version" value="go1.21.5 linux/amd64"></property>
+		</properties>
+		<testcase classname="github.com/org/repo/pkg" name="TestPass" time="0.5"></testcase>
+		<testcase classname="github.com/org/repo/pkg" name="TestFail" time="1.0">
+			<failure message="Failed" type="">=== RUN   TestFail
+    pkg_test.go:12: expected 1, got 2
+--- FAIL: TestFail (1.00s)</failure>
+		</testcase>
+		<testcase classname="github.com/org/repo/pkg" name="TestSkip" time="0.0">
+			<skipped message="pkg_test.go:20: requires a cluster"></skipped>
+		</testcase>
+	</testsuite>
+</testsuites>`
+
+const pytestJUnit = `<?xml version="1.0" encoding="utf-8"?>
+<testsuites name="pytest tests">
+	<testsuite name="pytest" errors="1" failures="0" skipped="2" tests="4" time="2.1" timestamp="2024-05-01T10:00:00.123456" hostname="runner">
+		<testcase classname="tests.test_api" name="test_get" file="tests/test_api.py" line="3" time="0.1" />
+		<testcase classname="tests.test_api" name="test_post" file="tests/test_api.py" line="9" time="2.0">
+			<error message="failed on setup with &quot;ConnectionError: refused&quot;">fixture 'client' failed</error>
+		</testcase>
+		<testcase classname="tests.test_api" name="test_put" time="0.0">
+			<skipped type="pytest.skip" message="not implemented">tests/test_api.py:15: not implemented</skipped>
+		</testcase>
+		<testcase classname="tests.test_api" name="test_delete" time="0.0">
+			<skipped type="pytest.xfail" message="known bug" />
+		</testcase>
+	</testsuite>
+</testsuites>`
+
+const jestJUnit = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="jest tests" tests="2" failures="1" errors="0" time="0.8">
+	<testsuite name="app.test.js" errors="0" failures="1" skipped="0" timestamp="2024-05-01T10:00:00" time="0.8" tests="2">
+		<testcase classname="App renders" name="App renders" time="0.3"></testcase>
+		<testcase classname="App handles clicks" name="App handles clicks" time="0.5">
+			<failure>Error: expect(received).toBe(expected)
+    at Object.&lt;anonymous&gt; (app.test.js:10:5)</failure>
+		</testcase>
+	</testsuite>
+</testsuites>`
+
+const surefireJUnit = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:noNamespaceSchemaLocation="https://maven.apache.org/surefire/maven-surefire-plugin/xsd/surefire-test-report-3.0.xsd" version="3.0" name="com.example.AppTest" time="1,234.5" tests="3" errors="0" skipped="1" failures="1">
+	<properties>
+		<property name="java.version" value="17.0.9"/>
+		<property name="surefire.test.class.path" value="/target/test-classes"/>
+	</properties>
+	<testcase name="testSlow" classname="com.example.AppTest" time="1,234.5"/>
+	<testcase name="testFail" classname="com.example.AppTest" time="0">
+		<failure message="expected: &lt;1&gt; but was: &lt;2&gt;" type="org.opentest4j.AssertionFailedError">org.opentest4j.AssertionFailedError: expected: &lt;1&gt; but was: &lt;2&gt;</failure>
+		<system-out>some output</system-out>
+	</testcase>
+	<testcase name="testIgnored" classname="com.example.AppTest" time="0">
+		<skipped message="public void com.example.AppTest.testIgnored() is @Disabled"/>
+	</testcase>
+</testsuite>`
+
+const tektonJUnit = `<testsuites>
+	<testsuite name="e2e-pipeline-run-x7k2" timestamp="2024-05-01T10:00:00Z">
+		<properties>
+			<property name="tekton.dev/pipeline" value="e2e-pipeline"/>
+		</properties>
+		<testcase name="clone" classname="e2e-pipeline" time="12" status="Succeeded"/>
+		<testcase name="build" classname="e2e-pipeline" time="600" status="TaskRunTimeout"/>
+		<testcase name="deploy" classname="e2e-pipeline" time="1" status="TaskRunImagePullFailed"/>
+		<testcase name="test" classname="e2e-pipeline" time="0" status="TaskRunCancelled"/>
+	</testsuite>
+</testsuites>`
+
+const ginkgoJUnit = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="4" disabled="2" errors="1" failures="0" time="5.5">
+	<testsuite name="E2E Suite" package="/tests" tests="4" disabled="1" skipped="1" errors="1" failures="0" time="5.5" timestamp="2024-05-01T10:00:00">
+		<properties>
+			<property name="SuiteSucceeded" value="false"></property>
+		</properties>
+		<testcase name="[It] creates an app" classname="E2E Suite" status="passed" time="1" owner="team-a"></testcase>
+		<testcase name="[It] deletes an app" classname="E2E Suite" status="pending" time="0">
+			<skipped message="pending"></skipped>
+		</testcase>
+		<testcase name="[It] updates an app" classname="E2E Suite" status="skipped" time="0">
+			<skipped message="skipped - not supported"></skipped>
+		</testcase>
+		<testcase name="[It] scales an app" classname="E2E Suite" status="interrupted" time="2">
+			<error message="interrupted by timeout" type="interrupted"></error>
+		</testcase>
+	</testsuite>
+</testsuites>`
+
+// statuses returns "name=status" of all test cases of the report
+func statuses(r *Report) string {
+	var s []string
+	for _, suite := range r.Suites {
+		for _, tc := range suite.TestCases {
+			s = append(s, tc.Name+"="+string(tc.Status))
+		}
+	}
+	return strings.Join(s, " ")
+}
+
+// TestDecode tests normalizing JUnit reports of the supported producers
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name         string
+		junit        string
+		wantProducer Producer
+		wantStatuses string
+	}{
+		{"gotestsum", gotestsumJUnit, GoTest, "TestPass=passed TestFail=failed TestSkip=skipped"},
+		{"pytest", pytestJUnit, Pytest, "test_get=passed test_post=error test_put=skipped test_delete=skipped"},
+		{"jest", jestJUnit, Jest, "App renders=passed App handles clicks=failed"},
+		{"surefire", surefireJUnit, Surefire, "testSlow=passed testFail=failed testIgnored=disabled"},
+		{"tekton", tektonJUnit, Tekton, "clone=passed build=failed deploy=error test=error"},
+		{"ginkgo", ginkgoJUnit, Ginkgo, "[It] creates an app=passed [It] deletes an app=disabled [It] updates an app=skipped [It] scales an app=error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Decode(strings.NewReader(tt.junit))
+			if err != nil {
+				t.Fatalf("failed to decode JUnit: %v", err)
+			}
+			if report.Producer != tt.wantProducer {
+				t.Errorf("expected producer %q, got %q", tt.wantProducer, report.Producer)
+			}
+			if got := statuses(report); got != tt.wantStatuses {
+				t.Errorf("expected statuses %q, got %q", tt.wantStatuses, got)
+			}
+		})
+	}
+}
+
+// TestDecodeMixedProducers tests normalizing a report merged from reports of different producers
+func TestDecodeMixedProducers(t *testing.T) {
+	suite := func(junit string) string {
+		start := strings.Index(junit, "<testsuite ")
+		end := strings.LastIndex(junit, "</testsuite>") + len("</testsuite>")
+		return junit[start:end]
+	}
+	merged := "<testsuites>" + suite(pytestJUnit) + suite(ginkgoJUnit) + suite(tektonJUnit) + "</testsuites>"
+
+	report, err := Decode(strings.NewReader(merged))
+	if err != nil {
+		t.Fatalf("failed to decode JUnit: %v", err)
+	}
+	if report.Producer != Pytest {
+		t.Errorf("expected the producer of the first suite, got %q", report.Producer)
+	}
+	var producers []string
+	for _, s := range report.Suites {
+		producers = append(producers, string(s.Producer))
+	}
+	if got, want := strings.Join(producers, " "), "pytest ginkgo tekton"; got != want {
+		t.Errorf("expected producers %q, got %q", want, got)
+	}
+	if got, want := statuses(report), "test_get=passed test_post=error test_put=skipped test_delete=skipped "+
+		"[It] creates an app=passed [It] deletes an app=disabled [It] updates an app=skipped [It] scales an app=error "+
+		"clone=passed build=failed deploy=error test=error"; got != want {
+		t.Errorf("expected statuses %q, got %q", want, got)
+	}
+
+	// states of Ginkgo specs are kept only in the suites reported by Ginkgo
+	suites := report.Ginkgo()
+	if tc := suites.TestSuites[1].TestCases[3]; tc.Status != "interrupted" {
+		t.Errorf("expected the state of the Ginkgo spec to be kept, got %+v", tc)
+	}
+	if tc := suites.TestSuites[2].TestCases[1]; tc.Status != "failed" {
+		t.Errorf("expected the status of the TaskRun to be normalized, got %+v", tc)
+	}
+}
+
+// TestDecodeDetails tests normalizing messages, durations and the output of test cases
+func TestDecodeDetails(t *testing.T) {
+	jest, _ := Decode(strings.NewReader(jestJUnit))
+	if failed := jest.Suites[0].TestCases[1]; failed.Message != "Error: expect(received).toBe(expected)" || !strings.Contains(failed.Details, "app.test.js:10:5") {
+		t.Errorf("expected the message to be the first line of the failure, got %+v", failed)
+	}
+
+	surefire, _ := Decode(strings.NewReader(surefireJUnit))
+	if slow := surefire.Suites[0].TestCases[0]; slow.Time != 1234.5 {
+		t.Errorf("expected the duration with a thousands separator to be parsed, got %v", slow.Time)
+	}
+	if failed := surefire.Suites[0].TestCases[1]; failed.Type != "org.opentest4j.AssertionFailedError" || failed.SystemOut != "some output" {
+		t.Errorf("unexpected failed test case %+v", failed)
+	}
+	if len(surefire.Suites[0].Properties) != 2 {
+		t.Errorf("expected the properties to be kept, got %+v", surefire.Suites[0].Properties)
+	}
+
+	if _, err := Decode(strings.NewReader("<html></html>")); err == nil {
+		t.Errorf("expected an error for an unexpected root element")
+	}
+}
+
+// TestGinkgo tests writing the Ginkgo-flavoured variant of the report
+func TestGinkgo(t *testing.T) {
+	pytest, _ := Decode(strings.NewReader(pytestJUnit))
+	suites := pytest.Ginkgo()
+	if suites.Tests != 4 || suites.Errors != 1 || suites.Disabled != 2 {
+		t.Errorf("unexpected counts of suites %+v", suites)
+	}
+	suite := suites.TestSuites[0]
+	if suite.Skipped != 2 || suite.Disabled != 0 || suite.Time != 2.1 {
+		t.Errorf("unexpected counts of suite %+v", suite)
+	}
+	if tc := suite.TestCases[1]; tc.Status != "panicked" || tc.Error == nil || !strings.Contains(tc.Error.Message, "ConnectionError") {
+		t.Errorf("expected the error to be reported as panicked, got %+v", tc)
+	}
+	if tc := suite.TestCases[2]; tc.Status != "skipped" || tc.Skipped.Message != "skipped - not implemented" {
+		t.Errorf("unexpected skipped test case %+v", tc)
+	}
+
+	surefire, _ := Decode(strings.NewReader(surefireJUnit))
+	if tc := surefire.Ginkgo().TestSuites[0].TestCases[2]; tc.Status != "pending" || !strings.HasPrefix(tc.Skipped.Message, "pending - ") {
+		t.Errorf("expected the disabled test case to be reported as pending, got %+v", tc)
+	}
+
+	// states of Ginkgo specs and the messages are kept as they are
+	ginkgo, _ := Decode(strings.NewReader(ginkgoJUnit))
+	suite = ginkgo.Ginkgo().TestSuites[0]
+	var got []string
+	for _, tc := range suite.TestCases {
+		message := ""
+		if tc.Skipped != nil {
+			message = tc.Skipped.Message
+		}
+		got = append(got, fmt.Sprintf("%s(%s)", tc.Status, message))
+	}
+	if want := "passed() pending(pending) skipped(skipped - not supported) interrupted()"; strings.Join(got, " ") != want {
+		t.Errorf("expected test cases %q, got %q", want, strings.Join(got, " "))
+	}
+	if suite.Disabled != 1 || suite.Skipped != 1 || suite.Errors != 1 {
+		t.Errorf("unexpected counts of suite %+v", suite)
+	}
+}
+
+// TestGinkgoRoundTrip tests that suites reported by Ginkgo are returned as they were reported
+func TestGinkgoRoundTrip(t *testing.T) {
+	want := reporters.JUnitTestSuites{}
+	if err := xml.Unmarshal([]byte(ginkgoJUnit), &want); err != nil {
+		t.Fatalf("failed to unmarshal JUnit: %v", err)
+	}
+	report, err := Decode(strings.NewReader(ginkgoJUnit))
+	if err != nil {
+		t.Fatalf("failed to decode JUnit: %v", err)
+	}
+	got := report.Ginkgo()
+	got.XMLName = want.XMLName
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("expected the Ginkgo report to be kept as it is\nwant: %+v\ngot:  %+v", want, *got)
+	}
+	// the duration of the suite includes the time spent outside the specs (e.g. in BeforeSuite)
+	if report.Suites[0].Time() != 5.5 {
+		t.Errorf("expected the reported duration of the suite, got %v", report.Suites[0].Time())
+	}
+}
+
+// TestReportPortal tests writing the Report Portal variant of the report
+func TestReportPortal(t *testing.T) {
+	ginkgo, _ := Decode(strings.NewReader(ginkgoJUnit))
+	suites := ginkgo.ReportPortal()
+	if suites.Tests != 4 || suites.Skipped != 2 || suites.Errors != 1 || suites.TestSuites[0].Skipped != 2 {
+		t.Errorf("expected disabled test cases to be counted as skipped, got %+v", suites)
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeXML(&buf, suites); err != nil {
+		t.Fatalf("failed to encode report: %v", err)
+	}
+	if strings.Contains(buf.String(), "status=") || strings.Contains(buf.String(), "disabled=") {
+		t.Errorf("expected no Ginkgo-specific attributes, got:\n%s", buf.String())
+	}
+
+	// the written variant can be normalized again
+	again, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("failed to decode the written report: %v", err)
+	}
+	if got, want := statuses(again), "[It] creates an app=passed [It] deletes an app=disabled [It] updates an app=skipped [It] scales an app=error"; got != want {
+		t.Errorf("expected statuses %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,99 @@
+package junitnormalize
+
+import (
+	"slices"
+	"strings"
+)
+
+// Producer is the tool that produced the JUnit report
+type Producer string
+
+// Supported producers of JUnit reports
+const (
+	Ginkgo Producer = "ginkgo"
+	// GoTest is "go test" output converted by gotestsum or go-junit-report
+	GoTest   Producer = "go-test"
+	Pytest   Producer = "pytest"
+	Jest     Producer = "jest"
+	Surefire Producer = "surefire"
+	// Tekton are reports of PipelineRuns, where test cases are TaskRuns with the status attribute set to
+	// the reason of their "Succeeded" condition (e.g. "Succeeded", "Failed", "TaskRunCancelled", "TaskRunTimeout")
+	Tekton Producer = "tekton"
+	// Unknown producers are normalized by the common JUnit conventions only
+	Unknown Producer = "unknown"
+)
+
+// ginkgoProperties are properties of every suite reported by Ginkgo
+var ginkgoProperties = []string{"SuiteSucceeded", "RandomSeed", "SpecialSuiteFailureReason"}
+
+// detectProducer detects the producer of the suite by the conventions of known producers - the name of
+// the root element, the suite properties and the types of results. Suites are detected one by one, as
+// reports merged from several files (e.g. by "prowjob create-report") may contain suites of different producers
+func detectProducer(rootName string, s junitTestSuite) Producer {
+	for _, p := range s.Properties {
+		switch {
+		case slices.Contains(ginkgoProperties, p.Name):
+			return Ginkgo
+		case p.Name == "go.version":
+			return GoTest
+		case strings.HasPrefix(p.Name, "surefire.") || strings.HasPrefix(p.Name, "maven."):
+			return Surefire
+		case strings.HasPrefix(p.Name, "tekton.dev/"):
+			return Tekton
+		}
+	}
+	if s.Name == "pytest" {
+		return Pytest
+	}
+	for _, tc := range s.TestCases {
+		switch {
+		case tc.Skipped != nil && strings.HasPrefix(tc.Skipped.Type, "pytest."):
+			return Pytest
+		case tc.Status != "" && strings.HasPrefix(tc.Name, "["):
+			// Ginkgo prefixes the spec names with the node type, e.g. "[It]"
+			return Ginkgo
+		}
+	}
+	switch {
+	case strings.HasPrefix(rootName, "jest"):
+		return Jest
+	case strings.HasPrefix(rootName, "pytest"):
+		return Pytest
+	}
+	return Unknown
+}
+
+// status normalizes the status attribute of a test case without any result element
+func (p Producer) status(attr string) Status {
+	switch s := strings.ToLower(attr); {
+	case s == "" || s == "passed" || s == "pass" || s == "success" || s == "succeeded" || s == "completed" || s == "ok":
+		return PassedStatus
+	case s == "skipped" || s == "skip":
+		return SkippedStatus
+	case s == "pending" || s == "disabled" || s == "ignored" || s == "notrun" || s == "not run":
+		return DisabledStatus
+	case s == "error" || s == "panicked" || s == "interrupted" || s == "aborted" || strings.Contains(s, "cancel"):
+		return ErrorStatus
+	case p == Tekton && s != "failed" && !strings.HasSuffix(s, "timeout"):
+		// reasons of TaskRuns failed before their steps were executed, e.g. "TaskRunImagePullFailed"
+		return ErrorStatus
+	}
+	return FailedStatus
+}
+
+// skippedStatus distinguishes test cases skipped at runtime from disabled ones, which
+// producers usually report by the same <skipped> element
+func (p Producer) skippedStatus(attr string, skipped *junitResult) Status {
+	if status := p.status(attr); attr != "" && (status == SkippedStatus || status == DisabledStatus) {
+		return status
+	}
+	message := strings.ToLower(skipped.Message)
+	switch {
+	case strings.HasPrefix(message, "pending") || strings.HasPrefix(message, "disabled"):
+		return DisabledStatus
+	// JUnit 4 and 5 annotations reported by Surefire
+	case p == Surefire && (strings.Contains(message, "@disabled") || strings.Contains(message, "@ignore")):
+		return DisabledStatus
+	}
+	return SkippedStatus
+}
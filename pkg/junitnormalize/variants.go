@@ -0,0 +1,165 @@
+package junitnormalize
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/konflux-ci/qe-tools/pkg/customjunit"
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+	ginkgoTypes "github.com/onsi/ginkgo/v2/types"
+)
+
+// Ginkgo returns the report in the flavour written by Ginkgo, which is expected by "prowjob create-report"
+// and the other commands: every test case has the "status" attribute, disabled test cases are reported
+// as pending and test cases with errors as panicked (Ginkgo's only state of unexpected errors). Suites
+// reported by Ginkgo itself are returned as they were decoded
+func (r *Report) Ginkgo() *reporters.JUnitTestSuites {
+	suites := &reporters.JUnitTestSuites{}
+	for _, s := range r.Suites {
+		suite := s.ginkgoSuite()
+		suites.TestSuites = append(suites.TestSuites, suite)
+		suites.Tests += suite.Tests
+		suites.Disabled += suite.Disabled + suite.Skipped
+		suites.Errors += suite.Errors
+		suites.Failures += suite.Failures
+		suites.Time += suite.Time
+	}
+	return suites
+}
+
+func (s Suite) ginkgoSuite() reporters.JUnitTestSuite {
+	if s.ginkgo != nil {
+		return *s.ginkgo
+	}
+	counts := s.Counts()
+	suite := reporters.JUnitTestSuite{
+		Name:      s.Name,
+		Package:   s.Package,
+		Timestamp: s.Timestamp,
+		Tests:     len(s.TestCases),
+		Disabled:  counts[DisabledStatus],
+		Skipped:   counts[SkippedStatus],
+		Errors:    counts[ErrorStatus],
+		Failures:  counts[FailedStatus],
+		Time:      s.Time(),
+	}
+	for _, p := range s.Properties {
+		suite.Properties.Properties = append(suite.Properties.Properties, reporters.JUnitProperty{Name: p.Name, Value: p.Value})
+	}
+	for _, tc := range s.TestCases {
+		suite.TestCases = append(suite.TestCases, s.Producer.ginkgoTestCase(tc))
+	}
+	return suite
+}
+
+func (p Producer) ginkgoTestCase(tc TestCase) reporters.JUnitTestCase {
+	testCase := reporters.JUnitTestCase{
+		Name:      tc.Name,
+		Classname: tc.Classname,
+		Time:      tc.Time,
+		SystemOut: tc.SystemOut,
+		SystemErr: tc.SystemErr,
+	}
+	switch tc.Status {
+	case PassedStatus:
+		testCase.Status = ginkgoTypes.SpecStatePassed.String()
+	case FailedStatus:
+		testCase.Status = ginkgoTypes.SpecStateFailed.String()
+		testCase.Failure = &reporters.JUnitFailure{Message: tc.Message, Type: resultType(tc.Type, testCase.Status), Description: tc.Details}
+	case ErrorStatus:
+		testCase.Status = ginkgoTypes.SpecStatePanicked.String()
+		testCase.Error = &reporters.JUnitError{Message: tc.Message, Type: resultType(tc.Type, testCase.Status), Description: tc.Details}
+	case SkippedStatus:
+		testCase.Status = ginkgoTypes.SpecStateSkipped.String()
+		testCase.Skipped = &reporters.JUnitSkipped{Message: skipMessage(testCase.Status, tc.Message)}
+	case DisabledStatus:
+		testCase.Status = ginkgoTypes.SpecStatePending.String()
+		testCase.Skipped = &reporters.JUnitSkipped{Message: skipMessage(testCase.Status, tc.Message)}
+	}
+	// states of Ginkgo specs (e.g. "timedout", "interrupted") are kept
+	if p == Ginkgo && tc.ProducerStatus != "" {
+		testCase.Status = tc.ProducerStatus
+	}
+	return testCase
+}
+
+// ReportPortal returns the report in the flavour expected by Report Portal's JUnit import: test cases
+// don't have the "status" attribute and disabled test cases are counted (and reported) as skipped
+func (r *Report) ReportPortal() *customjunit.TestSuites {
+	suites := &customjunit.TestSuites{}
+	for _, s := range r.Suites {
+		counts := s.Counts()
+		suite := customjunit.TestSuite{
+			Name:      s.Name,
+			Package:   s.Package,
+			Timestamp: s.Timestamp,
+			Tests:     len(s.TestCases),
+			Skipped:   counts[SkippedStatus] + counts[DisabledStatus],
+			Errors:    counts[ErrorStatus],
+			Failures:  counts[FailedStatus],
+			Time:      s.Time(),
+		}
+		for _, p := range s.Properties {
+			suite.Properties.Properties = append(suite.Properties.Properties, reporters.JUnitProperty{Name: p.Name, Value: p.Value})
+		}
+		for _, tc := range s.TestCases {
+			testCase := customjunit.TestCase{
+				Name:      tc.Name,
+				Classname: tc.Classname,
+				Time:      tc.Time,
+				SystemOut: tc.SystemOut,
+				SystemErr: tc.SystemErr,
+			}
+			switch tc.Status {
+			case FailedStatus:
+				testCase.Failure = &reporters.JUnitFailure{Message: tc.Message, Type: resultType(tc.Type, "failed"), Description: tc.Details}
+			case ErrorStatus:
+				testCase.Error = &reporters.JUnitError{Message: tc.Message, Type: resultType(tc.Type, "error"), Description: tc.Details}
+			case SkippedStatus, DisabledStatus:
+				testCase.Skipped = &reporters.JUnitSkipped{Message: skipMessage(string(tc.Status), tc.Message)}
+			}
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+		suites.TestSuites = append(suites.TestSuites, suite)
+		suites.Tests += suite.Tests
+		suites.Skipped += suite.Skipped
+		suites.Errors += suite.Errors
+		suites.Failures += suite.Failures
+		suites.Time += suite.Time
+	}
+	return suites
+}
+
+// EncodeXML writes the variant of the report (e.g. returned by Ginkgo or ReportPortal) as indented XML
+func EncodeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("cannot encode JUnit report: %+v", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func resultType(t, fallback string) string {
+	if t == "" {
+		return fallback
+	}
+	return t
+}
+
+// skipMessage returns the message of the <skipped> element in the format used by Ginkgo, e.g. "skipped - reason"
+func skipMessage(prefix, message string) string {
+	if message == "" {
+		return prefix
+	}
+	if strings.HasPrefix(message, prefix) {
+		return message
+	}
+	return prefix + " - " + message
+}
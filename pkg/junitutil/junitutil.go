@@ -0,0 +1,76 @@
+// Package junitutil contains helpers for JUnit reports shared by the commands and the packages. Both JUnit
+// types used within the repository are supported - the ones of onsi/ginkgo (used by "prowjob" commands) and
+// the ones of bsm/ginkgo (used by "analyze-test-results" and "results" commands)
+package junitutil
+
+import (
+	"strings"
+
+	bsmreporters "github.com/bsm/ginkgo/v2/reporters"
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+// TestSuites are the supported JUnit test suites
+type TestSuites interface {
+	reporters.JUnitTestSuites | bsmreporters.JUnitTestSuites
+}
+
+// TestCase is the supported JUnit test case
+type TestCase interface {
+	reporters.JUnitTestCase | bsmreporters.JUnitTestCase
+}
+
+// MergeSuites appends test suites from 'src' to 'dst' and updates the overall counts
+func MergeSuites[T TestSuites](dst, src *T) {
+	switch dst := any(dst).(type) {
+	case *reporters.JUnitTestSuites:
+		src := any(src).(*reporters.JUnitTestSuites)
+		dst.TestSuites = append(dst.TestSuites, src.TestSuites...)
+		dst.Tests += src.Tests
+		dst.Disabled += src.Disabled
+		dst.Errors += src.Errors
+		dst.Failures += src.Failures
+		dst.Time += src.Time
+	case *bsmreporters.JUnitTestSuites:
+		src := any(src).(*bsmreporters.JUnitTestSuites)
+		dst.TestSuites = append(dst.TestSuites, src.TestSuites...)
+		dst.Tests += src.Tests
+		dst.Disabled += src.Disabled
+		dst.Errors += src.Errors
+		dst.Failures += src.Failures
+		dst.Time += src.Time
+	}
+}
+
+// FailureMessage returns the message of the test case's failure (or error), or an empty string if it didn't fail
+func FailureMessage[T TestCase](tc T) string {
+	message, _ := failure(tc)
+	return message
+}
+
+// FailureDetails returns the message and the description (e.g. the location and the stack trace)
+// of the test case's failure (or error), or an empty string if it didn't fail
+func FailureDetails[T TestCase](tc T) string {
+	message, description := failure(tc)
+	return strings.TrimSpace(message + "\n" + description)
+}
+
+func failure[T TestCase](tc T) (message, description string) {
+	switch tc := any(tc).(type) {
+	case reporters.JUnitTestCase:
+		switch {
+		case tc.Failure != nil:
+			return tc.Failure.Message, tc.Failure.Description
+		case tc.Error != nil:
+			return tc.Error.Message, tc.Error.Description
+		}
+	case bsmreporters.JUnitTestCase:
+		switch {
+		case tc.Failure != nil:
+			return tc.Failure.Message, tc.Failure.Description
+		case tc.Error != nil:
+			return tc.Error.Message, tc.Error.Description
+		}
+	}
+	return "", ""
+}
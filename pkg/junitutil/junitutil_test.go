@@ -0,0 +1,42 @@
+package junitutil
+
+import (
+	"testing"
+
+	bsmreporters "github.com/bsm/ginkgo/v2/reporters"
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+// TestMergeSuites tests merging test suites of both supported JUnit types
+func TestMergeSuites(t *testing.T) {
+	dst := &reporters.JUnitTestSuites{Tests: 1, TestSuites: []reporters.JUnitTestSuite{{Name: "a"}}}
+	MergeSuites(dst, &reporters.JUnitTestSuites{Tests: 2, Failures: 1, Time: 1.5, TestSuites: []reporters.JUnitTestSuite{{Name: "b"}}})
+	if len(dst.TestSuites) != 2 || dst.Tests != 3 || dst.Failures != 1 || dst.Time != 1.5 {
+		t.Errorf("unexpected merged suites %+v", dst)
+	}
+
+	bsmDst := &bsmreporters.JUnitTestSuites{}
+	MergeSuites(bsmDst, &bsmreporters.JUnitTestSuites{Tests: 2, Disabled: 1, TestSuites: []bsmreporters.JUnitTestSuite{{Name: "b"}}})
+	if len(bsmDst.TestSuites) != 1 || bsmDst.Tests != 2 || bsmDst.Disabled != 1 {
+		t.Errorf("unexpected merged suites %+v", bsmDst)
+	}
+}
+
+// TestFailure tests getting the failure (or the error) of test cases of both supported JUnit types
+func TestFailure(t *testing.T) {
+	failed := reporters.JUnitTestCase{Failure: &reporters.JUnitFailure{Message: "expected 1", Description: "at test.go:10\n"}}
+	if got := FailureMessage(failed); got != "expected 1" {
+		t.Errorf("unexpected failure message %q", got)
+	}
+	if got := FailureDetails(failed); got != "expected 1\nat test.go:10" {
+		t.Errorf("unexpected failure details %q", got)
+	}
+
+	panicked := bsmreporters.JUnitTestCase{Error: &bsmreporters.JUnitError{Message: "panic", Description: "goroutine 1"}}
+	if got := FailureDetails(panicked); got != "panic\ngoroutine 1" {
+		t.Errorf("unexpected error details %q", got)
+	}
+	if got := FailureDetails(bsmreporters.JUnitTestCase{}); got != "" {
+		t.Errorf("expected no failure of a passed test case, got %q", got)
+	}
+}
@@ -0,0 +1,151 @@
+package knownissues
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Signature describes failures caused by a known issue. All defined fields have to match
+type Signature struct {
+	// TestName is a regular expression matched against the name of the failed test case (or openshift-ci step)
+	TestName string `json:"testName,omitempty"`
+	// Pattern is a regular expression and Substring is a plain string matched against the failure message (or log)
+	Pattern   string `json:"pattern,omitempty"`
+	Substring string `json:"substring,omitempty"`
+
+	testNameRegexp *regexp.Regexp
+	patternRegexp  *regexp.Regexp
+}
+
+// Issue represents a known issue tracked in an issue tracker
+type Issue struct {
+	Key        string      `json:"key"`
+	URL        string      `json:"url,omitempty"`
+	Status     string      `json:"status,omitempty"`
+	Summary    string      `json:"summary,omitempty"`
+	Signatures []Signature `json:"signatures"`
+}
+
+// String returns the issue in a human-readable form, e.g. "KFLUXBUGS-123 (Open) https://..."
+func (i Issue) String() string {
+	s := i.Key
+	if i.Status != "" {
+		s += " (" + i.Status + ")"
+	}
+	if i.URL != "" {
+		s += " " + i.URL
+	}
+	return s
+}
+
+// Markdown returns the issue as a Markdown link with its status
+func (i Issue) Markdown() string {
+	s := i.Key
+	if i.URL != "" {
+		s = fmt.Sprintf("[%s](%s)", i.Key, i.URL)
+	}
+	if i.Status != "" {
+		s += " (" + i.Status + ")"
+	}
+	return s
+}
+
+// Store holds the known issues, e.g. loaded from a YAML/JSON file:
+//
+//	issues:
+//	  - key: KFLUXBUGS-123
+//	    url: https://issues.redhat.com/browse/KFLUXBUGS-123
+//	    status: Open
+//	    signatures:
+//	      - testName: "build-service"
+//	        substring: "failed to create PipelineRun"
+type Store struct {
+	Issues []Issue `json:"issues"`
+}
+
+// Load reads the known issues from the given YAML or JSON file
+func Load(path string) (*Store, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known issues from %s: %+v", path, err)
+	}
+	store := &Store{}
+	if err := yaml.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse known issues from %s: %+v", path, err)
+	}
+	if err := store.compile(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// NewStore creates a Store with the given known issues
+func NewStore(issues []Issue) (*Store, error) {
+	store := &Store{Issues: issues}
+	if err := store.compile(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *Store) compile() error {
+	for i := range s.Issues {
+		issue := &s.Issues[i]
+		if issue.Key == "" {
+			return fmt.Errorf("key of the known issue #%d is not defined", i+1)
+		}
+		for j := range issue.Signatures {
+			sig := &issue.Signatures[j]
+			if sig.TestName == "" && sig.Pattern == "" && sig.Substring == "" {
+				return fmt.Errorf("signature #%d of the known issue %s is empty", j+1, issue.Key)
+			}
+			var err error
+			if sig.TestName != "" {
+				if sig.testNameRegexp, err = regexp.Compile(sig.TestName); err != nil {
+					return fmt.Errorf("invalid test name pattern %q of the known issue %s: %+v", sig.TestName, issue.Key, err)
+				}
+			}
+			if sig.Pattern != "" {
+				if sig.patternRegexp, err = regexp.Compile(sig.Pattern); err != nil {
+					return fmt.Errorf("invalid pattern %q of the known issue %s: %+v", sig.Pattern, issue.Key, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Match returns the known issues with a signature matching the failed test case with the given name and failure message
+func (s *Store) Match(testName, failureMessage string) []Issue {
+	if s == nil {
+		return nil
+	}
+	var matched []Issue
+	for _, issue := range s.Issues {
+		for _, sig := range issue.Signatures {
+			if sig.matches(testName, failureMessage) {
+				matched = append(matched, issue)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+func (sig Signature) matches(testName, failureMessage string) bool {
+	if sig.testNameRegexp != nil && !sig.testNameRegexp.MatchString(testName) {
+		return false
+	}
+	if sig.patternRegexp != nil && !sig.patternRegexp.MatchString(failureMessage) {
+		return false
+	}
+	if sig.Substring != "" && !strings.Contains(failureMessage, sig.Substring) {
+		return false
+	}
+	return true
+}
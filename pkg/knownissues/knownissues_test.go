@@ -0,0 +1,79 @@
+package knownissues
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadAndMatch tests loading known issues from a file and matching them against failures
+func TestLoadAndMatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "known-issues.yaml")
+	content := `issues:
+  - key: BUG-1
+    url: https://issues.example.com/browse/BUG-1
+    status: Open
+    signatures:
+      - testName: "build-service"
+        substring: "failed to create PipelineRun"
+  - key: BUG-2
+    status: Closed
+    signatures:
+      - pattern: "quota (exceeded|limit)"
+      - testName: "^ipi-install"
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write known issues file: %v", err)
+	}
+	store, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load known issues: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		testName     string
+		message      string
+		expectedKeys []string
+	}{
+		{name: "All fields of the signature match", testName: "[build-service] creates a build", message: "error: failed to create PipelineRun", expectedKeys: []string{"BUG-1"}},
+		{name: "Test name doesn't match", testName: "[integration] runs tests", message: "failed to create PipelineRun"},
+		{name: "Any signature of the issue matches", testName: "ipi-install-install", message: "cluster failed", expectedKeys: []string{"BUG-2"}},
+		{name: "Multiple issues match", testName: "[build-service] build", message: "failed to create PipelineRun: quota exceeded", expectedKeys: []string{"BUG-1", "BUG-2"}},
+		{name: "No issue matches", testName: "e2e", message: "unexpected error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := store.Match(tt.testName, tt.message)
+			if len(issues) != len(tt.expectedKeys) {
+				t.Fatalf("expected issues %v, got %+v", tt.expectedKeys, issues)
+			}
+			for i, key := range tt.expectedKeys {
+				if issues[i].Key != key {
+					t.Errorf("expected issue %s, got %s", key, issues[i].Key)
+				}
+			}
+		})
+	}
+
+	if got := store.Issues[0].String(); got != "BUG-1 (Open) https://issues.example.com/browse/BUG-1" {
+		t.Errorf("unexpected string representation of the issue: %q", got)
+	}
+	var nilStore *Store
+	if issues := nilStore.Match("test", "message"); issues != nil {
+		t.Errorf("expected no issues from nil store, got %+v", issues)
+	}
+}
+
+// TestNewStoreValidation tests validation of known issues
+func TestNewStoreValidation(t *testing.T) {
+	if _, err := NewStore([]Issue{{Signatures: []Signature{{Substring: "x"}}}}); err == nil {
+		t.Errorf("expected an error for issue without key")
+	}
+	if _, err := NewStore([]Issue{{Key: "BUG-1", Signatures: []Signature{{}}}}); err == nil {
+		t.Errorf("expected an error for empty signature")
+	}
+	if _, err := NewStore([]Issue{{Key: "BUG-1", Signatures: []Signature{{Pattern: "("}}}}); err == nil {
+		t.Errorf("expected an error for invalid pattern")
+	}
+}
@@ -0,0 +1,128 @@
+package logexcerpt
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/konflux-ci/qe-tools/pkg/utils"
+)
+
+const (
+	// DefaultContextLines is the default number of lines kept before and after every error marker
+	DefaultContextLines = 10
+	// DefaultMaxBytes is the default size of the excerpt. It keeps even a few excerpts within the limit of a GitHub comment (65536 characters)
+	DefaultMaxBytes = 10000
+
+	separator       = "[...]"
+	truncatedSuffix = "[... truncated]"
+)
+
+// DefaultMarkers match lines with errors - panics, failed Ginkgo specs, errors and non-zero exit codes
+var DefaultMarkers = []string{
+	`\bpanic:`,
+	`\[FAIL\]`,
+	`\b[Ee]rror:`,
+	`level=(error|fatal)`,
+	`exit (code|status):? [1-9][0-9]*`,
+	`exited with (code|status) [1-9][0-9]*`,
+	`non-zero exit`,
+}
+
+// Options configures the extraction of an excerpt
+type Options struct {
+	// ContextLines is the number of lines kept before and after every line with an error marker
+	ContextLines int
+	// MaxBytes is the maximum size of the excerpt, no limit if <= 0
+	MaxBytes int
+	// Markers are regular expressions matching lines with errors, DefaultMarkers are used if empty
+	Markers []string
+}
+
+// DefaultOptions returns the options with default context lines, byte budget and markers
+func DefaultOptions() Options {
+	return Options{ContextLines: DefaultContextLines, MaxBytes: DefaultMaxBytes}
+}
+
+// Excerpt is the part of the log relevant to the failure
+type Excerpt struct {
+	Text string
+	// Matches is the number of lines with an error marker
+	Matches int
+	// Partial is true if any part of the log was left out of the excerpt
+	Partial bool
+}
+
+// Extract returns the excerpt of the log without ANSI escape sequences. Only lines with error markers
+// and their context are kept - separated by "[...]" - or the end of the log if there's no error marker.
+// If the excerpt exceeds the byte budget, its beginning (with the first errors) is kept
+func Extract(log string, opts Options) (Excerpt, error) {
+	markers := opts.Markers
+	if len(markers) == 0 {
+		markers = DefaultMarkers
+	}
+	markerRegexp, err := regexp.Compile("(" + strings.Join(markers, ")|(") + ")")
+	if err != nil {
+		return Excerpt{}, err
+	}
+	contextLines := opts.ContextLines
+	if contextLines < 0 {
+		contextLines = 0
+	}
+
+	lines := strings.Split(strings.TrimRight(utils.RemoveANSIEscapeSequences(log), "\n"), "\n")
+	keep := make([]bool, len(lines))
+	excerpt := Excerpt{}
+	for i, line := range lines {
+		if !markerRegexp.MatchString(line) {
+			continue
+		}
+		excerpt.Matches++
+		for j := max(0, i-contextLines); j <= min(len(lines)-1, i+contextLines); j++ {
+			keep[j] = true
+		}
+	}
+	if excerpt.Matches == 0 {
+		// The end of the log is usually the most relevant without any error marker
+		for j := max(0, len(lines)-2*contextLines-1); j < len(lines); j++ {
+			keep[j] = true
+		}
+	}
+
+	var kept []string
+	for i, line := range lines {
+		switch {
+		case keep[i]:
+			kept = append(kept, line)
+		case i == 0 || keep[i-1]:
+			kept = append(kept, separator)
+			excerpt.Partial = true
+		}
+	}
+	excerpt.Text = strings.Join(kept, "\n")
+
+	if opts.MaxBytes > 0 && len(excerpt.Text) > opts.MaxBytes {
+		excerpt.Text = strings.TrimSuffix(truncate(excerpt.Text, opts.MaxBytes-len(truncatedSuffix)-1), "\n"+separator) + "\n" + truncatedSuffix
+		excerpt.Partial = true
+	}
+	return excerpt, nil
+}
+
+// truncate cuts the text to at most maxBytes, preferably at the end of a line
+func truncate(text string, maxBytes int) string {
+	if maxBytes <= 0 {
+		return ""
+	}
+	if len(text) <= maxBytes {
+		return text
+	}
+	// Don't split a multi-byte character
+	for maxBytes > 0 && !utf8.RuneStart(text[maxBytes]) {
+		maxBytes--
+	}
+	text = text[:maxBytes]
+	if i := strings.LastIndex(text, "\n"); i > 0 {
+		text = text[:i]
+	}
+	return text
+}
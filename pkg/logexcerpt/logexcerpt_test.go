@@ -0,0 +1,95 @@
+package logexcerpt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func numberedLines(from, to int) []string {
+	var lines []string
+	for i := from; i <= to; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	return lines
+}
+
+// TestExtract tests keeping context around the error markers and falling back to the end of the log
+func TestExtract(t *testing.T) {
+	log := strings.Join(numberedLines(1, 9), "\n") + "\n\x1b[31m[FAIL] creates a build\x1b[0m\n" +
+		strings.Join(numberedLines(11, 29), "\n") + "\npanic: runtime error\n" + strings.Join(numberedLines(31, 40), "\n") + "\n"
+
+	tests := []struct {
+		name            string
+		log             string
+		opts            Options
+		expectedText    string
+		expectedMatches int
+		expectedPartial bool
+	}{
+		{
+			name:            "Context around multiple markers",
+			log:             log,
+			opts:            Options{ContextLines: 1},
+			expectedText:    "[...]\nline 9\n[FAIL] creates a build\nline 11\n[...]\nline 29\npanic: runtime error\nline 31\n[...]",
+			expectedMatches: 2,
+			expectedPartial: true,
+		},
+		{
+			name:            "Overlapping context is merged",
+			log:             "a\nError: first\nb\nexit code 2\nc\nd",
+			opts:            Options{ContextLines: 1},
+			expectedText:    "a\nError: first\nb\nexit code 2\nc\n[...]",
+			expectedMatches: 2,
+			expectedPartial: true,
+		},
+		{
+			name:            "End of the log without any marker",
+			log:             strings.Join(numberedLines(1, 10), "\n"),
+			opts:            Options{ContextLines: 1},
+			expectedText:    "[...]\nline 8\nline 9\nline 10",
+			expectedPartial: true,
+		},
+		{
+			name:         "Whole short log",
+			log:          "exit code 0\ndone\n",
+			opts:         DefaultOptions(),
+			expectedText: "exit code 0\ndone",
+		},
+		{
+			name:            "Byte budget keeps the first errors",
+			log:             log,
+			opts:            Options{ContextLines: 1, MaxBytes: 70},
+			expectedText:    "[...]\nline 9\n[FAIL] creates a build\nline 11\n[... truncated]",
+			expectedMatches: 2,
+			expectedPartial: true,
+		},
+		{
+			name:            "Custom markers",
+			log:             "a\nb\nBOOM\nc",
+			opts:            Options{Markers: []string{"BOOM"}},
+			expectedText:    "[...]\nBOOM\n[...]",
+			expectedMatches: 1,
+			expectedPartial: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Extract(tt.log, tt.opts)
+			if err != nil {
+				t.Fatalf("failed to extract excerpt: %v", err)
+			}
+			if got.Text != tt.expectedText || got.Matches != tt.expectedMatches || got.Partial != tt.expectedPartial {
+				t.Errorf("expected %q (matches: %d, partial: %t), got %q (matches: %d, partial: %t)",
+					tt.expectedText, tt.expectedMatches, tt.expectedPartial, got.Text, got.Matches, got.Partial)
+			}
+			if tt.opts.MaxBytes > 0 && len(got.Text) > tt.opts.MaxBytes {
+				t.Errorf("excerpt exceeds the budget of %d bytes: %d", tt.opts.MaxBytes, len(got.Text))
+			}
+		})
+	}
+
+	if _, err := Extract("log", Options{Markers: []string{"("}}); err == nil {
+		t.Errorf("expected an error for invalid marker")
+	}
+}
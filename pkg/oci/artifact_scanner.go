@@ -22,16 +22,32 @@ func NewArtifactScanner(cfg ScannerConfig) (*ArtifactScanner, error) {
 }
 
 // Run method processes pulls the OCI artifact and stores required files (their path and content)
-// in the map (ArtifactsFilesPathMap).
+// in the map (ArtifactsFilesPathMap). If the LocalDir or Tarball is configured, the files are
+// scanned from the local directory or the extracted tarball instead.
 func (as *ArtifactScanner) Run() error {
-	artifactDirPath, err := os.MkdirTemp("", "artifact-content")
-	if err != nil {
-		return fmt.Errorf("failed to create temporary director for pulling OCI artifact to: %+v", err)
-	}
-	as.artifactDirPath = artifactDirPath
+	if as.config.LocalDir != "" {
+		info, err := os.Stat(as.config.LocalDir)
+		if err != nil {
+			return fmt.Errorf("failed to access directory %s: %+v", as.config.LocalDir, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", as.config.LocalDir)
+		}
+		as.artifactDirPath = as.config.LocalDir
+	} else {
+		artifactDirPath, err := os.MkdirTemp("", "artifact-content")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary director for pulling OCI artifact to: %+v", err)
+		}
+		as.artifactDirPath = artifactDirPath
 
-	if err := as.pullAndExtractOciArtifact(); err != nil {
-		return fmt.Errorf("failed to pull OCI artifact: %+v", err)
+		if as.config.Tarball != "" {
+			if err := extractTarball(as.config.Tarball, as.artifactDirPath); err != nil {
+				return fmt.Errorf("failed to extract tarball: %+v", err)
+			}
+		} else if err := as.pullAndExtractOciArtifact(); err != nil {
+			return fmt.Errorf("failed to pull OCI artifact: %+v", err)
+		}
 	}
 
 	if err := as.processExtractedFiles(); err != nil {
@@ -66,8 +82,15 @@ func (as *ArtifactScanner) processExtractedFiles() error {
 		if err != nil {
 			return fmt.Errorf("failed to visit file in path %s: %+v", filePath, err)
 		}
-		if as.isRequiredFile(filePath) {
-			if err := as.initArtifactsFilesPathMap(info.Name(), filePath); err != nil {
+		if info.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(as.artifactDirPath, filePath)
+		if err != nil {
+			return err
+		}
+		if as.isRequiredFile(FilePath(filepath.ToSlash(relPath))) {
+			if err := as.initArtifactsFilesPathMap(info.Name(), filePath, FilePath(filepath.ToSlash(relPath))); err != nil {
 				return err
 			}
 		}
@@ -79,7 +102,7 @@ func (as *ArtifactScanner) processExtractedFiles() error {
 
 // initArtifactsFilesPathMap is  function to initialise/update the ArtifactsFilesPathMap with content
 // of a file with given file path and file name
-func (as *ArtifactScanner) initArtifactsFilesPathMap(fileName, filePath string) error {
+func (as *ArtifactScanner) initArtifactsFilesPathMap(fileName, filePath string, relPath FilePath) error {
 	file, err := os.Open(filepath.Clean(filePath))
 	if err != nil {
 		return err
@@ -90,17 +113,16 @@ func (as *ArtifactScanner) initArtifactsFilesPathMap(fileName, filePath string)
 	}
 
 	artifact := Artifact{Content: string(fileData), Filename: fileName}
-
-	as.FilesPathMap[FilePath(filePath)] = artifact
+	as.FilesPathMap[relPath] = artifact
 
 	return nil
 }
 
-// isRequiredFile is a helper function to check if a file with given 'ArtifactFullPath',
+// isRequiredFile is a helper function to check if a file with given path (relative to the artifact's root directory)
 // matches the file-name filter(s) defined within ScannerConfig struct
-func (as *ArtifactScanner) isRequiredFile(filePath string) bool {
+func (as *ArtifactScanner) isRequiredFile(filePath FilePath) bool {
 	return slices.ContainsFunc(as.config.FileNameFilter, func(s string) bool {
 		re := regexp.MustCompile(s)
-		return re.MatchString(filePath)
+		return re.MatchString(string(filePath))
 	})
 }
@@ -0,0 +1,112 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var scannedContent = map[string]string{
+	"e2e-test/artifacts/e2e-report.xml": "<testsuites></testsuites>",
+	"e2e-test/e2e-tests.log":            "test log",
+	"e2e-test/other.txt":                "other",
+}
+
+func assertScannedFiles(t *testing.T, fpm FilesPathMap) {
+	t.Helper()
+	if len(fpm) != 2 {
+		t.Fatalf("expected 2 files to be scanned, got %+v", fpm)
+	}
+	for path, artifact := range fpm {
+		expected, ok := scannedContent[string(path)]
+		if !ok || artifact.Content != expected || artifact.Filename != filepath.Base(string(path)) {
+			t.Errorf("unexpected artifact %+v scanned from %s", artifact, path)
+		}
+	}
+}
+
+// TestRunLocalDir tests scanning files from a local directory
+func TestRunLocalDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range scannedContent {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scanner, _ := NewArtifactScanner(ScannerConfig{LocalDir: dir, FileNameFilter: []string{"e2e-report.xml", "e2e-tests.log"}})
+	if err := scanner.Run(); err != nil {
+		t.Fatalf("failed to scan local directory: %v", err)
+	}
+	assertScannedFiles(t, scanner.FilesPathMap)
+
+	// filters are matched against the path relative to the directory, so they can be anchored
+	scanner, _ = NewArtifactScanner(ScannerConfig{LocalDir: dir, FileNameFilter: []string{`^e2e-test/artifacts/[^/]*\.xml$`, `^e2e-test/e2e-tests\.log$`}})
+	if err := scanner.Run(); err != nil {
+		t.Fatalf("failed to scan local directory: %v", err)
+	}
+	assertScannedFiles(t, scanner.FilesPathMap)
+
+	scanner, _ = NewArtifactScanner(ScannerConfig{LocalDir: filepath.Join(dir, "missing")})
+	if err := scanner.Run(); err == nil {
+		t.Errorf("expected an error for a missing directory")
+	}
+}
+
+// TestRunTarball tests scanning files from compressed and uncompressed tarballs
+func TestRunTarball(t *testing.T) {
+	dir := t.TempDir()
+
+	// Compressed tarball without entries for the parent directories
+	tarGz := filepath.Join(dir, "artifacts.tar.gz")
+	createTarGzFile(t, tarGz, scannedContent)
+
+	// Uncompressed tarball
+	var buf bytes.Buffer
+	tarWriter := tar.NewWriter(&buf)
+	for name, content := range scannedContent {
+		if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	plainTar := filepath.Join(dir, "artifacts.tar")
+	if err := os.WriteFile(plainTar, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tarball := range []string{tarGz, plainTar} {
+		t.Run(filepath.Base(tarball), func(t *testing.T) {
+			scanner, _ := NewArtifactScanner(ScannerConfig{Tarball: tarball, FileNameFilter: []string{"e2e-report.xml", "e2e-tests.log"}})
+			if err := scanner.Run(); err != nil {
+				t.Fatalf("failed to scan tarball: %v", err)
+			}
+			assertScannedFiles(t, scanner.FilesPathMap)
+		})
+	}
+}
+
+// TestExtractTarballOutsideOfDestination tests rejecting tar entries pointing outside of the destination directory
+func TestExtractTarballOutsideOfDestination(t *testing.T) {
+	dir := t.TempDir()
+	tarball := filepath.Join(dir, "malicious.tar.gz")
+	createTarGzFile(t, tarball, map[string]string{"../outside.txt": "content"})
+
+	if err := extractTarball(tarball, filepath.Join(dir, "dest")); err == nil {
+		t.Errorf("expected an error for an entry outside of the destination directory")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "outside.txt")); err == nil {
+		t.Errorf("expected the file outside of the destination directory not to be created")
+	}
+}
@@ -0,0 +1,82 @@
+package oci
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// extractTarball extracts a tarball (optionally gzip-compressed) with test artifacts to the given directory.
+// Unlike OCI artifact blobs, tarballs created by other CI systems don't always contain entries
+// for parent directories, so these are created as needed
+func extractTarball(tarballPath, dest string) error {
+	file, err := os.Open(filepath.Clean(tarballPath))
+	if err != nil {
+		return fmt.Errorf("failed to open tarball %s: %w", tarballPath, err)
+	}
+	defer file.Close()
+
+	br := bufio.NewReader(file)
+	var r io.Reader = br
+	if header, err := br.Peek(2); err == nil && header[0] == 0x1F && header[1] == 0x8B {
+		gzReader, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer gzReader.Close()
+		r = gzReader
+	}
+
+	tarReader := tar.NewReader(r)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar header: %w", err)
+		}
+
+		destPath := filepath.Join(dest, header.Name) // #nosec G305 - the path is checked below
+		if destPath != filepath.Clean(dest) && !strings.HasPrefix(destPath, filepath.Clean(dest)+string(os.PathSeparator)) {
+			return fmt.Errorf("tar entry %q points outside of the destination directory", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(destPath, 0o750); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(destPath), 0o750); err != nil {
+				return err
+			}
+			if err := writeFileFromTar(tarReader, destPath); err != nil {
+				return err
+			}
+		default:
+			klog.Infof("skipping unsupported tar entry %s (type %c)", header.Name, header.Typeflag)
+		}
+	}
+}
+
+func writeFileFromTar(tarReader *tar.Reader, destPath string) error {
+	outFile, err := os.Create(filepath.Clean(destPath))
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", destPath, err)
+	}
+	defer outFile.Close()
+
+	// #nosec G110
+	if _, err := io.Copy(outFile, tarReader); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", destPath, err)
+	}
+	return nil
+}
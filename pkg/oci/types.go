@@ -9,16 +9,24 @@ type ArtifactScanner struct {
 }
 
 // ScannerConfig contains fields required
-// for scanning files with ArtifactScanner
+// for scanning files with ArtifactScanner.
+// Exactly one of OciArtifactReference, LocalDir and Tarball is expected to be set
 type ScannerConfig struct {
 	OciArtifactReference string
-	FileNameFilter       []string
+	// LocalDir is the path to the directory with the test artifacts (e.g. downloaded from another CI system)
+	LocalDir string
+	// Tarball is the path to the tarball (optionally gzip-compressed) with the test artifacts
+	Tarball string
+	// FileNameFilter are regular expressions selecting the files to scan - they're matched against the slash-separated
+	// path of the file relative to the root directory of the artifact (see FilePath)
+	FileNameFilter []string
 }
 
 // FilesPathMap - e.g. "e2e-test/e2e-report.xml": {Content: "<file-content>", Filename: "e2e-report.xml"}
 type FilesPathMap map[FilePath]Artifact
 
-// FilePath represents the full path of the file from the root directory of the extracted OCI artifact
+// FilePath represents the slash-separated path of the file relative to the root directory of the extracted OCI artifact
+// (or of the scanned local directory or extracted tarball)
 type FilePath string
 
 // Artifact stores the file name of the artifact and the content of the file
@@ -0,0 +1,143 @@
+package prcomment
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+)
+
+const (
+	// MaxCommentLength is the maximum length of a GitHub comment
+	MaxCommentLength = 65536
+	// DefaultMaxPreviousAnalyses is the default number of previous analyses kept (collapsed) in the comment
+	DefaultMaxPreviousAnalyses = 5
+
+	previousAnalysesMarker = "<!-- qe-tools:previous-analyses -->"
+	previousAnalysisMarker = "<!-- qe-tools:previous-analysis -->"
+	truncatedNote          = "\n\n_(truncated)_"
+)
+
+// Commenter maintains a single comment on a GitHub PR identified by a hidden marker. Instead of
+// creating a new comment every time, the previous comment is edited - its content is replaced with
+// the latest analysis and the older analyses are collapsed at the end of the comment
+type Commenter struct {
+	Client *github.Client
+	Owner  string
+	Repo   string
+	Number int
+	// ID distinguishes comments of different tools (or pipelines) on the same PR
+	ID string
+	// MaxPreviousAnalyses is the number of previous analyses kept in the comment
+	MaxPreviousAnalyses int
+}
+
+// NewCommenter creates a Commenter of the PR with the given number using the given GitHub client (see utils.NewGithubClient)
+func NewCommenter(client *github.Client, owner, repo string, number int, id string) *Commenter {
+	return &Commenter{
+		Client:              client,
+		Owner:               owner,
+		Repo:                repo,
+		Number:              number,
+		ID:                  id,
+		MaxPreviousAnalyses: DefaultMaxPreviousAnalyses,
+	}
+}
+
+// marker returns the hidden marker identifying the comment
+func (c *Commenter) marker() string {
+	return fmt.Sprintf("<!-- qe-tools:%s -->", c.ID)
+}
+
+// Post creates the comment with the given analysis, or edits the existing comment with the same marker
+func (c *Commenter) Post(ctx context.Context, analysis string) (*github.IssueComment, error) {
+	existing, err := c.findComment(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing == nil {
+		comment, _, err := c.Client.Issues.CreateComment(ctx, c.Owner, c.Repo, c.Number, &github.IssueComment{Body: github.String(c.body(analysis, nil))})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create comment on PR %s/%s#%d: %+v", c.Owner, c.Repo, c.Number, err)
+		}
+		return comment, nil
+	}
+
+	current, previous := c.parse(existing.GetBody())
+	if current != "" {
+		updated := existing.GetUpdatedAt().Time
+		if updated.IsZero() {
+			updated = existing.GetCreatedAt().Time
+		}
+		previous = append([]string{fmt.Sprintf("_Analysis from %s_\n\n%s", updated.UTC().Format(time.RFC1123), current)}, previous...)
+	}
+	comment, _, err := c.Client.Issues.EditComment(ctx, c.Owner, c.Repo, existing.GetID(), &github.IssueComment{Body: github.String(c.body(analysis, previous))})
+	if err != nil {
+		return nil, fmt.Errorf("failed to edit comment %d on PR %s/%s#%d: %+v", existing.GetID(), c.Owner, c.Repo, c.Number, err)
+	}
+	return comment, nil
+}
+
+// findComment returns the latest comment on the PR with the marker, or nil if there's none
+func (c *Commenter) findComment(ctx context.Context) (*github.IssueComment, error) {
+	var found *github.IssueComment
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := c.Client.Issues.ListComments(ctx, c.Owner, c.Repo, c.Number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list comments of PR %s/%s#%d: %+v", c.Owner, c.Repo, c.Number, err)
+		}
+		for _, comment := range comments {
+			if strings.HasPrefix(comment.GetBody(), c.marker()) {
+				found = comment
+			}
+		}
+		if resp.NextPage == 0 {
+			return found, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// parse splits the body of the existing comment into the latest analysis and the previous ones
+func (c *Commenter) parse(body string) (current string, previous []string) {
+	body = strings.TrimPrefix(body, c.marker())
+	current, collapsed, _ := strings.Cut(body, previousAnalysesMarker)
+	for _, analysis := range strings.Split(collapsed, previousAnalysisMarker)[1:] {
+		analysis = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(analysis), "</details>"))
+		analysis = strings.TrimSpace(strings.TrimSuffix(analysis, "---"))
+		if analysis != "" {
+			previous = append(previous, analysis)
+		}
+	}
+	return strings.TrimSpace(current), previous
+}
+
+// body returns the body of the comment with the analysis and the collapsed previous analyses.
+// The oldest analyses are left out to respect the number of kept analyses and the maximum comment length
+func (c *Commenter) body(analysis string, previous []string) string {
+	if len(previous) > c.MaxPreviousAnalyses {
+		previous = previous[:c.MaxPreviousAnalyses]
+	}
+
+	head := c.marker() + "\n" + analysis
+	if len(head) > MaxCommentLength {
+		head = strings.ToValidUTF8(head[:MaxCommentLength-len(truncatedNote)], "") + truncatedNote
+	}
+	for ; len(previous) > 0; previous = previous[:len(previous)-1] {
+		var b strings.Builder
+		b.WriteString(head)
+		fmt.Fprintf(&b, "\n\n%s\n<details><summary>Previous analyses (%d)</summary>\n", previousAnalysesMarker, len(previous))
+		for _, p := range previous {
+			fmt.Fprintf(&b, "\n%s\n\n%s\n\n---\n", previousAnalysisMarker, p)
+		}
+		b.WriteString("</details>")
+		if b.Len() <= MaxCommentLength {
+			return b.String()
+		}
+	}
+	return head
+}
@@ -0,0 +1,98 @@
+package prcomment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// fakeGitHub is a local stand-in of the GitHub API serving comments of a single PR
+type fakeGitHub struct {
+	comments []*github.IssueComment
+	created  int
+	edited   int
+}
+
+func (g *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/org/repo/issues/1/comments":
+		_ = json.NewEncoder(w).Encode(g.comments)
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/org/repo/issues/1/comments":
+		comment := &github.IssueComment{}
+		_ = json.NewDecoder(r.Body).Decode(comment)
+		comment.ID = github.Int64(int64(len(g.comments) + 1))
+		comment.UpdatedAt = &github.Timestamp{Time: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}
+		g.comments = append(g.comments, comment)
+		g.created++
+		_ = json.NewEncoder(w).Encode(comment)
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/repos/org/repo/issues/comments/"):
+		edit := &github.IssueComment{}
+		_ = json.NewDecoder(r.Body).Decode(edit)
+		for _, comment := range g.comments {
+			if r.URL.Path == fmt.Sprintf("/repos/org/repo/issues/comments/%d", comment.GetID()) {
+				comment.Body = edit.Body
+				g.edited++
+				_ = json.NewEncoder(w).Encode(comment)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// TestPost tests creating the comment and editing it with the next analyses
+func TestPost(t *testing.T) {
+	gh := &fakeGitHub{comments: []*github.IssueComment{{ID: github.Int64(100), Body: github.String("/retest")}}}
+	server := httptest.NewServer(gh)
+	defer server.Close()
+
+	commenter := NewCommenter(github.NewClient(nil), "org", "repo", 1, "analyze-test-results")
+	commenter.MaxPreviousAnalyses = 2
+	commenter.Client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	for i := 1; i <= 4; i++ {
+		if _, err := commenter.Post(context.Background(), fmt.Sprintf("analysis #%d", i)); err != nil {
+			t.Fatalf("failed to post analysis #%d: %v", i, err)
+		}
+	}
+
+	if gh.created != 1 || gh.edited != 3 || len(gh.comments) != 2 {
+		t.Fatalf("expected a single comment to be created and edited 3 times, got %d created and %d edited", gh.created, gh.edited)
+	}
+	body := gh.comments[1].GetBody()
+	if !strings.HasPrefix(body, "<!-- qe-tools:analyze-test-results -->\nanalysis #4") {
+		t.Errorf("expected the latest analysis at the beginning of the comment, got:\n%s", body)
+	}
+	if !strings.Contains(body, "Previous analyses (2)") || !strings.Contains(body, "analysis #3") || !strings.Contains(body, "analysis #2") || strings.Contains(body, "analysis #1") {
+		t.Errorf("expected 2 previous analyses to be collapsed, got:\n%s", body)
+	}
+	if strings.Index(body, "analysis #3") > strings.Index(body, "analysis #2") {
+		t.Errorf("expected the previous analyses ordered from the newest, got:\n%s", body)
+	}
+}
+
+// TestBodyLength tests leaving out previous analyses exceeding the maximum comment length
+func TestBodyLength(t *testing.T) {
+	commenter := &Commenter{ID: "id", MaxPreviousAnalyses: DefaultMaxPreviousAnalyses}
+	previous := []string{strings.Repeat("a", MaxCommentLength/2), strings.Repeat("b", MaxCommentLength/2)}
+
+	body := commenter.body("analysis", previous)
+	if len(body) > MaxCommentLength || !strings.Contains(body, "Previous analyses (1)") {
+		t.Errorf("expected only 1 previous analysis within the limit, got body of length %d", len(body))
+	}
+
+	body = commenter.body(strings.Repeat("c", MaxCommentLength), previous)
+	if len(body) > MaxCommentLength || !strings.HasSuffix(body, truncatedNote) {
+		t.Errorf("expected truncated analysis without previous analyses, got body of length %d", len(body))
+	}
+}
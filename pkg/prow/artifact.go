@@ -0,0 +1,108 @@
+package prow
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Open returns a reader for the full content of the artifact. If the Content was truncated,
+// the copy spooled on disk is used (if available), otherwise the content is lazily read from the ArtifactSource
+func (a Artifact) Open(ctx context.Context) (io.ReadCloser, error) {
+	switch {
+	case !a.Truncated:
+		return io.NopCloser(strings.NewReader(a.Content)), nil
+	case a.spoolPath != "":
+		f, err := os.Open(filepath.Clean(a.spoolPath))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open spooled artifact %s: %+v", a.spoolPath, err)
+		}
+		return f, nil
+	case a.source != nil:
+		return a.source.Open(ctx, a.FullName)
+	default:
+		return nil, fmt.Errorf("full content of the artifact %s is not available", a.FullName)
+	}
+}
+
+// readArtifact reads the given object from the source. At most MaxArtifactSize bytes are kept in memory
+// (the beginning of the file, or the end of it for files matching TailFileNameFilter) and the full content
+// of bigger files is spooled to the SpoolDir (if configured)
+func (as *ArtifactScanner) readArtifact(ctx context.Context, object ObjectAttrs) (Artifact, error) {
+	artifact := Artifact{FullName: object.Name, Size: object.Size, source: as.source}
+
+	rc, err := as.source.Open(ctx, object.Name)
+	if err != nil {
+		return artifact, err
+	}
+	defer rc.Close()
+
+	content := &boundedBuffer{limit: as.config.MaxArtifactSize, tail: as.isTailOnlyFile(object.Name)}
+	var w io.Writer = content
+
+	if as.config.SpoolDir != "" && as.config.MaxArtifactSize > 0 && object.Size > as.config.MaxArtifactSize {
+		spoolPath := filepath.Join(as.config.SpoolDir, filepath.FromSlash(filepath.Clean("/"+object.Name)))
+		if err := os.MkdirAll(filepath.Dir(spoolPath), 0o750); err != nil {
+			return artifact, fmt.Errorf("failed to create spool directory for %s: %+v", object.Name, err)
+		}
+		f, err := os.Create(filepath.Clean(spoolPath))
+		if err != nil {
+			return artifact, fmt.Errorf("failed to create spool file for %s: %+v", object.Name, err)
+		}
+		defer f.Close()
+		w = io.MultiWriter(content, f)
+		artifact.spoolPath = spoolPath
+	}
+
+	if _, err := io.Copy(w, rc); err != nil {
+		return artifact, fmt.Errorf("cannot read from storage reader: %w", err)
+	}
+
+	artifact.Content = string(content.buf)
+	artifact.Size = content.total
+	artifact.Truncated = content.total > int64(len(content.buf))
+
+	return artifact, nil
+}
+
+// isTailOnlyFile checks if a file with given 'fullArtifactName' matches
+// the tail-only file-name filter(s) defined within ScannerConfig struct
+func (as *ArtifactScanner) isTailOnlyFile(fullArtifactName string) bool {
+	return slices.ContainsFunc(as.config.TailFileNameFilter, func(s string) bool {
+		re := regexp.MustCompile(s)
+		return re.MatchString(fullArtifactName)
+	})
+}
+
+// boundedBuffer keeps at most 'limit' bytes written to it (no limit if 'limit' <= 0) -
+// either the first ones, or the last ones if 'tail' is true
+type boundedBuffer struct {
+	limit int64
+	tail  bool
+	buf   []byte
+	total int64
+}
+
+func (b *boundedBuffer) Write(p []byte) (int, error) {
+	b.total += int64(len(p))
+	switch {
+	case b.limit <= 0:
+		b.buf = append(b.buf, p...)
+	case b.tail:
+		b.buf = append(b.buf, p...)
+		if overflow := int64(len(b.buf)) - b.limit; overflow > 0 {
+			b.buf = b.buf[overflow:]
+		}
+	default:
+		if remaining := b.limit - int64(len(b.buf)); remaining > 0 {
+			b.buf = append(b.buf, p[:min(int64(len(p)), remaining)]...)
+		}
+	}
+	return len(p), nil
+}
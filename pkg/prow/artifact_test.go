@@ -0,0 +1,94 @@
+package prow
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+// TestReadArtifact tests reading artifacts bigger than the configured limit
+func TestReadArtifact(t *testing.T) {
+	logContent := strings.Repeat("a", 10) + strings.Repeat("b", 10)
+	root := createLocalBucket(t, map[string]string{
+		"logs/job/1/build-log.txt": logContent,
+		"logs/job/1/small.json":    "{}",
+	})
+	source, err := NewLocalSource(root)
+	if err != nil {
+		t.Fatalf("failed to create local artifact source: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		config          ScannerConfig
+		objectName      string
+		expectedContent string
+		expectTruncated bool
+	}{
+		{
+			name:            "Small file is read fully",
+			config:          ScannerConfig{MaxArtifactSize: 10},
+			objectName:      "logs/job/1/small.json",
+			expectedContent: "{}",
+		},
+		{
+			name:            "Beginning of a big file is kept",
+			config:          ScannerConfig{MaxArtifactSize: 10},
+			objectName:      "logs/job/1/build-log.txt",
+			expectedContent: strings.Repeat("a", 10),
+			expectTruncated: true,
+		},
+		{
+			name:            "End of a big file is kept in tail-only mode",
+			config:          ScannerConfig{MaxArtifactSize: 10, TailFileNameFilter: []string{"build-log.txt"}},
+			objectName:      "logs/job/1/build-log.txt",
+			expectedContent: strings.Repeat("b", 10),
+			expectTruncated: true,
+		},
+		{
+			name:            "Big file is spooled to disk",
+			config:          ScannerConfig{MaxArtifactSize: 5, SpoolDir: t.TempDir()},
+			objectName:      "logs/job/1/build-log.txt",
+			expectedContent: strings.Repeat("a", 5),
+			expectTruncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as := &ArtifactScanner{source: source, config: tt.config}
+			objects, err := source.List(context.Background(), tt.objectName)
+			if err != nil || len(objects) != 1 {
+				t.Fatalf("failed to list object %s: %v", tt.objectName, err)
+			}
+
+			artifact, err := as.readArtifact(context.Background(), objects[0])
+			if err != nil {
+				t.Fatalf("failed to read artifact: %v", err)
+			}
+			if artifact.Content != tt.expectedContent {
+				t.Errorf("expected content %q, got %q", tt.expectedContent, artifact.Content)
+			}
+			if artifact.Truncated != tt.expectTruncated {
+				t.Errorf("expected truncated to be %v, got %v", tt.expectTruncated, artifact.Truncated)
+			}
+			if tt.config.SpoolDir != "" && artifact.spoolPath == "" {
+				t.Error("expected the artifact to be spooled to disk")
+			}
+
+			rc, err := artifact.Open(context.Background())
+			if err != nil {
+				t.Fatalf("failed to open artifact: %v", err)
+			}
+			defer rc.Close()
+			fullContent, err := io.ReadAll(rc)
+			if err != nil {
+				t.Fatalf("failed to read artifact: %v", err)
+			}
+			if int64(len(fullContent)) != artifact.Size {
+				t.Errorf("expected full content of size %d, got %d", artifact.Size, len(fullContent))
+			}
+		})
+	}
+}
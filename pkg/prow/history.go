@@ -0,0 +1,329 @@
+package prow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/testgrid/metadata"
+	"k8s.io/klog/v2"
+)
+
+const (
+	periodicLogsPrefix    = "logs/"
+	presubmitIndexPrefix  = "pr-logs/directory/"
+	startedFilename       = "started.json"
+	finishedFilename      = "finished.json"
+	latestBuildFilename   = "latest-build.txt"
+	presubmitIndexFileExt = ".txt"
+)
+
+// JobResult represents the result of a Prow job run
+type JobResult string
+
+const (
+	// JobResultSuccess is the result of a run that finished and passed
+	JobResultSuccess JobResult = "SUCCESS"
+	// JobResultFailure is the result of a run that finished and didn't pass
+	JobResultFailure JobResult = "FAILURE"
+	// JobResultAborted is the result of a run that was aborted
+	JobResultAborted JobResult = "ABORTED"
+	// JobResultPending is the result of a run that hasn't finished yet
+	JobResultPending JobResult = "PENDING"
+)
+
+// JobRun represents a single run (build) of a Prow job
+type JobRun struct {
+	// Job is the name of the Prow job
+	Job string `json:"job"`
+	// ID is the build ID of the run
+	ID string `json:"id"`
+	// Path is the path of the run's directory within the bucket, e.g. "logs/<job>/<id>"
+	Path string `json:"path"`
+	// URL is the link to the run's job view
+	URL string `json:"url"`
+	// Started is the time the run started at
+	Started time.Time `json:"started"`
+	// Finished is the time the run finished at - nil if the run is still pending
+	Finished *time.Time `json:"finished,omitempty"`
+	// Result of the run
+	Result JobResult `json:"result"`
+	// Revision identifies the code the run tested - the commit from started.json, or
+	// the refs of all checked out repositories if the commit isn't available
+	Revision string `json:"revision,omitempty"`
+}
+
+// Duration returns how long the run took (or has been running for, if it's still pending)
+func (r JobRun) Duration() time.Duration {
+	if r.Finished == nil {
+		return time.Since(r.Started)
+	}
+	return r.Finished.Sub(r.Started)
+}
+
+// JobRunFilter defines which runs are returned by ListJobRuns
+type JobRunFilter struct {
+	// Since and Until define the time window the runs started within - zero values mean no limit
+	Since time.Time
+	Until time.Time
+	// Results limits the runs to the ones with the given results - empty means any result
+	Results []JobResult
+	// Limit is the maximum number of returned runs - 0 means no limit
+	Limit int
+}
+
+func (f JobRunFilter) matchesResult(result JobResult) bool {
+	if len(f.Results) == 0 {
+		return true
+	}
+	for _, r := range f.Results {
+		if strings.EqualFold(string(r), string(result)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListJobRuns returns runs of the Prow job with the given name matching the filter, newest first.
+// Both periodic ("logs/<job>/") and presubmit ("pr-logs/directory/<job>/") jobs are supported
+func ListJobRuns(ctx context.Context, source ArtifactSource, instance Instance, job string, filter JobRunFilter) ([]JobRun, error) {
+	refs, err := listJobRunRefs(ctx, source, job)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []JobRun
+	for _, ref := range refs {
+		if filter.Limit > 0 && len(runs) >= filter.Limit {
+			break
+		}
+		runPath, err := ref.resolve(ctx, source)
+		if err != nil {
+			klog.Warningf("skipping run %s: %+v", ref.index, err)
+			continue
+		}
+		run, err := getJobRun(ctx, source, instance, job, runPath)
+		if err != nil {
+			klog.Warningf("skipping run %s: %+v", runPath, err)
+			continue
+		}
+		if !filter.Since.IsZero() && run.Started.Before(filter.Since) {
+			// Build IDs grow over time, so all the remaining runs are older
+			break
+		}
+		if (!filter.Until.IsZero() && run.Started.After(filter.Until)) || !filter.matchesResult(run.Result) {
+			continue
+		}
+		runs = append(runs, *run)
+	}
+
+	return runs, nil
+}
+
+// LatestJobRun returns the latest run of the Prow job with the given name,
+// based on the "latest-build.txt" file maintained by Prow for every job
+func LatestJobRun(ctx context.Context, source ArtifactSource, instance Instance, job string) (*JobRun, error) {
+	// Periodic (and postsubmit) jobs
+	data, err := readObject(ctx, source, periodicLogsPrefix+job+"/"+latestBuildFilename)
+	if err == nil {
+		return getJobRun(ctx, source, instance, job, periodicLogsPrefix+job+"/"+strings.TrimSpace(string(data)))
+	}
+
+	// Presubmit jobs
+	data, presubmitErr := readObject(ctx, source, presubmitIndexPrefix+job+"/"+latestBuildFilename)
+	if presubmitErr != nil {
+		return nil, fmt.Errorf("failed to determine the latest build of job %s: %+v", job, errors.Join(err, presubmitErr))
+	}
+	runPath, err := readPresubmitIndex(ctx, source, presubmitIndexPrefix+job+"/"+strings.TrimSpace(string(data))+presubmitIndexFileExt)
+	if err != nil {
+		return nil, err
+	}
+	return getJobRun(ctx, source, instance, job, runPath)
+}
+
+// GetJobRun returns details of the run with the given URL (e.g. Prow job view URL) pointing to the run's directory within the bucket
+func GetJobRun(ctx context.Context, source ArtifactSource, instance Instance, jobURL string) (*JobRun, error) {
+	runPath, err := instance.ObjectPathFromURL(jobURL)
+	if err != nil {
+		return nil, err
+	}
+	runPath = strings.Trim(runPath, "/")
+	// => e.g. "logs/<job>/<build-id>" => "<job>"
+	return getJobRun(ctx, source, instance, path.Base(path.Dir(runPath)), runPath)
+}
+
+// getJobRun returns details of the run with the given path, read from its started.json and finished.json files
+func getJobRun(ctx context.Context, source ArtifactSource, instance Instance, job, runPath string) (*JobRun, error) {
+	started, err := readStarted(ctx, source, runPath)
+	if err != nil {
+		return nil, err
+	}
+	run := &JobRun{Job: job, ID: path.Base(runPath), Path: runPath, URL: instance.JobURL(runPath), Started: time.Unix(started.Timestamp, 0), Result: JobResultPending, Revision: revision(started)}
+
+	finished, err := readFinished(ctx, source, runPath)
+	if err != nil {
+		// finished.json doesn't exist until the run finishes
+		klog.V(2).Infof("considering run %s pending: %+v", runPath, err)
+		return run, nil
+	}
+	if finished.Timestamp != nil {
+		finishedAt := time.Unix(*finished.Timestamp, 0)
+		run.Finished = &finishedAt
+	}
+	switch {
+	case strings.EqualFold(finished.Result, string(JobResultAborted)):
+		run.Result = JobResultAborted
+	case finished.Passed != nil && *finished.Passed:
+		run.Result = JobResultSuccess
+	default:
+		run.Result = JobResultFailure
+	}
+	return run, nil
+}
+
+// revision returns the identifier of the code tested by the run
+func revision(started *metadata.Started) string {
+	if started.RepoCommit != "" {
+		return started.RepoCommit
+	}
+	var repos []string
+	for repo, refs := range started.Repos {
+		repos = append(repos, repo+"="+refs)
+	}
+	sort.Strings(repos)
+	return strings.Join(repos, ";")
+}
+
+// jobRunRef references a run of a job - either directly by its path, or by the presubmit index file
+// pointing to it, which is read only once the run is needed
+type jobRunRef struct {
+	buildID string
+	path    string
+	index   string
+}
+
+// resolve returns the path of the referenced run
+func (r jobRunRef) resolve(ctx context.Context, source ArtifactSource) (string, error) {
+	if r.path != "" {
+		return r.path, nil
+	}
+	return readPresubmitIndex(ctx, source, r.index)
+}
+
+// listJobRunRefs returns references to all runs of the given job within the bucket, sorted by the build ID (newest first)
+func listJobRunRefs(ctx context.Context, source ArtifactSource, job string) ([]jobRunRef, error) {
+	var refs []jobRunRef
+
+	// Periodic (and postsubmit) jobs store their runs in "logs/<job>/<build-id>/"
+	dirs, err := source.ListDirectories(ctx, periodicLogsPrefix+job+"/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs of job %s: %+v", job, err)
+	}
+	for _, dir := range dirs {
+		runPath := strings.TrimSuffix(dir, "/")
+		refs = append(refs, jobRunRef{buildID: path.Base(runPath), path: runPath})
+	}
+
+	if len(refs) == 0 {
+		// Presubmit jobs have an index of runs in "pr-logs/directory/<job>/<build-id>.txt" files,
+		// the build ID is part of the index file's name
+		objects, err := source.List(ctx, presubmitIndexPrefix+job+"/")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list runs of job %s: %+v", job, err)
+		}
+		for _, object := range objects {
+			buildID := strings.TrimSuffix(path.Base(object.Name), presubmitIndexFileExt)
+			if path.Ext(object.Name) != presubmitIndexFileExt || !isBuildID(buildID) {
+				continue
+			}
+			refs = append(refs, jobRunRef{buildID: buildID, index: object.Name})
+		}
+	}
+
+	sort.SliceStable(refs, func(i, j int) bool {
+		return compareBuildIDs(refs[i].buildID, refs[j].buildID) > 0
+	})
+
+	return refs, nil
+}
+
+// readPresubmitIndex reads the "pr-logs/directory/<job>/<build-id>.txt" file and returns the path of the run it points to.
+// The file contains the link to the run's directory, e.g. "gs://<bucket>/pr-logs/pull/<org_repo>/<pr>/<job>/<build-id>"
+func readPresubmitIndex(ctx context.Context, source ArtifactSource, name string) (string, error) {
+	link, err := readObject(ctx, source, name)
+	if err != nil {
+		return "", err
+	}
+	// => e.g. [ "gs:", "", "<bucket>", "pr-logs/pull/..." ]
+	sp := strings.SplitN(strings.TrimSpace(string(link)), "/", 4)
+	if len(sp) != 4 {
+		return "", fmt.Errorf("unexpected content of %s: %q", name, link)
+	}
+	return strings.TrimSuffix(sp[3], "/"), nil
+}
+
+// readStarted reads the started.json file of the run with the given path
+func readStarted(ctx context.Context, source ArtifactSource, runPath string) (*metadata.Started, error) {
+	data, err := readObject(ctx, source, runPath+"/"+startedFilename)
+	if err != nil {
+		return nil, err
+	}
+	started := &metadata.Started{}
+	if err := json.Unmarshal(data, started); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal %s: %+v", startedFilename, err)
+	}
+	return started, nil
+}
+
+// readFinished reads the finished.json file of the run with the given path
+func readFinished(ctx context.Context, source ArtifactSource, runPath string) (*metadata.Finished, error) {
+	data, err := readObject(ctx, source, runPath+"/"+finishedFilename)
+	if err != nil {
+		return nil, err
+	}
+	finished := &metadata.Finished{}
+	if err := json.Unmarshal(data, finished); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal %s: %+v", finishedFilename, err)
+	}
+	return finished, nil
+}
+
+func readObject(ctx context.Context, source ArtifactSource, name string) ([]byte, error) {
+	rc, err := source.Open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %+v", name, err)
+	}
+	return data, nil
+}
+
+func isBuildID(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
+
+// compareBuildIDs compares numeric build IDs (falls back to string comparison for non-numeric IDs)
+func compareBuildIDs(a, b string) int {
+	ai, errA := strconv.ParseUint(a, 10, 64)
+	bi, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA != nil || errB != nil:
+		return strings.Compare(a, b)
+	case ai > bi:
+		return 1
+	case ai < bi:
+		return -1
+	}
+	return 0
+}
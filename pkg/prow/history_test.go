@@ -0,0 +1,156 @@
+package prow
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestListJobRuns tests listing runs of periodic and presubmit jobs mirrored in a local directory
+func TestListJobRuns(t *testing.T) {
+	now := time.Now().Unix()
+	started := func(agoHours int64) string {
+		return fmt.Sprintf(`{"timestamp": %d}`, now-agoHours*3600)
+	}
+	finished := func(agoHours int64, passed bool) string {
+		return fmt.Sprintf(`{"timestamp": %d, "passed": %t}`, now-agoHours*3600, passed)
+	}
+	presubmitRun := "pr-logs/pull/org_repo/7/pull-ci-org-repo-main-e2e/12"
+	root := createLocalBucket(t, map[string]string{
+		"logs/periodic-e2e/9/started.json":                             started(1),
+		"logs/periodic-e2e/10/started.json":                            started(1),
+		"logs/periodic-e2e/10/finished.json":                           finished(0, true),
+		"logs/periodic-e2e/8/started.json":                             started(2),
+		"logs/periodic-e2e/8/finished.json":                            finished(1, false),
+		"logs/periodic-e2e/1/started.json":                             started(48),
+		"logs/periodic-e2e/1/finished.json":                            finished(47, true),
+		"logs/periodic-e2e/latest-build.txt":                           "10",
+		"pr-logs/directory/pull-ci-org-repo-main-e2e/12.txt":           "gs://" + DefaultInstance.Bucket + "/" + presubmitRun,
+		"pr-logs/directory/pull-ci-org-repo-main-e2e/latest-build.txt": "12",
+		presubmitRun + "/started.json":                                 started(1),
+		presubmitRun + "/finished.json":                                `{"timestamp": 1, "passed": false, "result": "ABORTED"}`,
+	})
+	source, err := NewArtifactSource(SourceConfig{Type: LocalSourceType, LocalDir: root})
+	if err != nil {
+		t.Fatalf("failed to create local artifact source: %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		job         string
+		filter      JobRunFilter
+		expectedIDs []string
+	}{
+		{
+			name:        "All runs, newest first",
+			job:         "periodic-e2e",
+			expectedIDs: []string{"10", "9", "8", "1"},
+		},
+		{
+			name:        "Runs within the time window",
+			job:         "periodic-e2e",
+			filter:      JobRunFilter{Since: time.Now().Add(-24 * time.Hour)},
+			expectedIDs: []string{"10", "9", "8"},
+		},
+		{
+			name:        "Runs with the given results",
+			job:         "periodic-e2e",
+			filter:      JobRunFilter{Results: []JobResult{JobResultFailure, "pending"}},
+			expectedIDs: []string{"9", "8"},
+		},
+		{
+			name:        "Limited number of runs",
+			job:         "periodic-e2e",
+			filter:      JobRunFilter{Limit: 1},
+			expectedIDs: []string{"10"},
+		},
+		{
+			name:        "Presubmit runs",
+			job:         "pull-ci-org-repo-main-e2e",
+			expectedIDs: []string{"12"},
+		},
+		{
+			name: "Unknown job",
+			job:  "unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs, err := ListJobRuns(ctx, source, DefaultInstance, tt.job, tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var ids []string
+			for _, run := range runs {
+				ids = append(ids, run.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.expectedIDs) {
+				t.Errorf("expected runs %v, got %v", tt.expectedIDs, ids)
+			}
+		})
+	}
+
+	latest, err := LatestJobRun(ctx, source, DefaultInstance, "periodic-e2e")
+	if err != nil {
+		t.Fatalf("failed to get the latest run: %v", err)
+	}
+	if latest.ID != "10" || latest.Result != JobResultSuccess || latest.Finished == nil {
+		t.Errorf("expected the latest run 10 to have finished successfully, got %+v", latest)
+	}
+
+	latest, err = LatestJobRun(ctx, source, DefaultInstance, "pull-ci-org-repo-main-e2e")
+	if err != nil {
+		t.Fatalf("failed to get the latest presubmit run: %v", err)
+	}
+	if expectedURL := DefaultInstance.JobViewURL + DefaultInstance.Bucket + "/" + presubmitRun; latest.URL != expectedURL || latest.Result != JobResultAborted {
+		t.Errorf("expected the latest presubmit run to be aborted with URL %q, got %+v", expectedURL, latest)
+	}
+}
+
+// countingSource counts objects opened within the wrapped ArtifactSource
+type countingSource struct {
+	ArtifactSource
+	opened []string
+}
+
+func (s *countingSource) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	s.opened = append(s.opened, name)
+	return s.ArtifactSource.Open(ctx, name)
+}
+
+// TestListJobRunsReadsOnlyNeededIndexFiles tests that only index files of the returned presubmit runs are read
+func TestListJobRunsReadsOnlyNeededIndexFiles(t *testing.T) {
+	files := map[string]string{}
+	for i := 1; i <= 20; i++ {
+		runPath := fmt.Sprintf("pr-logs/pull/org_repo/%d/pull-ci-org-repo-main-e2e/%d", i, i)
+		files[fmt.Sprintf("pr-logs/directory/pull-ci-org-repo-main-e2e/%d.txt", i)] = "gs://" + DefaultInstance.Bucket + "/" + runPath
+		files[runPath+"/started.json"] = fmt.Sprintf(`{"timestamp": %d}`, time.Now().Unix())
+	}
+	local, err := NewArtifactSource(SourceConfig{Type: LocalSourceType, LocalDir: createLocalBucket(t, files)})
+	if err != nil {
+		t.Fatalf("failed to create local artifact source: %v", err)
+	}
+	source := &countingSource{ArtifactSource: local}
+
+	runs, err := ListJobRuns(context.Background(), source, DefaultInstance, "pull-ci-org-repo-main-e2e", JobRunFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID != "20" || runs[1].ID != "19" {
+		t.Errorf("expected the 2 newest runs, got %+v", runs)
+	}
+	var indexFiles []string
+	for _, name := range source.opened {
+		if strings.HasPrefix(name, presubmitIndexPrefix) {
+			indexFiles = append(indexFiles, path.Base(name))
+		}
+	}
+	if fmt.Sprint(indexFiles) != "[20.txt 19.txt]" {
+		t.Errorf("expected only index files of the returned runs to be read, got %v", indexFiles)
+	}
+}
@@ -0,0 +1,73 @@
+package prow
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultInstanceName is the name of the Prow instance used when no other instance is selected
+const DefaultInstanceName = "openshift-ci"
+
+// Instance represents a Prow deployment (and the bucket it stores job artifacts in)
+type Instance struct {
+	// Name is used for selecting the instance, e.g. "openshift-ci"
+	Name string `json:"name"`
+	// Bucket is the name of the bucket storing job artifacts, e.g. "test-platform-results"
+	Bucket string `json:"bucket"`
+	// ProwJobURL is the endpoint returning ProwJob YAML for the job ID appended to it
+	ProwJobURL string `json:"prowJobURL"`
+	// ArtifactBrowserURL is the URL prefix for browsing the bucket's content (e.g. via gcsweb)
+	ArtifactBrowserURL string `json:"artifactBrowserURL"`
+	// JobViewURL is the URL prefix of the Prow's job view (Spyglass), followed by the bucket name and the job path
+	JobViewURL string `json:"jobViewURL"`
+	// CredentialsFile is an optional path to the GCS service account key - anonymous access is used if empty
+	CredentialsFile string `json:"credentialsFile"`
+}
+
+// DefaultInstance is the openshift-ci Prow instance (prow.ci.openshift.org)
+var DefaultInstance = Instance{
+	Name:               DefaultInstanceName,
+	Bucket:             "test-platform-results",
+	ProwJobURL:         "https://prow.ci.openshift.org/prowjob?prowjob=",
+	ArtifactBrowserURL: "https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/test-platform-results/",
+	JobViewURL:         "https://prow.ci.openshift.org/view/gs/",
+}
+
+// GetInstance returns the instance with the given name from the list of instances.
+// DefaultInstance is returned for DefaultInstanceName, unless it is overridden within the list
+func GetInstance(instances []Instance, name string) (Instance, error) {
+	for _, i := range instances {
+		if i.Name == name {
+			if i.Bucket == "" {
+				return Instance{}, fmt.Errorf("bucket is not defined for Prow instance %q", name)
+			}
+			return i, nil
+		}
+	}
+	if name == DefaultInstanceName || name == "" {
+		return DefaultInstance, nil
+	}
+	return Instance{}, fmt.Errorf("Prow instance %q is not defined", name)
+}
+
+// ObjectPathFromURL returns the path of the object within the instance's bucket
+// based on the given URL (Prow job URL, artifact browser URL, ...) containing the bucket name, e.g.
+// "https://prow.ci.openshift.org/view/gs/test-platform-results/logs/job/123" => "logs/job/123"
+func (i Instance) ObjectPathFromURL(url string) (string, error) {
+	sp := strings.Split(url, "/"+i.Bucket+"/")
+	if len(sp) != 2 {
+		return "", fmt.Errorf("failed to determine object path - URL: '%s', bucket name: '%s'", url, i.Bucket)
+	}
+	return sp[1], nil
+}
+
+// JobURL returns the URL of the job view for a job run with the given path in the bucket, e.g.
+// "logs/job/123" => "https://prow.ci.openshift.org/view/gs/test-platform-results/logs/job/123".
+// If the instance doesn't define JobViewURL, the "gs://" URL is returned
+func (i Instance) JobURL(jobPath string) string {
+	prefix := i.JobViewURL
+	if prefix == "" {
+		prefix = "gs://"
+	}
+	return prefix + i.Bucket + "/" + strings.Trim(jobPath, "/")
+}
@@ -7,14 +7,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"path"
 	"regexp"
 	"strings"
-	"time"
+	"sync"
 
-	"cloud.google.com/go/storage"
 	"golang.org/x/exp/slices"
-	"google.golang.org/api/iterator"
-	"google.golang.org/api/option"
 	"k8s.io/klog/v2"
 	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	"sigs.k8s.io/yaml"
@@ -23,23 +21,54 @@ import (
 // NewArtifactScanner creates a new instance of ArtifactScanner,
 // requires a valid ScannerConfig
 func NewArtifactScanner(cfg ScannerConfig) (*ArtifactScanner, error) {
-	ctx := context.Background()
-	client, err := storage.NewClient(ctx, option.WithoutAuthentication())
-	if err != nil {
-		return nil, fmt.Errorf("failed to create new GCS client: %+v", err)
+	if cfg.Instance.Bucket == "" {
+		cfg.Instance = DefaultInstance
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultConcurrency
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+	source := cfg.Source
+	if source == nil {
+		var err error
+		source, err = NewGCSSource(cfg.Instance.Bucket, cfg.Instance.CredentialsFile)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return &ArtifactScanner{
-		Client: client,
+	as := &ArtifactScanner{
+		source: source,
 		config: cfg,
-	}, nil
+	}
+	if gcs, ok := source.(*GCSSource); ok {
+		as.Client = gcs.Client()
+	}
+	return as, nil
 }
 
 // Run processes the artifacts associated with the Prow job and stores required files
 // with their associated openshift-ci step names and their content in ArtifactStepMap.
 func (as *ArtifactScanner) Run() error {
+	return as.RunWithContext(context.Background())
+}
+
+// RunWithContext is like Run, but the scan is bound to the given context (in addition to ScannerConfig.Timeout).
+// The same context should be used for reading the full content of truncated artifacts (Artifact.Open) afterwards
+func (as *ArtifactScanner) RunWithContext(ctx context.Context) error {
+	// All requests (including downloads of the artifacts) share the same deadline
+	ctx, cancel := context.WithTimeout(ctx, as.config.Timeout)
+	defer cancel()
+
 	// Determine job target and Prow job URL.
-	jobTarget, pjURL, err := as.determineJobDetails()
+	jobTarget, pjURL, err := as.determineJobDetails(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to determine job details: %+v", err)
 	}
@@ -48,15 +77,24 @@ func (as *ArtifactScanner) Run() error {
 	if err != nil {
 		return fmt.Errorf("failed to get artifact directory prefix: %+v", err)
 	}
+	as.ProwJobURL = pjURL
+	if as.JobName == "" {
+		// => e.g. ".../pull-ci-redhat-appstudio-infra-deployments-main-appstudio-e2e-tests/123" => "pull-ci-redhat-appstudio-infra-deployments-main-appstudio-e2e-tests"
+		as.JobName = path.Base(path.Dir(strings.TrimSuffix(pjURL, "/")))
+	}
 
-	// Iterate over storage objects.
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*2)
-	defer cancel()
-	as.bucketHandle = as.Client.Bucket(bucketName)
-	it := as.bucketHandle.Objects(ctx, &storage.Query{Prefix: artifactDirectoryPrefix})
+	// List storage objects.
+	var objects []ObjectAttrs
+	err = retryWithBackoff(ctx, as.config.MaxRetries, retryBackoff, func() (err error) {
+		objects, err = as.source.List(ctx, artifactDirectoryPrefix)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list storage objects: %+v", err)
+	}
 
 	// Process storage objects.
-	if err := as.processStorageObjects(ctx, it, artifactDirectoryPrefix, pjURL); err != nil {
+	if err := as.processStorageObjects(ctx, objects, artifactDirectoryPrefix, pjURL); err != nil {
 		return fmt.Errorf("failed to process storage objects: %+v", err)
 	}
 
@@ -64,66 +102,117 @@ func (as *ArtifactScanner) Run() error {
 }
 
 // Helper function to determine job details.
-func (as *ArtifactScanner) determineJobDetails() (jobTarget, pjURL string, err error) {
+// The job target is determined (in this order) from the --target arg in the ProwJob YAML,
+// from the job target rules matching the job name/URL, or discovered from the job's "artifacts/" directory
+func (as *ArtifactScanner) determineJobDetails(ctx context.Context) (jobTarget, pjURL string, err error) {
+	var jobName string
 	switch {
 	case as.config.ProwJobID != "":
-		pjYAML, err := getProwJobYAML(as.config.ProwJobID)
+		pjYAML, err := getProwJobYAML(ctx, as.config.Instance.ProwJobURL, as.config.ProwJobID)
 		if err != nil {
 			return "", "", fmt.Errorf("failed to get Prow job YAML: %+v", err)
 		}
+		pjURL = pjYAML.Status.URL
+		jobName = pjYAML.Spec.Job
+		as.JobName = jobName
 		jobTarget, err = determineJobTargetFromYAML(pjYAML)
-		if err != nil {
-			return "", "", fmt.Errorf("failed to determine job target from YAML: %+v", err)
+		if err == nil {
+			return jobTarget, pjURL, nil
 		}
-		pjURL = pjYAML.Status.URL
+		klog.Warningf("%+v", err)
 
 	case as.config.ProwJobURL != "":
 		pjURL = as.config.ProwJobURL
-		jobTarget, err = determineJobTargetFromProwJobURL(pjURL)
-		if err != nil {
-			return "", "", fmt.Errorf("failed to determine job target from Prow job URL: %+v", err)
-		}
 
 	default:
 		return "", "", fmt.Errorf("ScannerConfig doesn't contain either ProwJobID or ProwJobURL")
 	}
 
+	rules := as.config.JobTargetRules
+	if rules == nil {
+		rules = DefaultJobTargetRules
+	}
+	jobTarget, err = determineJobTargetFromRules(rules, jobName, pjURL)
+	if err == nil {
+		return jobTarget, pjURL, nil
+	}
+	klog.Infof("%+v - trying to discover the target from job artifacts", err)
+
+	jobPath, err := as.config.Instance.ObjectPathFromURL(pjURL)
+	if err != nil {
+		return "", "", err
+	}
+	jobTarget, err = discoverJobTarget(ctx, as.source, jobPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to determine job target from Prow job URL: %+v", err)
+	}
+
 	return jobTarget, pjURL, nil
 }
 
 // Helper function to process storage objects.
-func (as *ArtifactScanner) processStorageObjects(ctx context.Context, it *storage.ObjectIterator, artifactDirectoryPrefix, pjURL string) error {
-	var objectAttrs *storage.ObjectAttrs
-	var err error
-
-	objectAttrs, err = it.Next()
-	if errors.Is(err, iterator.Done) {
+func (as *ArtifactScanner) processStorageObjects(ctx context.Context, objects []ObjectAttrs, artifactDirectoryPrefix, pjURL string) error {
+	if len(objects) == 0 {
 		// No files present within the target directory - get the root build-log.txt instead.
-		if err := as.handleEmptyDirectory(ctx, pjURL, artifactDirectoryPrefix); err != nil {
-			return err
-		}
-		return nil
+		return as.handleEmptyDirectory(ctx, pjURL, artifactDirectoryPrefix)
 	}
 
-	// Iterate over storage objects.
-	for {
-		if err != nil {
-			return fmt.Errorf("failed to iterate over storage objects: %+v", err)
+	var required []ObjectAttrs
+	for _, object := range objects {
+		// the filter is matched against the path relative to the artifact directory, e.g. "redhat-appstudio-e2e/artifacts/junit.xml"
+		if as.isRequiredFile(strings.TrimPrefix(object.Name, artifactDirectoryPrefix)) {
+			required = append(required, object)
 		}
-		fullArtifactName := objectAttrs.Name
-		if as.isRequiredFile(fullArtifactName) {
-			if err := as.processRequiredFile(fullArtifactName, artifactDirectoryPrefix); err != nil {
-				return err
+	}
+	return as.downloadAll(ctx, required, func(ctx context.Context, object ObjectAttrs) error {
+		return as.processRequiredFile(ctx, object, artifactDirectoryPrefix)
+	})
+}
+
+// downloadAll calls 'process' for every object by a fixed pool of at most ScannerConfig.Concurrency workers.
+// The remaining objects are not processed once any of the calls fails, or once the given context is done
+// (e.g. ScannerConfig.Timeout passed) - the error of the context is returned then, as the scan is incomplete
+func (as *ArtifactScanner) downloadAll(parent context.Context, objects []ObjectAttrs, process func(context.Context, ObjectAttrs) error) error {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	queue := make(chan ObjectAttrs)
+	errorsChan := make(chan error, len(objects))
+	var wg sync.WaitGroup
+	for i := 0; i < min(as.config.Concurrency, len(objects)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for object := range queue {
+				if ctx.Err() != nil {
+					continue
+				}
+				if err := process(ctx, object); err != nil {
+					errorsChan <- err
+					// Stop other downloads - the scan failed anyway
+					cancel()
+				}
 			}
-		}
+		}()
+	}
 
-		objectAttrs, err = it.Next()
-		if errors.Is(err, iterator.Done) {
-			break
-		}
+	for _, object := range objects {
+		queue <- object
 	}
+	close(queue)
+	wg.Wait()
+	close(errorsChan)
 
-	return nil
+	var errs []error
+	for err := range errorsChan {
+		errs = append(errs, err)
+	}
+	// workers skip the remaining objects once the context is done, without reporting any error
+	if err := parent.Err(); err != nil {
+		errs = append(errs, fmt.Errorf("scan interrupted before all artifacts were processed: %w", err))
+	}
+
+	return errors.Join(errs...)
 }
 
 // Helper function to handle an empty directory.
@@ -135,35 +224,29 @@ func (as *ArtifactScanner) handleEmptyDirectory(ctx context.Context, pjURL, arti
 	as.config.FileNameFilter = []string{fileName}
 
 	// Check for build log file.
-	sp := strings.Split(pjURL, "/"+bucketName+"/")
-	if len(sp) != 2 {
-		return fmt.Errorf("failed to determine artifact directory's prefix - Prow job URL: '%s', bucket name: '%s'", pjURL, bucketName)
+	jobPath, err := as.config.Instance.ObjectPathFromURL(pjURL)
+	if err != nil {
+		return fmt.Errorf("failed to determine artifact directory's prefix: %+v", err)
 	}
-	buildLogPrefix := sp[1] + "/" + fileName
+	buildLogPrefix := jobPath + "/" + fileName
 
 	// Iterate over build log files.
-	it := as.bucketHandle.Objects(ctx, &storage.Query{Prefix: buildLogPrefix})
-	for {
-		attrs, err := it.Next()
-		if errors.Is(err, iterator.Done) {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("failed to iterate over storage objects: %+v", err)
-		}
-		fullArtifactName := attrs.Name
-
-		if err := as.initArtifactStepMap(ctx, fileName, fullArtifactName, "/"); err != nil {
-			return err
-		}
+	var objects []ObjectAttrs
+	err = retryWithBackoff(ctx, as.config.MaxRetries, retryBackoff, func() (err error) {
+		objects, err = as.source.List(ctx, buildLogPrefix)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list storage objects: %+v", err)
 	}
-
-	return nil
+	return as.downloadAll(ctx, objects, func(ctx context.Context, object ObjectAttrs) error {
+		return as.initArtifactStepMap(ctx, fileName, object, "/")
+	})
 }
 
 // Helper function to process a required file.
-func (as *ArtifactScanner) processRequiredFile(fullArtifactName, artifactDirectoryPrefix string) error {
-	parentStepName, err := getParentStepName(fullArtifactName, artifactDirectoryPrefix)
+func (as *ArtifactScanner) processRequiredFile(ctx context.Context, object ObjectAttrs, artifactDirectoryPrefix string) error {
+	parentStepName, err := getParentStepName(object.Name, artifactDirectoryPrefix)
 	if err != nil {
 		return err
 	}
@@ -173,12 +256,12 @@ func (as *ArtifactScanner) processRequiredFile(fullArtifactName, artifactDirecto
 		return nil
 	}
 
-	fileName, err := getFileName(fullArtifactName, artifactDirectoryPrefix)
+	fileName, err := getFileName(object.Name, artifactDirectoryPrefix)
 	if err != nil {
 		return err
 	}
 
-	if err := as.initArtifactStepMap(context.Background(), fileName, fullArtifactName, parentStepName); err != nil {
+	if err := as.initArtifactStepMap(ctx, fileName, object, parentStepName); err != nil {
 		return err
 	}
 
@@ -187,17 +270,18 @@ func (as *ArtifactScanner) processRequiredFile(fullArtifactName, artifactDirecto
 
 // Helper function to initialise/update the ArtifactStepMap with content
 // of a file with given 'fileName', within the given 'parentStepName'
-func (as *ArtifactScanner) initArtifactStepMap(ctx context.Context, fileName, fullArtifactName, parentStepName string) error {
-	rc, err := as.bucketHandle.Object(fullArtifactName).NewReader(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to create objecthandle for %s: %+v", fullArtifactName, err)
-	}
-	data, err := io.ReadAll(rc)
+func (as *ArtifactScanner) initArtifactStepMap(ctx context.Context, fileName string, object ObjectAttrs, parentStepName string) error {
+	var artifact Artifact
+	err := retryWithBackoff(ctx, as.config.MaxRetries, retryBackoff, func() (err error) {
+		artifact, err = as.readArtifact(ctx, object)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("cannot read from storage reader: %+v", err)
+		return err
 	}
 
-	artifact := Artifact{Content: string(data), FullName: fullArtifactName}
+	as.mu.Lock()
+	defer as.mu.Unlock()
 	newArtifactMap := ArtifactFilenameMap{ArtifactFilename(fileName): artifact}
 
 	// No artifact step map not initialized yet
@@ -217,21 +301,26 @@ func (as *ArtifactScanner) initArtifactStepMap(ctx context.Context, fileName, fu
 	return nil
 }
 
-// Helper function to check if a file with given 'fullArtifactName',
+// Helper function to check if a file with given 'artifactPath' (relative to the artifact directory)
 // matches the file-name filter(s) defined within ScannerConfig struct
-func (as *ArtifactScanner) isRequiredFile(fullArtifactName string) bool {
+func (as *ArtifactScanner) isRequiredFile(artifactPath string) bool {
 	return slices.ContainsFunc(as.config.FileNameFilter, func(s string) bool {
 		re := regexp.MustCompile(s)
-		return re.MatchString(fullArtifactName)
+		return re.MatchString(artifactPath)
 	})
 }
 
-func getProwJobYAML(jobID string) (*v1.ProwJob, error) {
-	r, err := http.Get(prowJobYAMLPrefix + jobID)
+func getProwJobYAML(ctx context.Context, prowJobURL, jobID string) (*v1.ProwJob, error) {
 	errTemplate := "failed to get prow job YAML:"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, prowJobURL+jobID, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", errTemplate, err)
+	}
+	r, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("%s %s", errTemplate, err)
 	}
+	defer r.Body.Close()
 	if r.StatusCode > 299 {
 		return nil, fmt.Errorf("%s got response status code %v", errTemplate, r.StatusCode)
 	}
@@ -247,22 +336,6 @@ func getProwJobYAML(jobID string) (*v1.ProwJob, error) {
 	return &pj, nil
 }
 
-func determineJobTargetFromYAML(pjYAML *v1.ProwJob) (jobTarget string, err error) {
-	errPrefix := "failed to determine job target:"
-	args := pjYAML.Spec.PodSpec.Containers[0].Args
-	for _, arg := range args {
-		if strings.Contains(arg, "--target") {
-			sp := strings.Split(arg, "=")
-			if len(sp) != 2 {
-				return "", fmt.Errorf("%s expected %v to have len 2", errPrefix, sp)
-			}
-			jobTarget = sp[1]
-			return
-		}
-	}
-	return "", fmt.Errorf("%s expected %+v to contain arg --target", errPrefix, args)
-}
-
 // ParseJobSpec parses and then returns the openshift job spec data
 func ParseJobSpec(jobSpecData string) (*OpenshiftJobSpec, error) {
 	openshiftJobSpec := &OpenshiftJobSpec{}
@@ -273,33 +346,15 @@ func ParseJobSpec(jobSpecData string) (*OpenshiftJobSpec, error) {
 	return openshiftJobSpec, nil
 }
 
-func determineJobTargetFromProwJobURL(prowJobURL string) (jobTarget string, err error) {
-	switch {
-	case strings.Contains(prowJobURL, "pull-ci-redhat-appstudio-infra-deployments"):
-		// prow URL is from infra-deployments repo
-		jobTarget = "appstudio-e2e-tests"
-	case strings.Contains(prowJobURL, "pull-ci-konflux-ci-e2e-tests"):
-		// prow URL is from e2e-tests repo
-		jobTarget = "redhat-appstudio-e2e"
-	case strings.Contains(prowJobURL, "pull-ci-konflux-ci-integration-service"):
-		// prow URL is from integration-service repo
-		jobTarget = "integration-service-e2e"
-	default:
-		return "", fmt.Errorf("unable to determine the target from the ProwJobURL: %s", prowJobURL)
-	}
-
-	return jobTarget, nil
-}
-
 func getArtifactsDirectoryPrefix(artifactScanner *ArtifactScanner, prowJobURL, jobTarget string) (string, error) {
-	// => e.g. [ "https://prow.ci.openshift.org/view/gs", "pr-logs/pull/redhat-appstudio_infra-deployments/123/pull-ci-redhat-appstudio-infra-deployments-main-appstudio-e2e-tests/123" ]
-	sp := strings.Split(prowJobURL, "/"+bucketName+"/")
-	if len(sp) != 2 {
-		return "", fmt.Errorf("failed to determine artifact directory's prefix - prow job url: '%s', bucket name: '%s'", prowJobURL, bucketName)
+	// => e.g. "pr-logs/pull/redhat-appstudio_infra-deployments/123/pull-ci-redhat-appstudio-infra-deployments-main-appstudio-e2e-tests/123"
+	jobPath, err := artifactScanner.config.Instance.ObjectPathFromURL(prowJobURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to determine artifact directory's prefix: %+v", err)
 	}
 
 	// => e.g. "pr-logs/pull/redhat-appstudio_infra-deployments/123/pull-ci-redhat-appstudio-infra-deployments-main-appstudio-e2e-tests/123/artifacts/appstudio-e2e-tests/"
-	artifactDirectoryPrefix := sp[1] + "/artifacts/" + jobTarget + "/"
+	artifactDirectoryPrefix := jobPath + "/artifacts/" + jobTarget + "/"
 	artifactScanner.ArtifactDirectoryPrefix = artifactDirectoryPrefix
 
 	return artifactDirectoryPrefix, nil
@@ -320,15 +375,21 @@ func getParentStepName(fullArtifactName, artifactDirectoryPrefix string) (string
 	return parentStepName, nil
 }
 
+// getFileName returns the path of the artifact relative to its parent step's directory
 func getFileName(fullArtifactName, artifactDirectoryPrefix string) (string, error) {
+	// => e.g. [ "", "redhat-appstudio-e2e/artifacts/e2e-report.xml" ]
 	sp := strings.Split(fullArtifactName, artifactDirectoryPrefix)
 	if len(sp) != 2 {
 		return "", fmt.Errorf("cannot determine filepath - object name: %s, object prefix: %s", fullArtifactName, artifactDirectoryPrefix)
 	}
 	parentStepFilePath := sp[1]
 
-	sp = strings.Split(parentStepFilePath, "/")
-	fileName := sp[len(sp)-1]
+	// => e.g. [ "redhat-appstudio-e2e", "artifacts/e2e-report.xml" ]
+	sp = strings.SplitN(parentStepFilePath, "/", 2)
+	if len(sp) != 2 {
+		// The file isn't located within any step's directory
+		return sp[0], nil
+	}
 
-	return fileName, nil
+	return sp[1], nil
 }
@@ -0,0 +1,173 @@
+package prow
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testJobPath = "pr-logs/pull/konflux-ci_e2e-tests/1/pull-ci-konflux-ci-e2e-tests-main-konflux-e2e/123"
+
+// createLocalBucket creates a directory mirroring the bucket structure with the given files
+func createLocalBucket(t *testing.T, files map[string]string) string {
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+			t.Fatalf("failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write file %s: %v", name, err)
+		}
+	}
+	return root
+}
+
+// TestArtifactScannerWithLocalSource tests scanning artifacts of a Prow job mirrored in a local directory
+func TestArtifactScannerWithLocalSource(t *testing.T) {
+	artifactsPrefix := testJobPath + "/artifacts/redhat-appstudio-e2e/"
+	root := createLocalBucket(t, map[string]string{
+		testJobPath + "/build-log.txt":                                       "root build log",
+		artifactsPrefix + "redhat-appstudio-e2e/build-log.txt":               "e2e build log",
+		artifactsPrefix + "redhat-appstudio-e2e/finished.json":               `{"passed": true}`,
+		artifactsPrefix + "redhat-appstudio-e2e/artifacts/a.log":             "not required",
+		artifactsPrefix + "redhat-appstudio-e2e/artifacts/suite-a/junit.xml": "<testsuites/>",
+		artifactsPrefix + "redhat-appstudio-e2e/artifacts/suite-b/junit.xml": "<testsuite/>",
+		artifactsPrefix + "gather-extra/finished.json":                       `{"passed": false}`,
+		artifactsPrefix + "redhat-appstudio-report/finished.json":            `{"passed": true}`,
+	})
+
+	source, err := NewArtifactSource(SourceConfig{Type: LocalSourceType, LocalDir: root})
+	if err != nil {
+		t.Fatalf("failed to create local artifact source: %v", err)
+	}
+	scanner, err := NewArtifactScanner(ScannerConfig{
+		ProwJobURL:     "https://prow.ci.openshift.org/view/gs/" + DefaultInstance.Bucket + "/" + testJobPath,
+		FileNameFilter: []string{"finished.json", "build-log.txt", `junit\.xml`},
+		StepsToSkip:    []string{"redhat-appstudio-report"},
+		Source:         source,
+	})
+	if err != nil {
+		t.Fatalf("failed to create artifact scanner: %v", err)
+	}
+	if err := scanner.Run(); err != nil {
+		t.Fatalf("failed to run artifact scanner: %v", err)
+	}
+
+	if scanner.ArtifactDirectoryPrefix != artifactsPrefix {
+		t.Errorf("expected artifact directory prefix %q, got %q", artifactsPrefix, scanner.ArtifactDirectoryPrefix)
+	}
+	if len(scanner.ArtifactStepMap) != 2 {
+		t.Fatalf("expected 2 steps to be collected, got %d: %+v", len(scanner.ArtifactStepMap), scanner.ArtifactStepMap)
+	}
+	e2eStep := scanner.ArtifactStepMap["redhat-appstudio-e2e"]
+	if len(e2eStep) != 4 {
+		t.Errorf("expected 4 files within the e2e step, got %d: %+v", len(e2eStep), e2eStep)
+	}
+	for _, junitPath := range []ArtifactFilename{"artifacts/suite-a/junit.xml", "artifacts/suite-b/junit.xml"} {
+		if _, ok := e2eStep[junitPath]; !ok {
+			t.Errorf("expected %s to be collected within the e2e step", junitPath)
+		}
+	}
+	if got := e2eStep["build-log.txt"].Content; got != "e2e build log" {
+		t.Errorf("expected build log content %q, got %q", "e2e build log", got)
+	}
+
+	// filters are matched against the path relative to the artifact directory, so they can be anchored
+	scanner, err = NewArtifactScanner(ScannerConfig{
+		ProwJobURL:     "https://prow.ci.openshift.org/view/gs/" + DefaultInstance.Bucket + "/" + testJobPath,
+		FileNameFilter: []string{`^redhat-appstudio-e2e/artifacts/suite-a/[^/]*\.xml$`},
+		Source:         source,
+	})
+	if err != nil {
+		t.Fatalf("failed to create artifact scanner: %v", err)
+	}
+	if err := scanner.Run(); err != nil {
+		t.Fatalf("failed to run artifact scanner: %v", err)
+	}
+	if e2eStep := scanner.ArtifactStepMap["redhat-appstudio-e2e"]; len(scanner.ArtifactStepMap) != 1 || len(e2eStep) != 1 || e2eStep["artifacts/suite-a/junit.xml"].Content != "<testsuites/>" {
+		t.Errorf("expected only the JUnit file of suite-a to be scanned, got %+v", scanner.ArtifactStepMap)
+	}
+}
+
+// slowSource delays opening objects within the wrapped ArtifactSource, regardless of the context
+type slowSource struct {
+	ArtifactSource
+	delay time.Duration
+}
+
+func (s *slowSource) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	time.Sleep(s.delay)
+	return s.ArtifactSource.Open(ctx, name)
+}
+
+// TestArtifactScannerTimeout tests that the scan fails if the timeout passes before all artifacts are processed
+func TestArtifactScannerTimeout(t *testing.T) {
+	artifactsPrefix := testJobPath + "/artifacts/redhat-appstudio-e2e/redhat-appstudio-e2e/"
+	files := map[string]string{testJobPath + "/build-log.txt": "root build log"}
+	for i := 0; i < 10; i++ {
+		files[fmt.Sprintf("%sartifacts/suite-%d/junit.xml", artifactsPrefix, i)] = "<testsuites/>"
+	}
+	local, err := NewArtifactSource(SourceConfig{Type: LocalSourceType, LocalDir: createLocalBucket(t, files)})
+	if err != nil {
+		t.Fatalf("failed to create local artifact source: %v", err)
+	}
+	scanner, err := NewArtifactScanner(ScannerConfig{
+		ProwJobURL:     "https://prow.ci.openshift.org/view/gs/" + DefaultInstance.Bucket + "/" + testJobPath,
+		FileNameFilter: []string{`junit\.xml`},
+		Source:         &slowSource{ArtifactSource: local, delay: 50 * time.Millisecond},
+		Concurrency:    1,
+		Timeout:        200 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("failed to create artifact scanner: %v", err)
+	}
+
+	err = scanner.Run()
+	if err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("expected the scan to fail with the exceeded deadline, got %v (%d steps scanned)", err, len(scanner.ArtifactStepMap))
+	}
+}
+
+// TestDownloadAllBoundsConcurrency tests that objects are processed by at most ScannerConfig.Concurrency workers
+func TestDownloadAllBoundsConcurrency(t *testing.T) {
+	as := &ArtifactScanner{config: ScannerConfig{Concurrency: 2}}
+	var objects []ObjectAttrs
+	for i := 0; i < 10; i++ {
+		objects = append(objects, ObjectAttrs{Name: fmt.Sprintf("object-%d", i)})
+	}
+
+	var mu sync.Mutex
+	inFlight, maxInFlight, processed := 0, 0, 0
+	err := as.downloadAll(context.Background(), objects, func(_ context.Context, _ ObjectAttrs) error {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		inFlight--
+		processed++
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if processed != len(objects) || maxInFlight > 2 {
+		t.Errorf("expected %d objects processed by at most 2 workers, got %d processed by %d workers", len(objects), processed, maxInFlight)
+	}
+
+	err = as.downloadAll(context.Background(), objects, func(_ context.Context, o ObjectAttrs) error {
+		return fmt.Errorf("failed to download %s", o.Name)
+	})
+	if err == nil {
+		t.Errorf("expected the error of a failed download to be returned")
+	}
+}
@@ -0,0 +1,61 @@
+package prow
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"google.golang.org/api/googleapi"
+	"k8s.io/klog/v2"
+)
+
+// retryWithBackoff calls fn until it succeeds, returns a non-transient error, the maximum number
+// of retries is reached or the context is done. The delay between attempts doubles after each retry
+func retryWithBackoff(ctx context.Context, maxRetries int, backoff time.Duration, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= maxRetries || !isTransientError(err) {
+			return err
+		}
+		klog.Warningf("attempt %d failed with a transient error, retrying in %s: %+v", attempt+1, backoff, err)
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// isTransientError checks if the given error (returned by the ArtifactSource) is worth retrying
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var gErr *googleapi.Error
+	if errors.As(err, &gErr) {
+		return isTransientStatusCode(gErr.Code)
+	}
+	var s3Err minio.ErrorResponse
+	if errors.As(err, &s3Err) {
+		return isTransientStatusCode(s3Err.StatusCode)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return netErr.Timeout()
+	}
+	return false
+}
+
+func isTransientStatusCode(code int) bool {
+	return code == http.StatusTooManyRequests || code == http.StatusRequestTimeout || code >= http.StatusInternalServerError
+}
@@ -0,0 +1,77 @@
+package prow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// TestRetryWithBackoff tests retrying of operations failed with transient errors
+func TestRetryWithBackoff(t *testing.T) {
+	transientErr := fmt.Errorf("failed to get object: %w", &googleapi.Error{Code: http.StatusServiceUnavailable})
+	permanentErr := fmt.Errorf("failed to get object: %w", &googleapi.Error{Code: http.StatusNotFound})
+
+	tests := []struct {
+		name             string
+		errs             []error
+		maxRetries       int
+		expectedAttempts int
+		expectError      bool
+	}{
+		{
+			name:             "Succeeds after transient errors",
+			errs:             []error{transientErr, transientErr, nil},
+			maxRetries:       3,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "Gives up after max retries",
+			errs:             []error{transientErr, transientErr, transientErr},
+			maxRetries:       2,
+			expectedAttempts: 3,
+			expectError:      true,
+		},
+		{
+			name:             "Doesn't retry permanent errors",
+			errs:             []error{permanentErr, nil},
+			maxRetries:       3,
+			expectedAttempts: 1,
+			expectError:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := retryWithBackoff(context.Background(), tt.maxRetries, time.Millisecond, func() error {
+				err := tt.errs[attempts]
+				attempts++
+				return err
+			})
+			if attempts != tt.expectedAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.expectedAttempts, attempts)
+			}
+			if (err != nil) != tt.expectError {
+				t.Errorf("expected error: %v, got: %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+// TestRetryWithBackoffContextDone tests that retries stop once the shared deadline is exceeded
+func TestRetryWithBackoffContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := retryWithBackoff(ctx, 5, time.Hour, func() error {
+		return &googleapi.Error{Code: http.StatusTooManyRequests}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled error, got %v", err)
+	}
+}
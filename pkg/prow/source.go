@@ -0,0 +1,297 @@
+package prow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// ArtifactSource abstracts the storage holding Prow job artifacts,
+// so that ArtifactScanner can work with GCS, S3-compatible buckets or a local directory
+type ArtifactSource interface {
+	// List returns attributes of all objects whose name starts with the given prefix
+	List(ctx context.Context, prefix string) ([]ObjectAttrs, error)
+	// ListDirectories returns names of "directories" (common prefixes ending with "/")
+	// located directly under the given prefix, e.g. "logs/job/" => ["logs/job/1/", "logs/job/2/"]
+	ListDirectories(ctx context.Context, prefix string) ([]string, error)
+	// Open returns a reader for the object with the given name
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+}
+
+// ObjectAttrs represents the attributes of a single object within the ArtifactSource
+type ObjectAttrs struct {
+	// Name is the full name of the object, e.g. "logs/<job-name>/<build-id>/build-log.txt"
+	Name string
+	// Size is the size of the object in bytes
+	Size int64
+}
+
+// SourceType represents the kind of storage used as an ArtifactSource
+type SourceType string
+
+const (
+	// GCSSourceType represents Google Cloud Storage bucket
+	GCSSourceType SourceType = "gcs"
+	// S3SourceType represents S3-compatible (AWS S3, MinIO, ...) bucket
+	S3SourceType SourceType = "s3"
+	// LocalSourceType represents local directory mirroring the bucket's structure
+	LocalSourceType SourceType = "local"
+)
+
+// SourceConfig contains fields required for creating an ArtifactSource
+type SourceConfig struct {
+	Type SourceType
+	// Bucket is the name of the GCS/S3 bucket - defaults to the bucket of DefaultInstance
+	Bucket string
+	// CredentialsFile is an optional path to the GCS service account key (only used by GCSSourceType)
+	CredentialsFile string
+	// LocalDir is the path to the directory mirroring the bucket's structure (only used by LocalSourceType)
+	LocalDir string
+	// S3Endpoint is the host (and optionally port) of the S3-compatible service, e.g. "localhost:9000"
+	S3Endpoint string
+	// S3AccessKey and S3SecretKey are optional - anonymous access is used if they are empty
+	S3AccessKey string
+	S3SecretKey string
+	// S3Insecure disables TLS when connecting to the S3 endpoint
+	S3Insecure bool
+}
+
+// NewArtifactSource creates the ArtifactSource defined by the given SourceConfig
+func NewArtifactSource(cfg SourceConfig) (ArtifactSource, error) {
+	if cfg.Bucket == "" {
+		cfg.Bucket = DefaultInstance.Bucket
+	}
+	switch cfg.Type {
+	case GCSSourceType, "":
+		return NewGCSSource(cfg.Bucket, cfg.CredentialsFile)
+	case S3SourceType:
+		return NewS3Source(cfg)
+	case LocalSourceType:
+		return NewLocalSource(cfg.LocalDir)
+	default:
+		return nil, fmt.Errorf("unsupported artifact source type %q (supported: %s, %s, %s)", cfg.Type, GCSSourceType, S3SourceType, LocalSourceType)
+	}
+}
+
+// GCSSource reads artifacts from a Google Cloud Storage bucket
+type GCSSource struct {
+	client       *storage.Client
+	bucketHandle *storage.BucketHandle
+}
+
+// NewGCSSource creates a GCSSource for the given bucket. The client is authenticated
+// with the given service account key file, or unauthenticated if the credentialsFile is empty
+func NewGCSSource(bucket, credentialsFile string) (*GCSSource, error) {
+	clientOption := option.WithoutAuthentication()
+	if credentialsFile != "" {
+		clientOption = option.WithCredentialsFile(credentialsFile)
+	}
+	client, err := storage.NewClient(context.Background(), clientOption)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new GCS client: %+v", err)
+	}
+	return &GCSSource{client: client, bucketHandle: client.Bucket(bucket)}, nil
+}
+
+// Client returns the underlying GCS client
+func (s *GCSSource) Client() *storage.Client {
+	return s.client
+}
+
+// List returns attributes of all objects in the bucket with the given prefix
+func (s *GCSSource) List(ctx context.Context, prefix string) ([]ObjectAttrs, error) {
+	var objects []ObjectAttrs
+	it := s.bucketHandle.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate over storage objects: %w", err)
+		}
+		objects = append(objects, ObjectAttrs{Name: attrs.Name, Size: attrs.Size})
+	}
+	return objects, nil
+}
+
+// ListDirectories returns names of "directories" located directly under the given prefix
+func (s *GCSSource) ListDirectories(ctx context.Context, prefix string) ([]string, error) {
+	var dirs []string
+	it := s.bucketHandle.Objects(ctx, &storage.Query{Prefix: prefix, Delimiter: "/"})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate over storage objects: %w", err)
+		}
+		if attrs.Prefix != "" {
+			dirs = append(dirs, attrs.Prefix)
+		}
+	}
+	return dirs, nil
+}
+
+// Open returns a reader for the object with the given name
+func (s *GCSSource) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	rc, err := s.bucketHandle.Object(name).NewReader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create objecthandle for %s: %w", name, err)
+	}
+	return rc, nil
+}
+
+// S3Source reads artifacts from an S3-compatible bucket
+type S3Source struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Source creates a S3Source based on the S3-related fields of the given SourceConfig
+func NewS3Source(cfg SourceConfig) (*S3Source, error) {
+	if cfg.S3Endpoint == "" {
+		return nil, fmt.Errorf("S3 endpoint is required for the %q artifact source", S3SourceType)
+	}
+	var creds *credentials.Credentials
+	if cfg.S3AccessKey != "" {
+		creds = credentials.NewStaticV4(cfg.S3AccessKey, cfg.S3SecretKey, "")
+	} else {
+		creds = credentials.New(&credentials.Static{})
+	}
+	client, err := minio.New(cfg.S3Endpoint, &minio.Options{Creds: creds, Secure: !cfg.S3Insecure})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new S3 client: %+v", err)
+	}
+	return &S3Source{client: client, bucket: cfg.Bucket}, nil
+}
+
+// List returns attributes of all objects in the bucket with the given prefix
+func (s *S3Source) List(ctx context.Context, prefix string) ([]ObjectAttrs, error) {
+	var objects []ObjectAttrs
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("failed to iterate over storage objects: %w", obj.Err)
+		}
+		objects = append(objects, ObjectAttrs{Name: obj.Key, Size: obj.Size})
+	}
+	return objects, nil
+}
+
+// ListDirectories returns names of "directories" located directly under the given prefix
+func (s *S3Source) ListDirectories(ctx context.Context, prefix string) ([]string, error) {
+	var dirs []string
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("failed to iterate over storage objects: %w", obj.Err)
+		}
+		if strings.HasSuffix(obj.Key, "/") {
+			dirs = append(dirs, obj.Key)
+		}
+	}
+	return dirs, nil
+}
+
+// Open returns a reader for the object with the given name
+func (s *S3Source) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, name, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", name, err)
+	}
+	return obj, nil
+}
+
+// LocalSource reads artifacts from a local directory that mirrors the bucket's structure
+// (i.e. the directory contains e.g. "logs/" and "pr-logs/" subdirectories)
+type LocalSource struct {
+	rootDir string
+}
+
+// NewLocalSource creates a LocalSource for the given directory
+func NewLocalSource(rootDir string) (*LocalSource, error) {
+	info, err := os.Stat(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access local artifact directory %q: %+v", rootDir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("local artifact source %q is not a directory", rootDir)
+	}
+	return &LocalSource{rootDir: rootDir}, nil
+}
+
+// List returns attributes of all files within the directory whose (slash-separated)
+// path relative to the root directory starts with the given prefix
+func (s *LocalSource) List(_ context.Context, prefix string) ([]ObjectAttrs, error) {
+	var objects []ObjectAttrs
+	// Only walk the deepest directory fully covered by the prefix
+	walkRoot := filepath.Join(s.rootDir, filepath.FromSlash(prefix[:strings.LastIndex(prefix, "/")+1]))
+	err := filepath.WalkDir(walkRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return fmt.Errorf("failed to visit file in path %s: %+v", p, err)
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.rootDir, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, ObjectAttrs{Name: name, Size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// ListDirectories returns names of directories located directly under the given prefix
+func (s *LocalSource) ListDirectories(_ context.Context, prefix string) ([]string, error) {
+	var dirs []string
+	parent := prefix[:strings.LastIndex(prefix, "/")+1]
+	entries, err := os.ReadDir(filepath.Join(s.rootDir, filepath.FromSlash(parent)))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read directory %s: %+v", parent, err)
+	}
+	for _, e := range entries {
+		if name := parent + e.Name() + "/"; e.IsDir() && strings.HasPrefix(name, prefix) {
+			dirs = append(dirs, name)
+		}
+	}
+	return dirs, nil
+}
+
+// Open returns a reader for the file with the given name (relative to the root directory)
+func (s *LocalSource) Open(_ context.Context, name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(s.rootDir, filepath.FromSlash(filepath.Clean("/"+name))))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %+v", name, err)
+	}
+	return f, nil
+}
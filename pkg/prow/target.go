@@ -0,0 +1,95 @@
+package prow
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"k8s.io/klog/v2"
+	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+)
+
+// JobTargetRule maps Prow jobs onto the name of the openshift-ci target (i.e. the directory
+// within "artifacts/" containing step artifacts). The rule applies to jobs whose name or URL matches the Pattern
+type JobTargetRule struct {
+	// Pattern is a regular expression matched against the job name or Prow job URL
+	Pattern string `json:"pattern"`
+	// Target is the name of the openshift-ci target
+	Target string `json:"target"`
+}
+
+// DefaultJobTargetRules are used when ScannerConfig doesn't define any JobTargetRules
+var DefaultJobTargetRules = []JobTargetRule{
+	{Pattern: "pull-ci-redhat-appstudio-infra-deployments", Target: "appstudio-e2e-tests"},
+	{Pattern: "pull-ci-konflux-ci-e2e-tests", Target: "redhat-appstudio-e2e"},
+	{Pattern: "pull-ci-konflux-ci-integration-service", Target: "integration-service-e2e"},
+}
+
+// Directories within "artifacts/" created by ci-operator itself, i.e. not representing a target
+var nonTargetArtifactDirs = []string{"build-logs", "build-resources", "release"}
+
+// determineJobTargetFromRules returns the target of the first rule whose pattern matches
+// any of the given values (job name, Prow job URL)
+func determineJobTargetFromRules(rules []JobTargetRule, values ...string) (string, error) {
+	for _, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return "", fmt.Errorf("invalid job target rule pattern %q: %+v", rule.Pattern, err)
+		}
+		for _, v := range values {
+			if v != "" && re.MatchString(v) {
+				return rule.Target, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unable to determine the target from job target rules for %v", values)
+}
+
+// discoverJobTarget determines the target by listing the "artifacts/" directory of the job
+// with the given path in the bucket - it succeeds only if there is exactly one candidate directory
+func discoverJobTarget(ctx context.Context, source ArtifactSource, jobPath string) (string, error) {
+	dirs, err := source.ListDirectories(ctx, jobPath+"/artifacts/")
+	if err != nil {
+		return "", fmt.Errorf("failed to list artifacts directory: %+v", err)
+	}
+	var candidates []string
+	for _, dir := range dirs {
+		name := path.Base(dir)
+		if !slices.Contains(nonTargetArtifactDirs, name) {
+			candidates = append(candidates, name)
+		}
+	}
+	if len(candidates) != 1 {
+		return "", fmt.Errorf("unable to discover the target within %s/artifacts/ - found candidates: %v", jobPath, candidates)
+	}
+	klog.Infof("discovered job target %q from the content of %s/artifacts/", candidates[0], jobPath)
+	return candidates[0], nil
+}
+
+func determineJobTargetFromYAML(pjYAML *v1.ProwJob) (jobTarget string, err error) {
+	errPrefix := "failed to determine job target:"
+	if pjYAML.Spec.PodSpec == nil || len(pjYAML.Spec.PodSpec.Containers) == 0 {
+		return "", fmt.Errorf("%s ProwJob %q doesn't contain any container", errPrefix, pjYAML.Name)
+	}
+	args := pjYAML.Spec.PodSpec.Containers[0].Args
+	for i, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--target="):
+			// e.g. "--target=e2e"
+			if jobTarget = strings.TrimPrefix(arg, "--target="); jobTarget == "" {
+				return "", fmt.Errorf("%s empty value of %q", errPrefix, arg)
+			}
+			return jobTarget, nil
+		case arg == "--target":
+			// e.g. "--target", "e2e"
+			if i+1 >= len(args) || strings.HasPrefix(args[i+1], "-") {
+				return "", fmt.Errorf("%s missing value of the --target arg in %+v", errPrefix, args)
+			}
+			return args[i+1], nil
+		}
+	}
+	return "", fmt.Errorf("%s expected %+v to contain arg --target", errPrefix, args)
+}
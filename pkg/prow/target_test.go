@@ -0,0 +1,104 @@
+package prow
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+)
+
+// TestDetermineJobTargetFromYAML tests parsing the --target arg from ProwJob YAML
+func TestDetermineJobTargetFromYAML(t *testing.T) {
+	tests := []struct {
+		name           string
+		podSpec        *corev1.PodSpec
+		expectedTarget string
+		expectError    bool
+	}{
+		{
+			name:           "Target passed with equals sign",
+			podSpec:        &corev1.PodSpec{Containers: []corev1.Container{{Args: []string{"--report-credentials-file=/f", "--target=e2e"}}}},
+			expectedTarget: "e2e",
+		},
+		{
+			name:           "Target passed as two separate args",
+			podSpec:        &corev1.PodSpec{Containers: []corev1.Container{{Args: []string{"--target", "e2e", "--other"}}}},
+			expectedTarget: "e2e",
+		},
+		{
+			name:        "Target arg without value",
+			podSpec:     &corev1.PodSpec{Containers: []corev1.Container{{Args: []string{"--target"}}}},
+			expectError: true,
+		},
+		{
+			name:        "No containers",
+			podSpec:     &corev1.PodSpec{},
+			expectError: true,
+		},
+		{
+			name:        "No pod spec",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := determineJobTargetFromYAML(&v1.ProwJob{Spec: v1.ProwJobSpec{PodSpec: tt.podSpec}})
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got target %q", target)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if target != tt.expectedTarget {
+				t.Errorf("expected target %q, got %q", tt.expectedTarget, target)
+			}
+		})
+	}
+}
+
+// TestDetermineJobTargetFromRules tests matching job target rules against job name and URL
+func TestDetermineJobTargetFromRules(t *testing.T) {
+	rules := []JobTargetRule{
+		{Pattern: "^periodic-ci-.*-load-test$", Target: "load-test"},
+		{Pattern: "pull-ci-konflux-ci-e2e-tests", Target: "redhat-appstudio-e2e"},
+	}
+
+	target, err := determineJobTargetFromRules(rules, "periodic-ci-konflux-ci-e2e-tests-main-load-test", "")
+	if err != nil || target != "load-test" {
+		t.Errorf("expected target %q, got %q (error: %v)", "load-test", target, err)
+	}
+	target, err = determineJobTargetFromRules(rules, "", "https://prow.ci.openshift.org/view/gs/bucket/"+testJobPath)
+	if err != nil || target != "redhat-appstudio-e2e" {
+		t.Errorf("expected target %q, got %q (error: %v)", "redhat-appstudio-e2e", target, err)
+	}
+	if _, err := determineJobTargetFromRules(rules, "unknown-job"); err == nil {
+		t.Error("expected error for a job not matching any rule")
+	}
+}
+
+// TestDiscoverJobTarget tests discovering the job target from the content of the artifacts directory
+func TestDiscoverJobTarget(t *testing.T) {
+	root := createLocalBucket(t, map[string]string{
+		testJobPath + "/artifacts/build-logs/a.log":          "",
+		testJobPath + "/artifacts/release/b.log":             "",
+		testJobPath + "/artifacts/ci-operator.log":           "",
+		testJobPath + "/artifacts/my-e2e/step/finished.json": "",
+	})
+	source, err := NewLocalSource(root)
+	if err != nil {
+		t.Fatalf("failed to create local artifact source: %v", err)
+	}
+
+	target, err := discoverJobTarget(context.Background(), source, testJobPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target != "my-e2e" {
+		t.Errorf("expected target %q, got %q", "my-e2e", target)
+	}
+}
@@ -1,55 +1,107 @@
 package prow
 
 import (
+	"path"
+	"sync"
+	"time"
+
 	"cloud.google.com/go/storage"
 )
 
 const (
-	// The name of the openshift-ci step where the "createReport" command is used
-	reportStepName    = "redhat-appstudio-report"
-	bucketName        = "test-platform-results"
-	prowJobYAMLPrefix = "https://prow.ci.openshift.org/prowjob?prowjob="
+	// DefaultConcurrency is the default number of files downloaded in parallel
+	DefaultConcurrency = 8
+	// DefaultTimeout is the default deadline for scanning artifacts of a Prow job
+	DefaultTimeout = 2 * time.Minute
+	// DefaultMaxRetries is the default number of retries of a failed download
+	DefaultMaxRetries = 3
+
+	// initial delay between retries of a failed download
+	retryBackoff = time.Second
 )
 
-// ArtifactScanner is used for initializing
-// GCS client and scanning and storing
-// files found in defined storage
+// ArtifactScanner is used for scanning and storing
+// files found in defined storage (ArtifactSource)
 type ArtifactScanner struct {
-	bucketHandle *storage.BucketHandle
-	Client       *storage.Client
-	config       ScannerConfig
+	// Client is the GCS client used by the scanner if the artifacts are scanned from a GCS bucket (nil otherwise).
+	//
+	// Deprecated: the scanner reads artifacts via ScannerConfig.Source - use GCSSource to access the bucket
+	Client *storage.Client
+	source ArtifactSource
+	config ScannerConfig
+	// mu guards ArtifactStepMap while the artifacts are being downloaded concurrently
+	mu sync.Mutex
 	/* Example:
 	{
 	  "gather-extra": {"build-log.txt": {Content: "<content>", FullName: "/full/gcs/path/build-log.txt"}, "finished.json": ...},
-	  "e2e-tests": {"build-log.txt": ...},
+	  "e2e-tests": {"build-log.txt": ..., "artifacts/junit/junit.xml": ..., "artifacts/suite-b/junit.xml": ...},
 	}
 	*/
 	ArtifactStepMap         map[ArtifactStepName]ArtifactFilenameMap
 	ArtifactDirectoryPrefix string
+	// ProwJobURL and JobName of the scanned job - available once the scan determines them
+	ProwJobURL string
+	JobName    string
 }
 
 // ScannerConfig contains fields required
 // for scaning files with ArtifactScanner
 type ScannerConfig struct {
+	// FileNameFilter are regular expressions selecting the files to scan - they're matched against the path
+	// of the file relative to the artifact directory, e.g. "redhat-appstudio-e2e/artifacts/junit.xml"
 	FileNameFilter []string
 	ProwJobID      string
 	ProwJobURL     string
 	StepsToSkip    []string
+	// JobTargetRules are used for determining the openshift-ci target of the job - defaults to DefaultJobTargetRules
+	JobTargetRules []JobTargetRule
+	// MaxArtifactSize is the maximum number of bytes of each file kept in memory (Artifact.Content) - 0 means no limit
+	MaxArtifactSize int64
+	// TailFileNameFilter defines files (e.g. logs) for which the last MaxArtifactSize bytes are kept instead of the first ones
+	TailFileNameFilter []string
+	// SpoolDir is the directory for storing full content of files bigger than MaxArtifactSize - if empty,
+	// the full content is read from the Source again when requested
+	SpoolDir string
+	// Concurrency is the maximum number of files downloaded in parallel - defaults to DefaultConcurrency
+	Concurrency int
+	// Timeout is the deadline for the whole scan (including all downloads) - defaults to DefaultTimeout
+	Timeout time.Duration
+	// MaxRetries is the number of retries of a download failed with a transient error - defaults to DefaultMaxRetries
+	MaxRetries int
+	// Instance is the Prow deployment the job ran in - defaults to DefaultInstance
+	Instance Instance
+	// Source is the storage to scan the artifacts from - defaults to the GCS bucket of the Instance
+	Source ArtifactSource
 }
 
 // ArtifactStepName represents the openshift-ci step name
 type ArtifactStepName string
 
-// ArtifactFilenameMap - e.g. "build-log.txt": {Content: "<file-content>", FullName: "/full/gcs/path/build-log.txt"}
+// ArtifactFilenameMap - e.g. "build-log.txt": {Content: "<file-content>", FullName: "/full/gcs/path/build-log.txt"},
+// "artifacts/junit.xml": {Content: "<file-content>", FullName: "/full/gcs/path/artifacts/junit.xml"}
 type ArtifactFilenameMap map[ArtifactFilename]Artifact
 
-// ArtifactFilename represents the name of the file (including file extension)
+// ArtifactFilename represents the slash-separated path of the file relative to the step's directory
+// (including file extension), e.g. "build-log.txt" or "artifacts/junit/junit.xml"
 type ArtifactFilename string
 
-// Artifact stores the full name of the artifact (in GCS) and the content of the file
+// Base returns the name of the file without its parent directories, e.g. "junit.xml"
+func (f ArtifactFilename) Base() string {
+	return path.Base(string(f))
+}
+
+// Artifact stores the full name of the artifact (in the ArtifactSource) and the content of the file
 type Artifact struct {
+	// Content is the content of the file - it contains only a part of the file if Truncated is true
 	Content  string
 	FullName string
+	// Size is the full size of the file in bytes
+	Size int64
+	// Truncated is true if the file is bigger than ScannerConfig.MaxArtifactSize - use Open() to read the full content
+	Truncated bool
+
+	spoolPath string
+	source    ArtifactSource
 }
 
 // OpenshiftJobSpec represents the Openshift job spec data
@@ -0,0 +1,290 @@
+package reportportal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const apiPath = "/api/v1"
+
+// Item types of Report Portal test items
+const (
+	SuiteItemType = "SUITE"
+	StepItemType  = "STEP"
+)
+
+// Statuses of finished Report Portal test items
+const (
+	PassedStatus  = "PASSED"
+	FailedStatus  = "FAILED"
+	SkippedStatus = "SKIPPED"
+	// InterruptedStatus is the status of items whose upload failed before all their children and logs were reported
+	InterruptedStatus = "INTERRUPTED"
+)
+
+// Log levels of Report Portal log entries
+const (
+	InfoLevel  = "info"
+	ErrorLevel = "error"
+)
+
+// Client is a minimal client of the Report Portal API (v1) used for reporting test results
+type Client struct {
+	BaseURL string
+	Project string
+	// Token is the API key (access token) of the user reporting the results
+	Token string
+
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client of the Report Portal instance available on the given URL, reporting into the given project
+func NewClient(baseURL, project, token string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Project:    project,
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// Timestamp is the time sent to Report Portal as milliseconds since the epoch
+type Timestamp time.Time
+
+// MarshalJSON implements json.Marshaler
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Time(t).UnixMilli())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	var ms int64
+	if err := json.Unmarshal(data, &ms); err != nil {
+		return err
+	}
+	*t = Timestamp(time.UnixMilli(ms).UTC())
+	return nil
+}
+
+// Attribute is a key-value attribute of a launch or a test item (the key is optional)
+type Attribute struct {
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value"`
+}
+
+// StartLaunchRQ is the request for starting a launch
+type StartLaunchRQ struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	StartTime   Timestamp   `json:"startTime"`
+	Attributes  []Attribute `json:"attributes,omitempty"`
+}
+
+// FinishLaunchRS is the response to finishing a launch
+type FinishLaunchRS struct {
+	ID     string `json:"id"`
+	Number int    `json:"number"`
+	// Link is the URL of the launch in the Report Portal UI
+	Link string `json:"link"`
+}
+
+// StartItemRQ is the request for starting a test item
+type StartItemRQ struct {
+	LaunchID    string      `json:"launchUuid"`
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Description string      `json:"description,omitempty"`
+	CodeRef     string      `json:"codeRef,omitempty"`
+	StartTime   Timestamp   `json:"startTime"`
+	Attributes  []Attribute `json:"attributes,omitempty"`
+}
+
+// FinishItemRQ is the request for finishing a test item
+type FinishItemRQ struct {
+	LaunchID string    `json:"launchUuid"`
+	EndTime  Timestamp `json:"endTime"`
+	Status   string    `json:"status,omitempty"`
+	// Issue marks the failure (or the skip) - skipped items without an issue are considered "to investigate"
+	Issue *Issue `json:"issue,omitempty"`
+}
+
+// Issue is the defect type of a finished test item
+type Issue struct {
+	IssueType string `json:"issueType"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// NotIssue is the issue type of items that don't need an investigation (e.g. skipped items)
+const NotIssue = "NOT_ISSUE"
+
+// LogRQ is the request for saving a log entry of a test item (or of the launch, if ItemID is empty)
+type LogRQ struct {
+	LaunchID string    `json:"launchUuid"`
+	ItemID   string    `json:"itemUuid,omitempty"`
+	Time     Timestamp `json:"time"`
+	Message  string    `json:"message"`
+	Level    string    `json:"level"`
+	File     *LogFile  `json:"file,omitempty"`
+}
+
+// LogFile references the attachment of the log entry by its name
+type LogFile struct {
+	Name string `json:"name"`
+}
+
+// Attachment is a file attached to a log entry
+type Attachment struct {
+	Name        string
+	ContentType string
+	Content     []byte
+}
+
+type entryCreatedRS struct {
+	ID string `json:"id"`
+}
+
+// StartLaunch starts a launch and returns its UUID
+func (c *Client) StartLaunch(ctx context.Context, rq StartLaunchRQ) (string, error) {
+	var created entryCreatedRS
+	if err := c.do(ctx, http.MethodPost, "/launch", rq, &created); err != nil {
+		return "", fmt.Errorf("failed to start launch %q: %+v", rq.Name, err)
+	}
+	return created.ID, nil
+}
+
+// FinishLaunch finishes the launch with the given UUID
+func (c *Client) FinishLaunch(ctx context.Context, launchID string, endTime time.Time) (*FinishLaunchRS, error) {
+	finished := &FinishLaunchRS{}
+	rq := map[string]Timestamp{"endTime": Timestamp(endTime)}
+	if err := c.do(ctx, http.MethodPut, "/launch/"+url.PathEscape(launchID)+"/finish", rq, finished); err != nil {
+		return nil, fmt.Errorf("failed to finish launch %s: %+v", launchID, err)
+	}
+	return finished, nil
+}
+
+// StartItem starts a test item - a root item if parentID is empty, otherwise a child of the given item - and returns its UUID
+func (c *Client) StartItem(ctx context.Context, parentID string, rq StartItemRQ) (string, error) {
+	path := "/item"
+	if parentID != "" {
+		path += "/" + url.PathEscape(parentID)
+	}
+	var created entryCreatedRS
+	if err := c.do(ctx, http.MethodPost, path, rq, &created); err != nil {
+		return "", fmt.Errorf("failed to start item %q: %+v", rq.Name, err)
+	}
+	return created.ID, nil
+}
+
+// FinishItem finishes the test item with the given UUID
+func (c *Client) FinishItem(ctx context.Context, itemID string, rq FinishItemRQ) error {
+	if err := c.do(ctx, http.MethodPut, "/item/"+url.PathEscape(itemID), rq, nil); err != nil {
+		return fmt.Errorf("failed to finish item %s: %+v", itemID, err)
+	}
+	return nil
+}
+
+// Log saves the log entry
+func (c *Client) Log(ctx context.Context, rq LogRQ) error {
+	if err := c.do(ctx, http.MethodPost, "/log", rq, nil); err != nil {
+		return fmt.Errorf("failed to save log: %+v", err)
+	}
+	return nil
+}
+
+// LogWithAttachment saves the log entry with the attached file (multipart request)
+func (c *Client) LogWithAttachment(ctx context.Context, rq LogRQ, attachment Attachment) error {
+	rq.File = &LogFile{Name: attachment.Name}
+	data, err := json.Marshal([]LogRQ{rq})
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	jsonPart, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Disposition": {`form-data; name="json_request_part"`},
+		"Content-Type":        {"application/json"},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := jsonPart.Write(data); err != nil {
+		return err
+	}
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+	filePart, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Disposition": {fmt.Sprintf(`form-data; name="file"; filename=%q`, attachment.Name)},
+		"Content-Type":        {contentType},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := filePart.Write(attachment.Content); err != nil {
+		return err
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	if err := c.send(ctx, http.MethodPost, "/log", &body, mw.FormDataContentType(), nil); err != nil {
+		return fmt.Errorf("failed to save log with attachment %s: %+v", attachment.Name, err)
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return c.send(ctx, method, path, bytes.NewReader(data), "application/json", out)
+}
+
+func (c *Client) send(ctx context.Context, method, path string, body io.Reader, contentType string, out interface{}) error {
+	path = "/" + url.PathEscape(c.Project) + path
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+apiPath+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", contentType)
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %s: %s", method, path, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("cannot decode response of %s %s: %+v", method, path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+package reportportal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+const testProject = "konflux"
+
+// fakeItem is a test item stored by fakeReportPortal
+type fakeItem struct {
+	StartItemRQ
+	Parent string
+	Finish *FinishItemRQ
+	Logs   []string
+	Files  map[string]string
+}
+
+// fakeReportPortal is a local stand-in of the Report Portal API keeping the launch and its items in memory
+type fakeReportPortal struct {
+	mu         sync.Mutex
+	launch     *StartLaunchRQ
+	finished   bool
+	items      map[string]*fakeItem
+	order      []string
+	launchLogs map[string]string
+	auth       []string
+	// failItems makes starting items fail
+	failItems bool
+	// failItemsAfter makes starting items fail once the given number of items was started (if > 0)
+	failItemsAfter int
+	// failLogs makes saving log entries fail
+	failLogs bool
+	// cancel is called (and starting the item fails) once an item is started, e.g. to cancel the context of the upload
+	cancel context.CancelFunc
+}
+
+func (rp *fakeReportPortal) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	rp.auth = append(rp.auth, r.Header.Get("Authorization"))
+
+	path, ok := strings.CutPrefix(r.URL.Path, apiPath+"/"+testProject)
+	if !ok {
+		http.Error(w, "unknown project", http.StatusNotFound)
+		return
+	}
+	switch {
+	case r.Method == http.MethodPost && path == "/launch":
+		rp.launch = &StartLaunchRQ{}
+		rp.decode(w, r, rp.launch)
+		fmt.Fprint(w, `{"id": "launch-1", "number": 7}`)
+	case r.Method == http.MethodPut && path == "/launch/launch-1/finish":
+		rp.finished = true
+		fmt.Fprint(w, `{"id": "launch-1", "number": 7, "link": "http://rp/ui/#konflux/launches/all/launch-1"}`)
+	case r.Method == http.MethodPost && strings.HasPrefix(path, "/item"):
+		if rp.cancel != nil {
+			rp.cancel()
+		}
+		if rp.failItems || rp.cancel != nil || (rp.failItemsAfter > 0 && len(rp.items) >= rp.failItemsAfter) {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		item := &fakeItem{Parent: strings.TrimPrefix(strings.TrimPrefix(path, "/item"), "/"), Files: map[string]string{}}
+		rp.decode(w, r, &item.StartItemRQ)
+		id := fmt.Sprintf("item-%d", len(rp.items)+1)
+		rp.items[id], rp.order = item, append(rp.order, id)
+		fmt.Fprintf(w, `{"id": %q}`, id)
+	case r.Method == http.MethodPut && strings.HasPrefix(path, "/item/"):
+		item := rp.items[strings.TrimPrefix(path, "/item/")]
+		item.Finish = &FinishItemRQ{}
+		rp.decode(w, r, item.Finish)
+		fmt.Fprint(w, `{"message": "finished"}`)
+	case r.Method == http.MethodPost && path == "/log":
+		if rp.failLogs {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		rp.saveLog(w, r)
+	default:
+		http.Error(w, "unexpected request "+r.Method+" "+path, http.StatusBadRequest)
+	}
+}
+
+func (rp *fakeReportPortal) decode(w http.ResponseWriter, r *http.Request, v any) {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+func (rp *fakeReportPortal) saveLog(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		var rq LogRQ
+		rp.decode(w, r, &rq)
+		rp.items[rq.ItemID].Logs = append(rp.items[rq.ItemID].Logs, rq.Level+": "+rq.Message)
+		fmt.Fprint(w, `{"id": "log"}`)
+		return
+	}
+
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var rqs []LogRQ
+	if err := json.Unmarshal([]byte(r.MultipartForm.Value["json_request_part"][0]), &rqs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fh := r.MultipartForm.File["file"][0]
+	f, _ := fh.Open()
+	content, _ := io.ReadAll(f)
+	if rqs[0].File == nil || rqs[0].File.Name != fh.Filename {
+		http.Error(w, "file name doesn't match", http.StatusBadRequest)
+		return
+	}
+	if rqs[0].ItemID == "" {
+		rp.launchLogs[fh.Filename] = string(content)
+	} else {
+		item := rp.items[rqs[0].ItemID]
+		item.Logs = append(item.Logs, rqs[0].Level+": "+rqs[0].Message)
+		item.Files[fh.Filename] = string(content)
+	}
+	fmt.Fprint(w, `{"responses": [{"id": "log"}]}`)
+}
+
+func testSuites() *reporters.JUnitTestSuites {
+	return &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{
+		{
+			Name:       "build-service",
+			Timestamp:  "2024-05-01T10:00:00",
+			Properties: reporters.JUnitProperties{Properties: []reporters.JUnitProperty{{Name: "html-report-link", Value: "https://prow/report.html"}}},
+			TestCases: []reporters.JUnitTestCase{
+				{Name: "creates a build", Status: "passed", Time: 10},
+				{Name: "deletes a build", Status: "failed", Time: 5, Failure: &reporters.JUnitFailure{Message: "unexpected error", Description: "at build_test.go:42"}, SystemErr: strings.Repeat("x", 100)},
+			},
+		},
+		{
+			Name:      "openshift-ci job",
+			TestCases: []reporters.JUnitTestCase{{Name: "pending", Status: "pending", Skipped: &reporters.JUnitSkipped{}}},
+		},
+	}}
+}
+
+// TestUpload tests uploading the JUnit report as a launch with suites, steps, logs and attachments
+func TestUpload(t *testing.T) {
+	rp := &fakeReportPortal{items: map[string]*fakeItem{}, launchLogs: map[string]string{}}
+	server := httptest.NewServer(rp)
+	defer server.Close()
+
+	opts := UploadOptions{
+		LaunchName:  "e2e",
+		Attributes:  []Attribute{{Key: "repo", Value: "org/repo"}},
+		Attachments: []Attachment{{Name: "junit-summary.html", ContentType: "text/html", Content: []byte("<html/>")}},
+		MaxLogSize:  50,
+	}
+	finished, err := Upload(context.Background(), NewClient(server.URL+"/", testProject, "secret"), testSuites(), opts)
+	if err != nil {
+		t.Fatalf("failed to upload results: %v", err)
+	}
+	if finished.Link != "http://rp/ui/#konflux/launches/all/launch-1" || !rp.finished {
+		t.Errorf("expected the launch to be finished, got %+v", finished)
+	}
+	if rp.auth[0] != "Bearer secret" {
+		t.Errorf("expected Bearer authentication, got %q", rp.auth[0])
+	}
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	if rp.launch.Name != "e2e" || time.Time(rp.launch.StartTime) != start || len(rp.launch.Attributes) != 1 {
+		t.Errorf("unexpected launch %+v", rp.launch)
+	}
+	if rp.launchLogs["junit-summary.html"] != "<html/>" {
+		t.Errorf("expected the HTML report to be attached to the launch, got %v", rp.launchLogs)
+	}
+
+	if len(rp.order) != 5 {
+		t.Fatalf("expected 2 suites and 3 steps, got %d items", len(rp.order))
+	}
+	suite, passed, failed, otherSuite, skipped := rp.items["item-1"], rp.items["item-2"], rp.items["item-3"], rp.items["item-4"], rp.items["item-5"]
+	if suite.Type != SuiteItemType || suite.Parent != "" || !strings.Contains(suite.Description, "**html-report-link**: https://prow/report.html") {
+		t.Errorf("unexpected suite %+v", suite)
+	}
+	if passed.Type != StepItemType || passed.Parent != "item-1" || passed.Finish.Status != PassedStatus || len(passed.Logs) != 0 {
+		t.Errorf("unexpected passed step %+v", passed)
+	}
+	// the steps are started one after another, so that their durations are kept
+	if time.Time(failed.StartTime) != start.Add(10*time.Second) {
+		t.Errorf("expected the second step to start 10s after the launch, got %v", time.Time(failed.StartTime))
+	}
+	if failed.Finish.Status != FailedStatus || len(failed.Logs) != 2 || failed.Logs[0] != "error: unexpected error\nat build_test.go:42" {
+		t.Errorf("unexpected failed step %+v", failed)
+	}
+	if failed.Files["system-err.log"] != strings.Repeat("x", 100) {
+		t.Errorf("expected system-err exceeding the max log size to be attached, got %v", failed.Files)
+	}
+	if otherSuite.Finish.Status != "" {
+		t.Errorf("expected the status of the suite to be computed by Report Portal, got %q", otherSuite.Finish.Status)
+	}
+	if skipped.Finish.Status != SkippedStatus || skipped.Finish.Issue == nil || skipped.Finish.Issue.IssueType != NotIssue {
+		t.Errorf("expected skipped step not to be investigated, got %+v", skipped.Finish)
+	}
+}
+
+// TestUploadFinishesLaunchOnError tests that the launch isn't left in progress when uploading items fails
+func TestUploadFinishesLaunchOnError(t *testing.T) {
+	rp := &fakeReportPortal{items: map[string]*fakeItem{}, launchLogs: map[string]string{}, failItems: true}
+	server := httptest.NewServer(rp)
+	defer server.Close()
+
+	_, err := Upload(context.Background(), NewClient(server.URL, testProject, "secret"), testSuites(), UploadOptions{LaunchName: "e2e"})
+	if err == nil || !strings.Contains(err.Error(), "500 Internal Server Error") {
+		t.Errorf("expected an error from starting the item, got %v", err)
+	}
+	if !rp.finished {
+		t.Errorf("expected the launch to be finished")
+	}
+}
+
+// TestUploadInterruptsOpenItemsOnError tests that items left open by a failed upload are finished as interrupted
+func TestUploadInterruptsOpenItemsOnError(t *testing.T) {
+	tests := []struct {
+		name string
+		rp   *fakeReportPortal
+		// want are the statuses of the started items
+		want string
+	}{
+		{"starting an item fails", &fakeReportPortal{failItemsAfter: 2}, "item-1=INTERRUPTED item-2=PASSED"},
+		{"saving a log fails", &fakeReportPortal{failLogs: true}, "item-1=INTERRUPTED item-2=PASSED item-3=INTERRUPTED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rp.items, tt.rp.launchLogs = map[string]*fakeItem{}, map[string]string{}
+			server := httptest.NewServer(tt.rp)
+			defer server.Close()
+
+			_, err := Upload(context.Background(), NewClient(server.URL, testProject, "secret"), testSuites(), UploadOptions{LaunchName: "e2e"})
+			if err == nil || !strings.Contains(err.Error(), "500 Internal Server Error") {
+				t.Errorf("expected an error from the server, got %v", err)
+			}
+			var got []string
+			for _, id := range tt.rp.order {
+				status := "IN_PROGRESS"
+				if finish := tt.rp.items[id].Finish; finish != nil {
+					status = finish.Status
+				}
+				got = append(got, id+"="+status)
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("expected items %q, got %q", tt.want, strings.Join(got, " "))
+			}
+			if !tt.rp.finished {
+				t.Errorf("expected the launch to be finished")
+			}
+		})
+	}
+}
+
+// TestUploadFinishesLaunchWhenCancelled tests that the launch is finished even if the context of the upload is cancelled
+func TestUploadFinishesLaunchWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rp := &fakeReportPortal{items: map[string]*fakeItem{}, launchLogs: map[string]string{}, cancel: cancel}
+	server := httptest.NewServer(rp)
+	defer server.Close()
+
+	_, err := Upload(ctx, NewClient(server.URL, testProject, "secret"), testSuites(), UploadOptions{LaunchName: "e2e"})
+	if err == nil {
+		t.Errorf("expected an error of the cancelled upload")
+	}
+	if !rp.finished {
+		t.Errorf("expected the launch to be finished, got %v", err)
+	}
+}
+
+// TestAttributesFromJobSpec tests describing the openshift-ci job by launch attributes
+func TestAttributesFromJobSpec(t *testing.T) {
+	spec := &prow.OpenshiftJobSpec{Type: "presubmit", Job: "pull-ci-e2e", Refs: prow.Refs{Organization: "konflux-ci", Repo: "e2e-tests", Pulls: []prow.Pull{{Number: 42, Author: "dev", SHA: "abc"}}}}
+	var got []string
+	for _, a := range AttributesFromJobSpec(spec) {
+		got = append(got, a.Key+"="+a.Value)
+	}
+	want := "job_name=pull-ci-e2e job_type=presubmit repo=konflux-ci/e2e-tests pr=42 pr_author=dev commit=abc"
+	if strings.Join(got, " ") != want {
+		t.Errorf("expected attributes %q, got %q", want, strings.Join(got, " "))
+	}
+	if AttributesFromJobSpec(nil) != nil {
+		t.Errorf("expected no attributes without job spec")
+	}
+}
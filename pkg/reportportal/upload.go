@@ -0,0 +1,245 @@
+package reportportal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/konflux-ci/qe-tools/pkg/junitutil"
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+	ginkgoTypes "github.com/onsi/ginkgo/v2/types"
+	"k8s.io/klog/v2"
+)
+
+// DefaultMaxLogSize is the default size (in bytes) of the test case's output above which
+// the output is uploaded as an attachment instead of a log message
+const DefaultMaxLogSize = 64 * 1024
+
+// finishTimeout bounds finishing the launch and the items left open by a failed upload - they are finished
+// even if the upload was cancelled or timed out
+const finishTimeout = 30 * time.Second
+
+// junitTimestampLayout is the layout of timestamps of JUnit suites produced by Ginkgo
+const junitTimestampLayout = "2006-01-02T15:04:05"
+
+// UploadOptions configure uploading a JUnit report as a Report Portal launch
+type UploadOptions struct {
+	LaunchName  string
+	Description string
+	Attributes  []Attribute
+	// StartTime of the launch - defaults to the timestamp of the first suite, or to the current time
+	// minus the duration of all suites if the suite doesn't have a timestamp
+	StartTime time.Time
+	// Attachments are attached to the launch, e.g. the HTML report
+	Attachments []Attachment
+	// MaxLogSize is the size of the test case's output above which the output is uploaded as an attachment
+	// (DefaultMaxLogSize is used if <= 0)
+	MaxLogSize int
+}
+
+// Upload reports the JUnit report as a new launch - every JUnit suite is reported as a suite item with
+// a step item for each of its test cases, including failure messages and the output of the test cases.
+// JUnit reports don't contain start times of test cases, so they are computed from their durations.
+// The launch is finished even if uploading any of its items failed - the items left open are finished as interrupted
+func Upload(ctx context.Context, c *Client, suites *reporters.JUnitTestSuites, opts UploadOptions) (*FinishLaunchRS, error) {
+	if opts.MaxLogSize <= 0 {
+		opts.MaxLogSize = DefaultMaxLogSize
+	}
+	start := launchStartTime(suites, opts.StartTime)
+
+	launchID, err := c.StartLaunch(ctx, StartLaunchRQ{Name: opts.LaunchName, Description: opts.Description, StartTime: Timestamp(start), Attributes: opts.Attributes})
+	if err != nil {
+		return nil, err
+	}
+	klog.Infof("started Report Portal launch %q (%s)", opts.LaunchName, launchID)
+
+	u := uploader{client: c, launchID: launchID, maxLogSize: opts.MaxLogSize, cursor: start}
+	uploadErr := u.uploadSuites(ctx, suites, opts.Attachments)
+
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer cancel()
+	finished, err := c.FinishLaunch(finishCtx, launchID, u.cursor)
+	if err := errors.Join(uploadErr, err); err != nil {
+		return nil, err
+	}
+	return finished, nil
+}
+
+// uploader uploads items of a single launch
+type uploader struct {
+	client     *Client
+	launchID   string
+	maxLogSize int
+	// cursor is the time the next item starts at
+	cursor time.Time
+}
+
+func (u *uploader) uploadSuites(ctx context.Context, suites *reporters.JUnitTestSuites, attachments []Attachment) error {
+	for _, attachment := range attachments {
+		rq := LogRQ{LaunchID: u.launchID, Time: Timestamp(u.cursor), Message: attachment.Name, Level: InfoLevel}
+		if err := u.client.LogWithAttachment(ctx, rq, attachment); err != nil {
+			return err
+		}
+	}
+	for _, suite := range suites.TestSuites {
+		if err := u.uploadSuite(ctx, suite); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *uploader) uploadSuite(ctx context.Context, suite reporters.JUnitTestSuite) error {
+	suiteID, err := u.client.StartItem(ctx, "", StartItemRQ{
+		LaunchID:    u.launchID,
+		Name:        suite.Name,
+		Type:        SuiteItemType,
+		Description: suiteDescription(suite),
+		CodeRef:     suite.Package,
+		StartTime:   Timestamp(u.cursor),
+	})
+	if err != nil {
+		return err
+	}
+	for _, tc := range suite.TestCases {
+		if err := u.uploadTestCase(ctx, suiteID, tc); err != nil {
+			return u.interrupt(ctx, suiteID, err)
+		}
+	}
+	// the status of the suite is computed by Report Portal from the statuses of its test cases
+	return u.client.FinishItem(ctx, suiteID, FinishItemRQ{LaunchID: u.launchID, EndTime: Timestamp(u.cursor)})
+}
+
+func (u *uploader) uploadTestCase(ctx context.Context, suiteID string, tc reporters.JUnitTestCase) error {
+	start := u.cursor
+	itemID, err := u.client.StartItem(ctx, suiteID, StartItemRQ{
+		LaunchID:  u.launchID,
+		Name:      tc.Name,
+		Type:      StepItemType,
+		CodeRef:   tc.Classname,
+		StartTime: Timestamp(start),
+	})
+	if err != nil {
+		return err
+	}
+
+	logs := []struct {
+		name, content, level string
+	}{
+		{"failure", junitutil.FailureDetails(tc), ErrorLevel},
+		{"system-out", tc.SystemOut, InfoLevel},
+		{"system-err", tc.SystemErr, InfoLevel},
+	}
+	for _, l := range logs {
+		if strings.TrimSpace(l.content) == "" {
+			continue
+		}
+		rq := LogRQ{LaunchID: u.launchID, ItemID: itemID, Time: Timestamp(start), Message: l.content, Level: l.level}
+		if len(l.content) <= u.maxLogSize {
+			err = u.client.Log(ctx, rq)
+		} else {
+			rq.Message = fmt.Sprintf("%s (%d bytes, see the attachment)", l.name, len(l.content))
+			err = u.client.LogWithAttachment(ctx, rq, Attachment{Name: l.name + ".log", ContentType: "text/plain", Content: []byte(l.content)})
+		}
+		if err != nil {
+			return u.interrupt(ctx, itemID, err)
+		}
+	}
+
+	u.cursor = start.Add(time.Duration(tc.Time * float64(time.Second)))
+	finish := FinishItemRQ{LaunchID: u.launchID, EndTime: Timestamp(u.cursor), Status: testCaseStatus(tc)}
+	if finish.Status == SkippedStatus {
+		finish.Issue = &Issue{IssueType: NotIssue}
+	}
+	return u.client.FinishItem(ctx, itemID, finish)
+}
+
+// interrupt finishes the item whose upload failed, so that it isn't left in progress
+func (u *uploader) interrupt(ctx context.Context, itemID string, err error) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer cancel()
+	finishErr := u.client.FinishItem(ctx, itemID, FinishItemRQ{LaunchID: u.launchID, EndTime: Timestamp(u.cursor), Status: InterruptedStatus})
+	return errors.Join(err, finishErr)
+}
+
+// AttributesFromJobSpec returns launch attributes describing the openshift-ci job (job name and type, repository, PR)
+func AttributesFromJobSpec(spec *prow.OpenshiftJobSpec) []Attribute {
+	if spec == nil {
+		return nil
+	}
+	var attributes []Attribute
+	add := func(key, value string) {
+		if value != "" {
+			attributes = append(attributes, Attribute{Key: key, Value: value})
+		}
+	}
+	add("job_name", spec.Job)
+	add("job_type", spec.Type)
+	repo := spec.Refs.Repo
+	if spec.Refs.Organization != "" && repo != "" {
+		repo = spec.Refs.Organization + "/" + repo
+	}
+	add("repo", repo)
+	if len(spec.Refs.Pulls) > 0 {
+		pull := spec.Refs.Pulls[0]
+		add("pr", strconv.Itoa(pull.Number))
+		add("pr_author", pull.Author)
+		add("commit", pull.SHA)
+	}
+	return attributes
+}
+
+// launchStartTime returns the start time of the launch
+func launchStartTime(suites *reporters.JUnitTestSuites, start time.Time) time.Time {
+	if !start.IsZero() {
+		return start
+	}
+	for _, suite := range suites.TestSuites {
+		if t, err := time.Parse(junitTimestampLayout, suite.Timestamp); err == nil {
+			return t
+		}
+	}
+	var total float64
+	for _, suite := range suites.TestSuites {
+		for _, tc := range suite.TestCases {
+			total += tc.Time
+		}
+	}
+	return time.Now().Add(-time.Duration(total * float64(time.Second)))
+}
+
+// suiteDescription lists the properties of the suite (e.g. links to artifacts added by "prowjob create-report")
+func suiteDescription(suite reporters.JUnitTestSuite) string {
+	properties := append([]reporters.JUnitProperty{}, suite.Properties.Properties...)
+	sort.SliceStable(properties, func(i, j int) bool {
+		return properties[i].Name < properties[j].Name
+	})
+	var lines []string
+	for _, p := range properties {
+		lines = append(lines, fmt.Sprintf("* **%s**: %s", p.Name, p.Value))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// testCaseStatus returns the Report Portal status of the test case reported by Ginkgo (or another JUnit producer)
+func testCaseStatus(tc reporters.JUnitTestCase) string {
+	switch {
+	case tc.Failure != nil || tc.Error != nil:
+		return FailedStatus
+	case tc.Skipped != nil:
+		return SkippedStatus
+	}
+	switch tc.Status {
+	case ginkgoTypes.SpecStateSkipped.String(), ginkgoTypes.SpecStatePending.String(), "disabled":
+		return SkippedStatus
+	case ginkgoTypes.SpecStateFailed.String(), ginkgoTypes.SpecStatePanicked.String(), ginkgoTypes.SpecStateTimedout.String(),
+		ginkgoTypes.SpecStateInterrupted.String(), ginkgoTypes.SpecStateAborted.String():
+		return FailedStatus
+	}
+	return PassedStatus
+}
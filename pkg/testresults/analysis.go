@@ -0,0 +1,75 @@
+package testresults
+
+import (
+	"github.com/konflux-ci/qe-tools/pkg/classifier"
+	"github.com/konflux-ci/qe-tools/pkg/junitdiff"
+	"github.com/konflux-ci/qe-tools/pkg/knownissues"
+)
+
+// Analysis is the format-independent result of the test results analysis
+type Analysis struct {
+	FailureType FailureType `json:"failureType"`
+	Summary     string      `json:"summary"`
+	// Classification of the failure without JUnit report (cluster provisioning or test run failure)
+	Classification *classifier.Classification `json:"classification,omitempty"`
+	// Log is the excerpt of the log of the failure without JUnit report
+	Log         string `json:"log,omitempty"`
+	LogPartial  bool   `json:"logPartial,omitempty"`
+	FullLogsURL string `json:"fullLogsURL,omitempty"`
+
+	FailedTestCases []AnalyzedTestCase `json:"failedTestCases,omitempty"`
+	// KnownFailures are the failed test cases matching known issues
+	KnownFailures []AnalyzedTestCase `json:"knownFailures,omitempty"`
+	// Diff with the results of the baseline run
+	Diff *junitdiff.Diff `json:"diff,omitempty"`
+}
+
+// AnalyzedTestCase represents a failed test case within the Analysis
+type AnalyzedTestCase struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+	// MessagePartial is set if the message is an excerpt of the log of the test case (e.g. of a timed out spec)
+	MessagePartial bool `json:"messagePartial,omitempty"`
+	// File is the path of the JUnit file the test case came from
+	File           string                     `json:"file,omitempty"`
+	Classification *classifier.Classification `json:"classification,omitempty"`
+	KnownIssues    []knownissues.Issue        `json:"knownIssues,omitempty"`
+}
+
+// Analyze returns the analysis of the collected test results
+func (f FailedTestCasesReport) Analyze() Analysis {
+	a := Analysis{FailureType: f.FailureType, Summary: summaryForFailureType(f.FailureType), FullLogsURL: f.FullLogsURL, Diff: f.Diff}
+	if c, ok := f.Classify(nil); ok {
+		a.Classification = &c
+	}
+
+	switch f.FailureType {
+	case ClusterCreationFailure:
+		a.Log, a.LogPartial = f.excerpt(f.ClusterProvisionLog)
+	case TestRunFailure:
+		a.Log, a.LogPartial = f.excerpt(f.E2ETestLog)
+	case TestCaseFailure:
+		for _, ftc := range f.GetFailedTestCasesWithFiles() {
+			tc := ftc.JUnitTestCase
+			analyzed := AnalyzedTestCase{Name: tc.Name, Status: tc.Status, File: ftc.File}
+			switch {
+			case tc.Status == "timedout":
+				analyzed.Message, analyzed.MessagePartial = f.excerpt(tc.SystemErr)
+			case tc.Failure != nil:
+				analyzed.Message = tc.Failure.Message
+			default:
+				analyzed.Message = tc.Error.Message
+			}
+			if c, ok := f.Classify(&tc); ok {
+				analyzed.Classification = &c
+			}
+			if analyzed.KnownIssues = f.MatchKnownIssues(tc); len(analyzed.KnownIssues) > 0 {
+				a.KnownFailures = append(a.KnownFailures, analyzed)
+				continue
+			}
+			a.FailedTestCases = append(a.FailedTestCases, analyzed)
+		}
+	}
+	return a
+}
@@ -0,0 +1,99 @@
+package testresults
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// RegexpFilePatternPrefix marks file patterns that are regular expressions, e.g. "re:junit-.*\.xml$"
+const RegexpFilePatternPrefix = "re:"
+
+// FilePattern matches the files of the given role (JUnit report, e2e test log, cluster provision log).
+// By default, the pattern is a glob matched against the file name (e.g. "junit-*.xml") or, if it contains
+// a slash, against the trailing part of the file path (e.g. "artifacts/*/junit.xml"). Patterns prefixed
+// with RegexpFilePatternPrefix are regular expressions matched anywhere within the file path
+type FilePattern struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// NewFilePattern compiles the given glob or regular expression pattern
+func NewFilePattern(pattern string) (*FilePattern, error) {
+	if expr, ok := strings.CutPrefix(pattern, RegexpFilePatternPrefix); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression in file pattern %q: %+v", pattern, err)
+		}
+		return &FilePattern{pattern: pattern, re: re}, nil
+	}
+	if pattern == "" {
+		return nil, fmt.Errorf("empty file pattern")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid glob in file pattern %q: %+v", pattern, err)
+	}
+	return &FilePattern{pattern: pattern, re: regexp.MustCompile(globToRegexp(pattern))}, nil
+}
+
+// Match reports whether the file with the given path matches the pattern
+func (p *FilePattern) Match(filePath string) bool {
+	return p.re.MatchString(filepath.ToSlash(filePath))
+}
+
+// Regexp returns the regular expression equivalent to the pattern, usable as a file name filter of artifact scanners
+func (p *FilePattern) Regexp() string {
+	return p.re.String()
+}
+
+func (p *FilePattern) String() string {
+	return p.pattern
+}
+
+// globToRegexp converts the glob to a regular expression matching the trailing part of a slash-separated path
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	sb.WriteString("(^|/)")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+			// Character classes (including the negation with '^') have the same syntax as in regular expressions
+			sb.WriteString(glob[i : i+end+2])
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
+
+// FileNameFilter returns the file name filter of artifact scanners (regular expressions matched against
+// the file path relative to the root of the artifacts) selecting the files matching any of the given file patterns
+func FileNameFilter(patterns ...string) ([]string, error) {
+	var filter []string
+	for _, pattern := range patterns {
+		p, err := NewFilePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		filter = append(filter, p.Regexp())
+	}
+	return filter, nil
+}
@@ -0,0 +1,42 @@
+package testresults
+
+import "testing"
+
+// TestFilePattern tests matching file paths by globs and regular expressions
+func TestFilePattern(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		matched   []string
+		unmatched []string
+	}{
+		{pattern: "e2e-report.xml", matched: []string{"e2e-report.xml", "step/artifacts/e2e-report.xml"}, unmatched: []string{"step/e2e-report.xml.gz", "step/my-e2e-report.xml"}},
+		{pattern: "junit-*.xml", matched: []string{"junit-a.xml", "step/junit-.xml"}, unmatched: []string{"step/junit.xml", "junit-a/b.xml"}},
+		{pattern: "artifacts/*/junit?.xml", matched: []string{"step/artifacts/suite/junit1.xml"}, unmatched: []string{"artifacts/junit1.xml", "step/artifacts/suite/junit12.xml"}},
+		{pattern: "[ej]*.xml", matched: []string{"e2e.xml", "junit.xml"}, unmatched: []string{"report.xml"}},
+		{pattern: `re:/(j?unit|e2e).*\.xml`, matched: []string{"step/junit.xml", "step/e2e-report.xml"}, unmatched: []string{"junit.xml", "step/report.xml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			p, err := NewFilePattern(tt.pattern)
+			if err != nil {
+				t.Fatalf("failed to compile pattern: %v", err)
+			}
+			for _, path := range tt.matched {
+				if !p.Match(path) {
+					t.Errorf("expected %q to match %q", tt.pattern, path)
+				}
+			}
+			for _, path := range tt.unmatched {
+				if p.Match(path) {
+					t.Errorf("expected %q not to match %q", tt.pattern, path)
+				}
+			}
+		})
+	}
+
+	for _, invalid := range []string{"", "[a.xml", "re:("} {
+		if _, err := NewFilePattern(invalid); err == nil {
+			t.Errorf("expected an error for invalid pattern %q", invalid)
+		}
+	}
+}
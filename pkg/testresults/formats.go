@@ -0,0 +1,308 @@
+package testresults
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/konflux-ci/qe-tools/pkg/classifier"
+	"github.com/slack-go/slack"
+)
+
+// Supported output formats of the analysis
+const (
+	MarkdownFormat = "markdown"
+	TextFormat     = "text"
+	JSONFormat     = "json"
+	SlackFormat    = "slack"
+	HTMLFormat     = "html"
+
+	// maxSlackListedFailures limits the number of failed test cases listed in Slack messages
+	maxSlackListedFailures = 20
+	// maxSlackMessageLength keeps the failure messages well within the limit of a Slack section (3000 characters)
+	maxSlackMessageLength = 1000
+)
+
+// Formats lists the supported output formats
+var Formats = []string{MarkdownFormat, TextFormat, JSONFormat, SlackFormat, HTMLFormat}
+
+// Formatter formats the report of the test results analysis
+type Formatter interface {
+	Format(report FailedTestCasesReport) ([]byte, error)
+	// FileExtension is the extension of the file with the formatted report, e.g. "md"
+	FileExtension() string
+}
+
+// NewFormatter returns the formatter of the given format
+func NewFormatter(format string) (Formatter, error) {
+	switch format {
+	case MarkdownFormat:
+		return MarkdownFormatter{}, nil
+	case TextFormat:
+		return TextFormatter{}, nil
+	case JSONFormat:
+		return JSONFormatter{}, nil
+	case SlackFormat:
+		return SlackFormatter{}, nil
+	case HTMLFormat:
+		return HTMLFormatter{}, nil
+	}
+	return nil, fmt.Errorf("unsupported format %q, supported formats: %s", format, strings.Join(Formats, ", "))
+}
+
+// MarkdownFormatter formats the report as GitHub-flavoured Markdown (e.g. for PR comments)
+type MarkdownFormatter struct{}
+
+// Format implements Formatter
+func (MarkdownFormatter) Format(report FailedTestCasesReport) ([]byte, error) {
+	return []byte(GetFormattedReport(report)), nil
+}
+
+// FileExtension implements Formatter
+func (MarkdownFormatter) FileExtension() string { return "md" }
+
+// TextFormatter formats the report as plain text
+type TextFormatter struct{}
+
+// Format implements Formatter
+func (TextFormatter) Format(report FailedTestCasesReport) ([]byte, error) {
+	a := report.Analyze()
+	var sb strings.Builder
+	sb.WriteString(a.Summary + "\n")
+	if a.Classification != nil {
+		fmt.Fprintf(&sb, "Failure category: %s\n", a.Classification)
+	}
+	if a.Log != "" {
+		sb.WriteString("\n" + logTitle(a) + ":\n" + a.Log + "\n")
+	}
+	if len(a.KnownFailures) > 0 && len(a.FailedTestCases) == 0 {
+		sb.WriteString("\nNo new failures found.\n")
+	}
+	for _, tc := range a.FailedTestCases {
+		sb.WriteString("\n" + textTestCase(tc))
+	}
+	if len(a.KnownFailures) > 0 {
+		sb.WriteString("\nFailed Spec(s) matching known issues:\n")
+		for _, tc := range a.KnownFailures {
+			sb.WriteString("\n" + textTestCase(tc))
+		}
+	}
+	if a.Diff != nil {
+		sb.WriteString("\n" + a.Diff.Text())
+	}
+	return []byte(sb.String()), nil
+}
+
+// FileExtension implements Formatter
+func (TextFormatter) FileExtension() string { return "txt" }
+
+func textTestCase(tc AnalyzedTestCase) string {
+	line := fmt.Sprintf("- [%s] %s", tc.Status, tc.Name)
+	if tc.File != "" {
+		line += " (from " + tc.File + ")"
+	}
+	if tc.Classification != nil {
+		line += " (category: " + tc.Classification.String() + ")"
+	}
+	if len(tc.KnownIssues) > 0 {
+		var issues []string
+		for _, issue := range tc.KnownIssues {
+			issues = append(issues, issue.String())
+		}
+		line += "\n  known issue(s): " + strings.Join(issues, ", ")
+	}
+	if tc.Message != "" {
+		line += "\n  " + strings.ReplaceAll(strings.TrimSpace(tc.Message), "\n", "\n  ")
+	}
+	return line + "\n"
+}
+
+func logTitle(a Analysis) string {
+	if a.LogPartial {
+		if a.FullLogsURL != "" {
+			return "Log excerpt (full log: " + a.FullLogsURL + ")"
+		}
+		return "Log excerpt"
+	}
+	return "Log"
+}
+
+// JSONFormatter formats the report as JSON (e.g. for bots and dashboards)
+type JSONFormatter struct{}
+
+// Format implements Formatter
+func (JSONFormatter) Format(report FailedTestCasesReport) ([]byte, error) {
+	return json.MarshalIndent(report.Analyze(), "", "    ")
+}
+
+// FileExtension implements Formatter
+func (JSONFormatter) FileExtension() string { return "json" }
+
+// SlackFormatter formats the report as a Slack message payload with Block Kit blocks ({"blocks": [...]})
+type SlackFormatter struct{}
+
+// Format implements Formatter
+func (SlackFormatter) Format(report FailedTestCasesReport) ([]byte, error) {
+	return json.MarshalIndent(map[string]any{"blocks": SlackBlocks(report.Analyze())}, "", "    ")
+}
+
+// FileExtension implements Formatter
+func (SlackFormatter) FileExtension() string { return "json" }
+
+// SlackBlocks returns the analysis as Slack message blocks
+func SlackBlocks(a Analysis) []slack.Block {
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, ":rotating_light: Test results analysis", true, false)),
+	}
+	summary := a.Summary
+	if a.Classification != nil {
+		summary += fmt.Sprintf("\n*Failure category:* %s", a.Classification)
+	}
+	blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, summary, false, false), nil, nil))
+
+	if a.Log != "" {
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "```"+slackEscape(truncateTail(a.Log, maxSlackMessageLength))+"```", false, false), nil, nil))
+	}
+	if len(a.KnownFailures) > 0 && len(a.FailedTestCases) == 0 {
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, ":white_check_mark: *No new failures found.*", false, false), nil, nil))
+	}
+	for i, tc := range a.FailedTestCases {
+		if i == maxSlackListedFailures {
+			blocks = append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("… and %d more", len(a.FailedTestCases)-maxSlackListedFailures), false, false)))
+			break
+		}
+		text := fmt.Sprintf(":arrow_right: *[%s]* %s%s", tc.Status, slackEscape(tc.Name), slackClassification(tc.Classification))
+		if tc.File != "" {
+			text += " (from `" + slackEscape(tc.File) + "`)"
+		}
+		if tc.Message != "" {
+			text += "\n```" + slackEscape(truncateTail(strings.TrimSpace(tc.Message), maxSlackMessageLength)) + "```"
+		}
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil))
+	}
+	if len(a.KnownFailures) > 0 {
+		var sb strings.Builder
+		sb.WriteString(":information_source: *Failed Spec(s) matching known issues:*\n")
+		for i, tc := range a.KnownFailures {
+			if i == maxSlackListedFailures {
+				fmt.Fprintf(&sb, "… and %d more\n", len(a.KnownFailures)-maxSlackListedFailures)
+				break
+			}
+			var issues []string
+			for _, issue := range tc.KnownIssues {
+				if issue.URL != "" {
+					issues = append(issues, fmt.Sprintf("<%s|%s>", issue.URL, issue.Key))
+				} else {
+					issues = append(issues, issue.Key)
+				}
+			}
+			fmt.Fprintf(&sb, "• %s - %s\n", slackEscape(tc.Name), strings.Join(issues, ", "))
+		}
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, sb.String(), false, false), nil, nil))
+	}
+	if a.Diff != nil {
+		text := fmt.Sprintf(":mag: *%s:* %s", slackEscape(a.Diff.Title()), a.Diff.Summary())
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil))
+	}
+	if a.FullLogsURL != "" {
+		blocks = append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("<%s|View the full logs>", a.FullLogsURL), false, false)))
+	}
+	return blocks
+}
+
+// slackEscape escapes the control characters of Slack's mrkdwn
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+func slackClassification(c *classifier.Classification) string {
+	if c == nil {
+		return ""
+	}
+	return " (category: _" + c.String() + "_)"
+}
+
+// truncateTail keeps the beginning of the text shorter than maxLength
+func truncateTail(text string, maxLength int) string {
+	if len(text) <= maxLength {
+		return text
+	}
+	return strings.ToValidUTF8(text[:maxLength], "") + "…"
+}
+
+// HTMLFormatter formats the report as a standalone HTML page
+type HTMLFormatter struct{}
+
+// Format implements Formatter
+func (HTMLFormatter) Format(report FailedTestCasesReport) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, report.Analyze()); err != nil {
+		return nil, fmt.Errorf("failed to render HTML report: %+v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// FileExtension implements Formatter
+func (HTMLFormatter) FileExtension() string { return "html" }
+
+var htmlTemplate = template.Must(template.New("analysis").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Test results analysis</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+pre { background: #f6f8fa; padding: 1em; overflow-x: auto; }
+.status { font-weight: bold; color: #c00; }
+.category, .file { color: #555; }
+</style>
+</head>
+<body>
+<h1>Test results analysis</h1>
+<p>{{ .Summary }}</p>
+{{- if .Classification }}
+<p class="category">Failure category: {{ .Classification }}</p>
+{{- end }}
+{{- if .Log }}
+<h2>{{ if .LogPartial }}Log excerpt{{ else }}Log{{ end }}</h2>
+<pre>{{ .Log }}</pre>
+{{- end }}
+{{- if and .KnownFailures (not .FailedTestCases) }}
+<p>No new failures found.</p>
+{{- end }}
+{{- range .FailedTestCases }}
+{{ template "testCase" . }}
+{{- end }}
+{{- if .KnownFailures }}
+<h2>Failed Spec(s) matching known issues</h2>
+{{- range .KnownFailures }}
+{{ template "testCase" . }}
+{{- end }}
+{{- end }}
+{{- with .Diff }}
+<h2>{{ .Title }}</h2>
+<p>{{ .Summary }}</p>
+{{- range .Sections }}{{ if .Specs }}
+<h3>{{ .Title }} ({{ len .Specs }})</h3>
+<ul>
+{{- range .Specs }}
+<li><span class="status">[{{ .Status }}]</span> {{ .Name }}</li>
+{{- end }}
+</ul>
+{{- end }}{{ end }}
+{{- end }}
+{{- if .FullLogsURL }}
+<p><a href="{{ .FullLogsURL }}">View the full logs</a></p>
+{{- end }}
+</body>
+</html>
+{{ define "testCase" }}<details>
+<summary><span class="status">[{{ .Status }}]</span> {{ .Name }}
+{{- if .File }} <span class="file">(from <code>{{ .File }}</code>)</span>{{ end }}
+{{- if .Classification }} <span class="category">(category: {{ .Classification }})</span>{{ end }}
+{{- range .KnownIssues }} &ndash; known issue {{ if .URL }}<a href="{{ .URL }}">{{ .Key }}</a>{{ else }}{{ .Key }}{{ end }}{{ if .Status }} ({{ .Status }}){{ end }}{{ end }}</summary>
+<pre>{{ .Message }}</pre>
+</details>{{ end }}
+`))
@@ -0,0 +1,123 @@
+package testresults
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bsm/ginkgo/v2/reporters"
+	"github.com/konflux-ci/qe-tools/pkg/junitdiff"
+	"github.com/konflux-ci/qe-tools/pkg/knownissues"
+)
+
+func testReport(t *testing.T) FailedTestCasesReport {
+	store, err := knownissues.NewStore([]knownissues.Issue{
+		{Key: "BUG-1", URL: "https://issues.example.com/browse/BUG-1", Signatures: []knownissues.Signature{{Substring: "quota exceeded"}}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create known issues store: %v", err)
+	}
+	return FailedTestCasesReport{
+		FailureType: TestCaseFailure,
+		KnownIssues: store,
+		JUnitTestSuites: &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{{
+			Name:     "suite",
+			Failures: 2,
+			TestCases: []reporters.JUnitTestCase{
+				{Name: "creates <a> build", Status: "failed", Failure: &reporters.JUnitFailure{Message: "unexpected error"}},
+				{Name: "known failure", Status: "failed", Failure: &reporters.JUnitFailure{Message: "quota exceeded"}},
+			},
+		}}},
+	}
+}
+
+// TestFormatters tests formatting the same analysis in all supported formats
+func TestFormatters(t *testing.T) {
+	report := testReport(t)
+
+	tests := []struct {
+		format            string
+		expectedExtension string
+		expectedContent   []string
+	}{
+		{format: MarkdownFormat, expectedExtension: "md", expectedContent: []string{":arrow_right: [**`failed`**] creates <a> build", "[BUG-1](https://issues.example.com/browse/BUG-1)"}},
+		{format: TextFormat, expectedExtension: "txt", expectedContent: []string{"- [failed] creates <a> build\n  unexpected error", "Failed Spec(s) matching known issues:", "known issue(s): BUG-1 https://issues.example.com/browse/BUG-1"}},
+		{format: JSONFormat, expectedExtension: "json", expectedContent: []string{`"failureType": "testCaseFailure"`, `"name": "creates \u003ca\u003e build"`}},
+		{format: SlackFormat, expectedExtension: "json", expectedContent: []string{`"type": "header"`, `creates \u0026lt;a\u0026gt; build`, `\u003chttps://issues.example.com/browse/BUG-1|BUG-1\u003e`}},
+		{format: HTMLFormat, expectedExtension: "html", expectedContent: []string{"creates &lt;a&gt; build", `<a href="https://issues.example.com/browse/BUG-1">BUG-1</a>`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			formatter, err := NewFormatter(tt.format)
+			if err != nil {
+				t.Fatalf("failed to create formatter: %v", err)
+			}
+			if formatter.FileExtension() != tt.expectedExtension {
+				t.Errorf("expected extension %q, got %q", tt.expectedExtension, formatter.FileExtension())
+			}
+			output, err := formatter.Format(report)
+			if err != nil {
+				t.Fatalf("failed to format report: %v", err)
+			}
+			for _, expected := range tt.expectedContent {
+				if !strings.Contains(string(output), expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+				}
+			}
+			if tt.format == JSONFormat || tt.format == SlackFormat {
+				var decoded map[string]any
+				if err := json.Unmarshal(output, &decoded); err != nil {
+					t.Errorf("expected valid JSON, got error %v", err)
+				}
+			}
+		})
+	}
+
+	if _, err := NewFormatter("yaml"); err == nil {
+		t.Errorf("expected an error for unsupported format")
+	}
+}
+
+// TestAnalyze tests separating new and known failures in the analysis
+func TestAnalyze(t *testing.T) {
+	a := testReport(t).Analyze()
+	if len(a.FailedTestCases) != 1 || a.FailedTestCases[0].Name != "creates <a> build" || a.FailedTestCases[0].Message != "unexpected error" {
+		t.Errorf("unexpected new failures: %+v", a.FailedTestCases)
+	}
+	if len(a.KnownFailures) != 1 || len(a.KnownFailures[0].KnownIssues) != 1 || a.KnownFailures[0].KnownIssues[0].Key != "BUG-1" {
+		t.Errorf("unexpected known failures: %+v", a.KnownFailures)
+	}
+}
+
+// TestFormattersWithDiff tests embedding the diff with the baseline run in all supported formats
+func TestFormattersWithDiff(t *testing.T) {
+	report := testReport(t)
+	baseline := &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{{
+		Name:      "suite",
+		TestCases: []reporters.JUnitTestCase{{Name: "creates <a> build", Status: "passed"}},
+	}}}
+	diff := junitdiff.Compare(baseline, report.JUnitTestSuites)
+	diff.Baseline = "job 1234"
+	report.Diff = &diff
+
+	expectedContent := map[string]string{
+		MarkdownFormat: "<summary>:x: Newly failing (1)</summary>",
+		TextFormat:     "Spec(s) compared with job 1234:\n\nNewly failing (1):\n- [failed] creates <a> build",
+		JSONFormat:     `"newlyFailing": [`,
+		SlackFormat:    "Spec(s) compared with job 1234:* 1 newly failing, 1 added",
+		HTMLFormat:     "<h3>Newly failing (1)</h3>",
+	}
+	for _, format := range Formats {
+		formatter, err := NewFormatter(format)
+		if err != nil {
+			t.Fatalf("failed to create formatter: %v", err)
+		}
+		output, err := formatter.Format(report)
+		if err != nil {
+			t.Fatalf("failed to format report as %s: %v", format, err)
+		}
+		if !strings.Contains(string(output), expectedContent[format]) {
+			t.Errorf("expected %s output to contain %q, got:\n%s", format, expectedContent[format], output)
+		}
+	}
+}
@@ -2,38 +2,58 @@ package testresults
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/konflux-ci/qe-tools/pkg/classifier"
+	"github.com/konflux-ci/qe-tools/pkg/logexcerpt"
+	"k8s.io/klog/v2"
 )
 
 const dropdownSummaryString = "Click to view logs"
 
-// extractFailedTestCasesBody initialises the FailedTestCasesReport struct's
-// 'failedTestCaseNames' field with the names of failed test cases
-// within given JUnitTestSuites -- if the given JUnitTestSuites is !nil.
-func extractFailedTestCasesBody(f FailedTestCasesReport) (failedTestCasesBody []string) {
-	switch f.FailureType {
-	case OtherFailure:
-		return
-	case ClusterCreationFailure:
-		return []string{returnContentWrappedInDropdown(dropdownSummaryString, f.ClusterProvisionLog)}
-	case TestRunFailure:
-		return []string{returnContentWrappedInDropdown(dropdownSummaryString, f.E2ETestLog)}
-	}
-	ftc := f.GetFailedTestCases()
-	for _, tc := range ftc {
-		var tcMessage string
-		switch {
-		case tc.Status == "timedout":
-			tcMessage = returnContentWrappedInDropdown(dropdownSummaryString, tc.SystemErr)
-		case tc.Failure != nil:
-			tcMessage = returnContentWrappedInDropdown(dropdownSummaryString, tc.Failure.Message)
-		default:
-			tcMessage = returnContentWrappedInDropdown(dropdownSummaryString, tc.Error.Message)
+// markdownTestCase returns the Markdown entry of the failed test case, with its message (or the excerpt of its log) in a dropdown
+func markdownTestCase(tc AnalyzedTestCase, fullLogsURL string) string {
+	entry := ":arrow_right: " + "[**`" + tc.Status + "`**] " + tc.Name
+	if tc.File != "" {
+		entry += " (from `" + tc.File + "`)"
+	}
+	if tc.Classification != nil {
+		entry += " " + formatClassification(*tc.Classification)
+	}
+	if len(tc.KnownIssues) > 0 {
+		var links []string
+		for _, issue := range tc.KnownIssues {
+			links = append(links, issue.Markdown())
 		}
+		entry += " :link: matches known issue(s): " + strings.Join(links, ", ")
+	}
+	return entry + returnContentWrappedInDropdown(dropdownSummaryString, markdownLog(tc.Message, tc.MessagePartial, fullLogsURL))
+}
 
-		testCaseEntry := ":arrow_right: " + "[**`" + tc.Status + "`**] " + tc.Name + tcMessage
-		failedTestCasesBody = append(failedTestCasesBody, testCaseEntry)
+// excerpt returns the excerpt of the log around the errors and whether any part of the log was left out
+func (f FailedTestCasesReport) excerpt(log string) (string, bool) {
+	opts := logexcerpt.DefaultOptions()
+	if f.LogExcerpt != nil {
+		opts = *f.LogExcerpt
 	}
-	return
+	excerpt, err := logexcerpt.Extract(log, opts)
+	if err != nil {
+		klog.Warningf("cannot extract excerpt of the log, including the whole log: %+v", err)
+		return log, false
+	}
+	return excerpt.Text, excerpt.Partial
+}
+
+// markdownLog returns the log, preceded by a note with a link to the full logs if it's only an excerpt of the log
+func markdownLog(log string, partial bool, fullLogsURL string) string {
+	if !partial {
+		return log
+	}
+	note := "(showing an excerpt of the log"
+	if fullLogsURL != "" {
+		note += ", see the full log in <a href=\"" + fullLogsURL + "\">the artifact</a>"
+	}
+	return note + ")\n\n" + log
 }
 
 // getHeaderStringForFailureType returns 'headerString' for the report summary
@@ -41,26 +61,67 @@ func extractFailedTestCasesBody(f FailedTestCasesReport) (failedTestCasesBody []
 func getHeaderStringForFailureType(ft FailureType) string {
 	switch ft {
 	case OtherFailure:
-		return ":rotating_light: **Couldn't detect a specific failure, see the related PipelineRun for more details or consult with Konflux DevProd team.**\n"
+		return ":rotating_light: **" + summaryForFailureType(ft) + "**\n"
+	case TestRunFailure, ClusterCreationFailure, TestCaseFailure:
+		return ":rotating_light: **" + summaryForFailureType(ft) + "**: \n"
+	}
+	return ""
+}
+
+// summaryForFailureType returns the plain-text summary of the failure
+func summaryForFailureType(ft FailureType) string {
+	switch ft {
+	case OtherFailure:
+		return "Couldn't detect a specific failure, see the related PipelineRun for more details or consult with Konflux DevProd team."
 	case TestRunFailure:
-		return ":rotating_light: **No JUnit file found, see the log from running tests**: \n"
+		return "No JUnit file found, see the log from running tests"
 	case ClusterCreationFailure:
-		return ":rotating_light: **Failed to provision a cluster, see the log for more details**: \n"
+		return "Failed to provision a cluster, see the log for more details"
 	case TestCaseFailure:
-		return ":rotating_light: **Error occurred while running the E2E tests, list of failed Spec(s)**: \n"
+		return "Error occurred while running the E2E tests, list of failed Spec(s)"
 	}
 	return ""
 }
 
+// formatClassification returns the classification of a failure as a Markdown string
+func formatClassification(c classifier.Classification) string {
+	if c.Category == classifier.UnknownCategory {
+		return "(category: _" + string(c.Category) + "_)"
+	}
+	return fmt.Sprintf("(category: **%s**, confidence: %.0f%%)", c.Category, c.Confidence*100)
+}
+
 // GetFormattedReport returns the full report (test run analysis) as a string
-func GetFormattedReport(report FailedTestCasesReport) (formattedReport string) {
-	formattedReport = getHeaderStringForFailureType(report.FailureType)
+func GetFormattedReport(report FailedTestCasesReport) string {
+	return markdownReport(report.Analyze())
+}
 
-	for _, failedTCName := range extractFailedTestCasesBody(report) {
-		formattedReport += fmt.Sprintf("\n %s\n", failedTCName)
+// markdownReport renders the analysis as GitHub-flavoured Markdown
+func markdownReport(a Analysis) string {
+	formattedReport := getHeaderStringForFailureType(a.FailureType)
+	if a.Classification != nil {
+		formattedReport += "Failure " + formatClassification(*a.Classification) + "\n"
+	}
+	if a.Log != "" {
+		formattedReport += fmt.Sprintf("\n %s\n", returnContentWrappedInDropdown(dropdownSummaryString, markdownLog(a.Log, a.LogPartial, a.FullLogsURL)))
 	}
 
-	return
+	if len(a.KnownFailures) > 0 && len(a.FailedTestCases) == 0 {
+		formattedReport += "\n:white_check_mark: **No new failures found.**\n"
+	}
+	for _, tc := range a.FailedTestCases {
+		formattedReport += fmt.Sprintf("\n %s\n", markdownTestCase(tc, a.FullLogsURL))
+	}
+	if len(a.KnownFailures) > 0 {
+		formattedReport += "\n:information_source: **Failed Spec(s) matching known issues**: \n"
+		for _, tc := range a.KnownFailures {
+			formattedReport += fmt.Sprintf("\n %s\n", markdownTestCase(tc, a.FullLogsURL))
+		}
+	}
+	if a.Diff != nil {
+		formattedReport += "\n" + a.Diff.Markdown()
+	}
+	return formattedReport
 }
 
 func returnContentWrappedInDropdown(summary, content string) string {
@@ -0,0 +1,70 @@
+package testresults
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bsm/ginkgo/v2/reporters"
+	"github.com/konflux-ci/qe-tools/pkg/knownissues"
+	"github.com/konflux-ci/qe-tools/pkg/logexcerpt"
+)
+
+// TestGetFormattedReportWithKnownIssues tests separating new failures from the ones matching known issues
+func TestGetFormattedReportWithKnownIssues(t *testing.T) {
+	store, err := knownissues.NewStore([]knownissues.Issue{
+		{Key: "BUG-1", URL: "https://issues.example.com/browse/BUG-1", Status: "Open", Signatures: []knownissues.Signature{{Substring: "quota exceeded"}}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create known issues store: %v", err)
+	}
+	report := FailedTestCasesReport{
+		FailureType: TestCaseFailure,
+		KnownIssues: store,
+		JUnitTestSuites: &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{{
+			Name:     "suite",
+			Failures: 2,
+			TestCases: []reporters.JUnitTestCase{
+				{Name: "new failure", Status: "failed", Failure: &reporters.JUnitFailure{Message: "unexpected error"}},
+				{Name: "known failure", Status: "failed", Failure: &reporters.JUnitFailure{Message: "quota exceeded"}},
+				{Name: "passed", Status: "passed"},
+			},
+		}}},
+	}
+
+	formatted := GetFormattedReport(report)
+
+	knownSection := strings.Index(formatted, "matching known issues")
+	if knownSection == -1 {
+		t.Fatalf("expected the section with known failures, got:\n%s", formatted)
+	}
+	if newIdx := strings.Index(formatted, "new failure"); newIdx == -1 || newIdx > knownSection {
+		t.Errorf("expected the new failure to be listed before the known failures, got:\n%s", formatted)
+	}
+	if knownIdx := strings.Index(formatted, "known failure"); knownIdx < knownSection {
+		t.Errorf("expected the known failure to be listed within the known failures, got:\n%s", formatted)
+	}
+	if !strings.Contains(formatted, "[BUG-1](https://issues.example.com/browse/BUG-1) (Open)") {
+		t.Errorf("expected a link to the known issue, got:\n%s", formatted)
+	}
+}
+
+// TestGetFormattedReportWithLogExcerpt tests including only the excerpt of the log with a link to the full log
+func TestGetFormattedReportWithLogExcerpt(t *testing.T) {
+	log := strings.Repeat("provisioning...\n", 100) + "level=error msg=quota exceeded\n" + strings.Repeat("cleaning up...\n", 100)
+	report := FailedTestCasesReport{
+		FailureType:         ClusterCreationFailure,
+		ClusterProvisionLog: log,
+		LogExcerpt:          &logexcerpt.Options{ContextLines: 2},
+		FullLogsURL:         "https://quay.io/repository/org/repo?tab=tags&tag=tag",
+	}
+
+	formatted := GetFormattedReport(report)
+
+	expectedExcerpt := "[...]\nprovisioning...\nprovisioning...\nlevel=error msg=quota exceeded\ncleaning up...\ncleaning up...\n[...]"
+	if !strings.Contains(formatted, expectedExcerpt) {
+		t.Errorf("expected the excerpt %q, got:\n%s", expectedExcerpt, formatted)
+	}
+	if !strings.Contains(formatted, `<a href="https://quay.io/repository/org/repo?tab=tags&tag=tag">`) {
+		t.Errorf("expected a link to the full log, got:\n%s", formatted)
+	}
+}
@@ -0,0 +1,87 @@
+package testresults
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/bsm/ginkgo/v2/reporters"
+	"github.com/konflux-ci/qe-tools/pkg/junitutil"
+	"github.com/konflux-ci/qe-tools/pkg/oci"
+	"k8s.io/klog/v2"
+)
+
+// DecodeJUnit decodes JUnit XML with either <testsuites> or <testsuite> root element
+func DecodeJUnit(content string) (*reporters.JUnitTestSuites, error) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("no JUnit root element found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		suites := &reporters.JUnitTestSuites{}
+		switch start.Name.Local {
+		case "testsuites":
+			if err := decoder.DecodeElement(suites, &start); err != nil {
+				return nil, err
+			}
+		case "testsuite":
+			suite := reporters.JUnitTestSuite{}
+			if err := decoder.DecodeElement(&suite, &start); err != nil {
+				return nil, err
+			}
+			junitutil.MergeSuites(suites, &reporters.JUnitTestSuites{
+				Tests: suite.Tests, Disabled: suite.Disabled + suite.Skipped, Errors: suite.Errors, Failures: suite.Failures,
+				Time: suite.Time, TestSuites: []reporters.JUnitTestSuite{suite},
+			})
+		default:
+			return nil, fmt.Errorf("unexpected JUnit root element <%s>", start.Name.Local)
+		}
+		return suites, nil
+	}
+}
+
+// MergeJUnitFiles decodes and merges all JUnit files matching the pattern, processed in a stable (sorted) order.
+// Every merged suite gets the JUnitFilePropertyName property with the path of its file. Paths of all matching
+// files are returned, including files that couldn't be decoded
+func MergeJUnitFiles(fpm oci.FilesPathMap, pattern *FilePattern) (*reporters.JUnitTestSuites, []string) {
+	merged := &reporters.JUnitTestSuites{}
+	var files []string
+	for _, p := range sortedPaths(fpm) {
+		if !pattern.Match(p) {
+			continue
+		}
+		files = append(files, p)
+		suites, err := DecodeJUnit(fpm[oci.FilePath(p)].Content)
+		if err != nil {
+			klog.Warningf("cannot decode JUnit suite from %s into xml: %+v", p, err)
+			continue
+		}
+		for i := range suites.TestSuites {
+			suites.TestSuites[i].Properties.Properties = append(suites.TestSuites[i].Properties.Properties, reporters.JUnitProperty{Name: JUnitFilePropertyName, Value: p})
+		}
+		junitutil.MergeSuites(merged, suites)
+	}
+	return merged, files
+}
+
+// sortedPaths returns the paths of all files within the map in a sorted order
+func sortedPaths(fpm oci.FilesPathMap) []string {
+	paths := make([]string, 0, len(fpm))
+	for p := range fpm {
+		paths = append(paths, string(p))
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,109 @@
+package testresults
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+
+	"github.com/bsm/ginkgo/v2/reporters"
+	"github.com/konflux-ci/qe-tools/pkg/oci"
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+	"k8s.io/klog/v2"
+)
+
+// DefaultJUnitPattern matches JUnit files within OCI artifacts (e.g. "e2e-report.xml")
+// as well as within artifacts of Prow jobs (e.g. "redhat-appstudio-e2e/artifacts/junit.xml")
+const DefaultJUnitPattern = RegexpFilePatternPrefix + `(^|/)(j?unit|e2e)[^/]*\.xml$`
+
+// prowReportStep is the step of Prow jobs with the JUnit report of the whole job (created by "prowjob create-report")
+const prowReportStep = "redhat-appstudio-report"
+
+// prowJobIDRegexp matches references to test results that are Prow job IDs
+var prowJobIDRegexp = regexp.MustCompile(`^[0-9]+$`)
+
+// LoadJUnit loads the JUnit report the reference points to - the reference is either a path to a JUnit file,
+// a Prow job ID or an OCI artifact reference. All JUnit files matching the pattern within the artifacts
+// of the Prow job (scanned with the given configuration, see ScanProwJob) or within the OCI artifact are merged
+func LoadJUnit(ref string, pattern *FilePattern, prowConfig prow.ScannerConfig) (*reporters.JUnitTestSuites, error) {
+	if info, err := os.Stat(ref); err == nil && !info.IsDir() {
+		return decodeJUnitFile(ref)
+	}
+
+	var fpm oci.FilesPathMap
+	var err error
+	if prowJobIDRegexp.MatchString(ref) {
+		fpm, _, err = ScanProwJob(prowConfig, ref, []string{pattern.Regexp()})
+	} else {
+		fpm, err = scanOCIArtifact(ref, pattern)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	suites, files := MergeJUnitFiles(fpm, pattern)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no JUnit file matching %q found in %s", pattern, ref)
+	}
+	klog.Infof("loaded test results of %s from %d JUnit file(s)", ref, len(files))
+	return suites, nil
+}
+
+// decodeJUnitFile decodes the JUnit file, e.g. produced by "prowjob create-report"
+func decodeJUnitFile(path string) (*reporters.JUnitTestSuites, error) {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JUnit file %s: %+v", path, err)
+	}
+	suites, err := DecodeJUnit(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode JUnit suites from %s: %+v", path, err)
+	}
+	return suites, nil
+}
+
+// ScanProwJob returns files matching the file name filter (see FileNameFilter) from the artifacts of the Prow job
+// with the given ID, together with the Prow job URL. The configuration selects the Prow instance, the source
+// of the artifacts and the job target rules. Artifacts of the report step are skipped, since it only
+// contains copies of the JUnit files of the other steps
+func ScanProwJob(cfg prow.ScannerConfig, jobID string, fileNameFilter []string) (oci.FilesPathMap, string, error) {
+	cfg.ProwJobID = jobID
+	cfg.FileNameFilter = fileNameFilter
+	cfg.StepsToSkip = []string{prowReportStep}
+	scanner, err := prow.NewArtifactScanner(cfg)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to initialize Prow artifact scanner: %+v", err)
+	}
+	if err := scanner.Run(); err != nil {
+		return nil, "", fmt.Errorf("failed to scan artifacts of Prow job %s: %+v", jobID, err)
+	}
+	return prowFilesPathMap(scanner.ArtifactStepMap), scanner.ProwJobURL, nil
+}
+
+// prowFilesPathMap returns the artifacts scanned from a Prow job in the same form as files scanned from OCI artifacts,
+// keyed by their path relative to the job's artifact directory, e.g. "redhat-appstudio-e2e/artifacts/e2e-report.xml"
+func prowFilesPathMap(stepMap map[prow.ArtifactStepName]prow.ArtifactFilenameMap) oci.FilesPathMap {
+	fpm := oci.FilesPathMap{}
+	for step, files := range stepMap {
+		for filename, artifact := range files {
+			fpm[oci.FilePath(path.Join(string(step), string(filename)))] = oci.Artifact{Content: artifact.Content, Filename: filename.Base()}
+		}
+	}
+	return fpm
+}
+
+// scanOCIArtifact returns files matching the pattern from the OCI artifact
+func scanOCIArtifact(ref string, pattern *FilePattern) (oci.FilesPathMap, error) {
+	scanner, err := oci.NewArtifactScanner(oci.ScannerConfig{
+		OciArtifactReference: ref,
+		FileNameFilter:       []string{pattern.Regexp()},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize artifact scanner: %+v", err)
+	}
+	if err := scanner.Run(); err != nil {
+		return nil, fmt.Errorf("failed to scan artifact from %s: %+v", ref, err)
+	}
+	return scanner.FilesPathMap, nil
+}
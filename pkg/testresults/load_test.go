@@ -0,0 +1,55 @@
+package testresults
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konflux-ci/qe-tools/pkg/prow"
+)
+
+// TestLoadJUnitFile tests loading the JUnit report from a local file with any name
+func TestLoadJUnitFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.xml")
+	if err := os.WriteFile(path, []byte(`<testsuite name="suite"><testcase name="a"></testcase></testsuite>`), 0o600); err != nil {
+		t.Fatalf("failed to write JUnit file: %v", err)
+	}
+	pattern, err := NewFilePattern(DefaultJUnitPattern)
+	if err != nil {
+		t.Fatalf("failed to compile the default JUnit pattern: %v", err)
+	}
+	suites, err := LoadJUnit(path, pattern, prow.ScannerConfig{})
+	if err != nil {
+		t.Fatalf("failed to load JUnit file: %v", err)
+	}
+	if len(suites.TestSuites) != 1 || len(suites.TestSuites[0].TestCases) != 1 {
+		t.Errorf("expected a single suite with a single test case, got %+v", suites)
+	}
+
+	for _, p := range []string{"e2e-report.xml", "redhat-appstudio-e2e/artifacts/junit.xml", "step/artifacts/junit-rp.xml"} {
+		if !pattern.Match(p) {
+			t.Errorf("expected the default JUnit pattern to match %s", p)
+		}
+	}
+	if pattern.Match("step/artifacts/report.xml") {
+		t.Errorf("expected the default JUnit pattern not to match report.xml")
+	}
+}
+
+// TestProwFilesPathMap tests keying artifacts scanned from a Prow job by their path relative to the artifact directory
+func TestProwFilesPathMap(t *testing.T) {
+	fpm := prowFilesPathMap(map[prow.ArtifactStepName]prow.ArtifactFilenameMap{
+		"redhat-appstudio-e2e": {
+			"build-log.txt":               {Content: "e2e build log"},
+			"artifacts/suite-a/junit.xml": {Content: "<testsuites/>"},
+		},
+		"gather-extra": {"finished.json": {Content: `{"passed": false}`}},
+	})
+	if len(fpm) != 3 {
+		t.Errorf("expected 3 files in the files path map, got %d: %+v", len(fpm), fpm)
+	}
+	junit, ok := fpm["redhat-appstudio-e2e/artifacts/suite-a/junit.xml"]
+	if !ok || junit.Filename != "junit.xml" || junit.Content != "<testsuites/>" {
+		t.Errorf("expected JUnit file of suite-a in the files path map, got %+v", junit)
+	}
+}
@@ -1,9 +1,15 @@
 package testresults
 
 import (
-	"encoding/xml"
+	"fmt"
+	"strings"
 
 	"github.com/bsm/ginkgo/v2/reporters"
+	"github.com/konflux-ci/qe-tools/pkg/classifier"
+	"github.com/konflux-ci/qe-tools/pkg/junitdiff"
+	"github.com/konflux-ci/qe-tools/pkg/junitutil"
+	"github.com/konflux-ci/qe-tools/pkg/knownissues"
+	"github.com/konflux-ci/qe-tools/pkg/logexcerpt"
 	"github.com/konflux-ci/qe-tools/pkg/oci"
 	"k8s.io/klog/v2"
 )
@@ -22,6 +28,10 @@ const (
 	TestCaseFailure FailureType = "testCaseFailure"
 )
 
+// JUnitFilePropertyName is the name of the property added to the merged JUnit suites
+// with the path of the JUnit file the suite came from
+const JUnitFilePropertyName = "junit-file"
+
 // FailedTestCasesReport collects the data about failures
 type FailedTestCasesReport struct {
 	JUnitTestSuites *reporters.JUnitTestSuites
@@ -30,58 +40,140 @@ type FailedTestCasesReport struct {
 	E2ETestLog          string
 
 	FailureType FailureType
+
+	// Classifier is used for classifying the failures in the report - no classification is done if nil
+	Classifier *classifier.Classifier
+	// KnownIssues are matched against the failed test cases - no matching is done if nil
+	KnownIssues *knownissues.Store
+
+	// LogExcerpt configures extracting excerpts around errors from the logs included in the report - defaults are used if nil
+	LogExcerpt *logexcerpt.Options
+	// FullLogsURL is linked from partial log excerpts, e.g. the URL of the artifact with the logs
+	FullLogsURL string
+
+	// JUnitFiles are the paths of all the JUnit files merged into JUnitTestSuites
+	JUnitFiles []string
+
+	// Diff with the results of a baseline run (e.g. the last green periodic job) - it's included in the report if not nil
+	Diff *junitdiff.Diff
+}
+
+// FailedTestCase is a failed JUnit test case together with the path of the JUnit file it came from
+type FailedTestCase struct {
+	reporters.JUnitTestCase
+	// File is empty if the JUnit file is unknown
+	File string
 }
 
 // CollectTestFilesData inspects the FilesPathMap data and based on the supplied
-// names for JUnit, E2E run log and cluster provision log file names determines
-// the cause of the PipelineRun failure
-func (f *FailedTestCasesReport) CollectTestFilesData(fpm oci.FilesPathMap, junitFilename, e2eTestRunLogFilename, clusterProvisionLogFilename string) {
-	f.JUnitTestSuites = &reporters.JUnitTestSuites{}
-
-	for _, file := range fpm {
-		if string(file.Filename) == junitFilename {
-			if err := xml.Unmarshal([]byte(file.Content), f.JUnitTestSuites); err != nil {
-				klog.Warningf("cannot decode JUnit suite into xml: %+v", err)
-			}
-			f.FailureType = TestCaseFailure
-			klog.Info("the given PipelineRun failed on a test case failure")
-			return
+// file patterns (see FilePattern) for JUnit, E2E run log and cluster provision log files
+// determines the cause of the PipelineRun failure. All matched JUnit reports are merged,
+// and all matched logs of the same kind are concatenated
+func (f *FailedTestCasesReport) CollectTestFilesData(fpm oci.FilesPathMap, junitPattern, e2eTestRunLogPattern, clusterProvisionLogPattern string) error {
+	patterns := make([]*FilePattern, 3)
+	for i, p := range []string{junitPattern, e2eTestRunLogPattern, clusterProvisionLogPattern} {
+		var err error
+		if patterns[i], err = NewFilePattern(p); err != nil {
+			return err
 		}
 	}
+	junitFilePattern, e2eTestRunLogFilePattern, clusterProvisionLogFilePattern := patterns[0], patterns[1], patterns[2]
+
+	// Process the files in a stable order, so the merged report (and the logs) don't differ between runs
+	f.JUnitTestSuites, f.JUnitFiles = MergeJUnitFiles(fpm, junitFilePattern)
+	if len(f.JUnitFiles) > 0 {
+		f.FailureType = TestCaseFailure
+		klog.Infof("the given PipelineRun failed on a test case failure (JUnit file(s): %s)", strings.Join(f.JUnitFiles, ", "))
+		return nil
+	}
 
-	for _, file := range fpm {
-		if file.Filename == e2eTestRunLogFilename {
-			f.E2ETestLog = file.Content
+	var e2eTestLogs, clusterProvisionLogs []string
+	for _, p := range sortedPaths(fpm) {
+		if e2eTestRunLogFilePattern.Match(p) {
+			e2eTestLogs = append(e2eTestLogs, p)
 		}
-		if file.Filename == clusterProvisionLogFilename {
-			f.ClusterProvisionLog = file.Content
+		if clusterProvisionLogFilePattern.Match(p) {
+			clusterProvisionLogs = append(clusterProvisionLogs, p)
 		}
 	}
+	f.E2ETestLog = concatLogs(fpm, e2eTestLogs)
+	f.ClusterProvisionLog = concatLogs(fpm, clusterProvisionLogs)
 
 	if f.E2ETestLog != "" {
 		klog.Info("no JUnit file found - PipelineRun failed during running tests")
 		f.FailureType = TestRunFailure
-		return
+		return nil
 	}
 
 	if f.ClusterProvisionLog != "" {
 		klog.Info("failed to provision a cluster")
 		f.FailureType = ClusterCreationFailure
-		return
+		return nil
 	}
 
 	klog.Info("could not find any related artifacts")
 	f.FailureType = OtherFailure
+	return nil
+}
+
+// concatLogs returns the content of the log files with the given paths. If there are more of them,
+// the content of each file is preceded by a line with its path
+func concatLogs(fpm oci.FilesPathMap, paths []string) string {
+	if len(paths) == 1 {
+		return fpm[oci.FilePath(paths[0])].Content
+	}
+	var sb strings.Builder
+	for _, p := range paths {
+		fmt.Fprintf(&sb, "==> %s <==\n%s\n", p, strings.TrimSuffix(fpm[oci.FilePath(p)].Content, "\n"))
+	}
+	return sb.String()
+}
+
+// Classify returns the classification of the failure of the given test case, or of the whole
+// PipelineRun if the test case is nil - false is returned if the report has no Classifier
+func (f *FailedTestCasesReport) Classify(tc *reporters.JUnitTestCase) (classifier.Classification, bool) {
+	if f.Classifier == nil {
+		return classifier.Classification{}, false
+	}
+	switch {
+	case tc != nil:
+		return f.Classifier.Classify(classifier.Evidence{FailureMessage: junitutil.FailureDetails(*tc), BuildLog: tc.SystemErr}), true
+	case f.FailureType == ClusterCreationFailure:
+		return f.Classifier.Classify(classifier.Evidence{StepName: "cluster-provision", BuildLog: f.ClusterProvisionLog}), true
+	case f.FailureType == TestRunFailure:
+		return f.Classifier.Classify(classifier.Evidence{BuildLog: f.E2ETestLog}), true
+	}
+	return classifier.Classification{}, false
+}
+
+// MatchKnownIssues returns the known issues matching the failure of the given test case
+func (f *FailedTestCasesReport) MatchKnownIssues(tc reporters.JUnitTestCase) []knownissues.Issue {
+	if f.KnownIssues == nil {
+		return nil
+	}
+	return f.KnownIssues.Match(tc.Name, junitutil.FailureDetails(tc))
 }
 
 // GetFailedTestCases returns the list of JUnit test cases that failed
 func (f *FailedTestCasesReport) GetFailedTestCases() (ftc []reporters.JUnitTestCase) {
+	for _, tc := range f.GetFailedTestCasesWithFiles() {
+		ftc = append(ftc, tc.JUnitTestCase)
+	}
+	return
+}
+
+// GetFailedTestCasesWithFiles returns the list of JUnit test cases that failed, together
+// with the JUnit files they came from
+func (f *FailedTestCasesReport) GetFailedTestCasesWithFiles() (ftc []FailedTestCase) {
+	if f.JUnitTestSuites == nil {
+		return nil
+	}
 	for _, testSuite := range f.JUnitTestSuites.TestSuites {
 		if testSuite.Failures > 0 || testSuite.Errors > 0 {
 			for _, tc := range testSuite.TestCases {
 				if tc.Failure != nil || tc.Error != nil {
 					klog.Infof("Found a Test Case (suiteName/testCaseName): %s/%s, that didn't pass", testSuite.Name, tc.Name)
-					ftc = append(ftc, tc)
+					ftc = append(ftc, FailedTestCase{JUnitTestCase: tc, File: testSuite.Properties.WithName(JUnitFilePropertyName)})
 				}
 			}
 		}
@@ -0,0 +1,68 @@
+package testresults
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/konflux-ci/qe-tools/pkg/oci"
+)
+
+const (
+	suitesJUnit = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="2" failures="1">
+  <testsuite name="build" tests="2" failures="1">
+    <testcase name="creates a build" status="failed"><failure message="build failed"></failure></testcase>
+    <testcase name="passes" status="passed"></testcase>
+  </testsuite>
+</testsuites>`
+	suiteJUnit = `<testsuite name="integration" tests="1" failures="1">
+  <testcase name="runs a pipeline" status="failed"><failure message="pipeline failed"></failure></testcase>
+</testsuite>`
+)
+
+// TestCollectTestFilesData tests collecting JUnit reports and logs matching the file patterns
+func TestCollectTestFilesData(t *testing.T) {
+	fpm := oci.FilesPathMap{
+		"build/artifacts/junit-build.xml":       {Content: suitesJUnit, Filename: "junit-build.xml"},
+		"integration/artifacts/junit-integ.xml": {Content: suiteJUnit, Filename: "junit-integ.xml"},
+		"integration/artifacts/broken.xml":      {Content: "<testsuites", Filename: "broken.xml"},
+		"build/e2e-tests.log":                   {Content: "build log\n", Filename: "e2e-tests.log"},
+		"integration/e2e-tests.log":             {Content: "integration log\n", Filename: "e2e-tests.log"},
+	}
+
+	t.Run("junit", func(t *testing.T) {
+		report := FailedTestCasesReport{}
+		if err := report.CollectTestFilesData(fpm, "junit-*.xml", "e2e-tests.log", "cluster-provision.log"); err != nil {
+			t.Fatalf("failed to collect test files data: %v", err)
+		}
+		if report.FailureType != TestCaseFailure || len(report.JUnitFiles) != 2 || len(report.JUnitTestSuites.TestSuites) != 2 || report.JUnitTestSuites.Failures != 2 {
+			t.Fatalf("expected 2 merged JUnit reports, got %+v", report)
+		}
+		failed := report.GetFailedTestCasesWithFiles()
+		if len(failed) != 2 || failed[0].Name != "creates a build" || failed[0].File != "build/artifacts/junit-build.xml" ||
+			failed[1].Name != "runs a pipeline" || failed[1].File != "integration/artifacts/junit-integ.xml" {
+			t.Errorf("unexpected failed test cases: %+v", failed)
+		}
+		if formatted := GetFormattedReport(report); !strings.Contains(formatted, "runs a pipeline (from `integration/artifacts/junit-integ.xml`)") {
+			t.Errorf("expected the formatted report to contain the JUnit file of the failure, got:\n%s", formatted)
+		}
+	})
+
+	t.Run("logs", func(t *testing.T) {
+		report := FailedTestCasesReport{}
+		if err := report.CollectTestFilesData(fpm, "re:^missing/", "e2e-tests.log", "cluster-provision.log"); err != nil {
+			t.Fatalf("failed to collect test files data: %v", err)
+		}
+		expectedLog := "==> build/e2e-tests.log <==\nbuild log\n==> integration/e2e-tests.log <==\nintegration log\n"
+		if report.FailureType != TestRunFailure || report.E2ETestLog != expectedLog {
+			t.Errorf("expected concatenated e2e logs, got %q (failure type %s)", report.E2ETestLog, report.FailureType)
+		}
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		report := FailedTestCasesReport{}
+		if err := report.CollectTestFilesData(fpm, "re:(", "e2e-tests.log", "cluster-provision.log"); err == nil {
+			t.Errorf("expected an error for invalid pattern")
+		}
+	})
+}
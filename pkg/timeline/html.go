@@ -0,0 +1,64 @@
+package timeline
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"time"
+)
+
+var htmlTemplate = template.Must(template.New("timeline").Funcs(template.FuncMap{
+	"offset":   func(t *Timeline, s Step) string { return percentage(s.Started.Sub(t.Started), t.Duration) },
+	"width":    func(t *Timeline, s Step) string { return percentage(s.Duration, t.Duration) },
+	"duration": func(d time.Duration) string { return d.Round(time.Second).String() },
+	"time":     func(t time.Time) string { return t.UTC().Format("15:04:05") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Timeline{{ if .JobName }} of {{ .JobName }}{{ end }}{{ if .JobID }} #{{ .JobID }}{{ end }}</title>
+<style>
+  body { font-family: sans-serif; margin: 2em; }
+  table { border-collapse: collapse; width: 100%; }
+  td { padding: 2px 6px; white-space: nowrap; font-size: 0.9em; }
+  td.bar { width: 60%; position: relative; }
+  .track { position: relative; height: 1.2em; background: #f2f2f2; }
+  .step { position: absolute; top: 0; height: 100%; min-width: 2px; background: #3c8d40; }
+  .step.failed { background: #c9302c; }
+  tr.slowest td.name { font-weight: bold; }
+  tr.slowest .step { outline: 2px solid #f0ad4e; }
+</style>
+</head>
+<body>
+<h2>Timeline{{ if .JobURL }} of <a href="{{ .JobURL }}">{{ .JobName }} #{{ .JobID }}</a>{{ else if .JobName }} of {{ .JobName }}{{ end }}</h2>
+<p>Started at {{ time .Started }} UTC, took {{ duration .Duration }}.</p>
+{{ with .SlowestSteps }}<p>Slowest steps: {{ range $i, $s := . }}{{ if $i }}, {{ end }}<b>{{ $s.Name }}</b> ({{ duration $s.Duration }}){{ end }}</p>{{ end }}
+{{ with .ProvisioningToTests }}<p>Gap between the cluster provisioning (<b>{{ .ProvisioningStep }}</b>) and the test execution (<b>{{ .TestStep }}</b>): <b>{{ duration .Duration }}</b></p>{{ end }}
+<table>
+<tr><th>Step</th><th>Start</th><th>Duration</th><th></th></tr>
+{{ range .Steps }}<tr{{ if .Slowest }} class="slowest"{{ end }}>
+  <td class="name">{{ .Name }}</td>
+  <td>{{ time .Started }}</td>
+  <td>{{ duration .Duration }}</td>
+  <td class="bar"><div class="track"><div class="step{{ if not .Passed }} failed{{ end }}" style="left: {{ offset $ . }}; width: {{ width $ . }}"></div></div></td>
+</tr>
+{{ end }}</table>
+</body>
+</html>
+`))
+
+// HTML returns the timeline as a Gantt-like HTML page
+func (t *Timeline) HTML() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, t); err != nil {
+		return nil, fmt.Errorf("failed to render timeline: %+v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func percentage(part, total time.Duration) string {
+	if total <= 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.2f%%", float64(part)/float64(total)*100)
+}
@@ -0,0 +1,157 @@
+package timeline
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultProvisioningStepPattern matches names of openshift-ci steps provisioning the test cluster
+	DefaultProvisioningStepPattern = `ipi-install|provision|cluster-install|create-cluster|hypershift-.*-create`
+	// DefaultTestStepPattern matches names of openshift-ci steps executing the tests
+	DefaultTestStepPattern = `e2e|test`
+	// DefaultSlowestSteps is the default number of the slowest steps highlighted in the timeline
+	DefaultSlowestSteps = 3
+)
+
+// Step represents the execution of a single openshift-ci step
+type Step struct {
+	Name     string        `json:"name"`
+	Started  time.Time     `json:"started"`
+	Finished time.Time     `json:"finished"`
+	Duration time.Duration `json:"duration"`
+	Passed   bool          `json:"passed"`
+	// Slowest is true if the step is among the slowest steps of the job
+	Slowest bool `json:"slowest"`
+}
+
+// Gap represents the time between the end of the cluster provisioning and the start of the test execution
+type Gap struct {
+	ProvisioningStep string        `json:"provisioningStep"`
+	TestStep         string        `json:"testStep"`
+	Duration         time.Duration `json:"duration"`
+}
+
+// Timeline represents the steps of a Prow job sorted by their start
+type Timeline struct {
+	JobName  string        `json:"jobName,omitempty"`
+	JobID    string        `json:"jobID,omitempty"`
+	JobURL   string        `json:"jobURL,omitempty"`
+	Started  time.Time     `json:"started"`
+	Finished time.Time     `json:"finished"`
+	Duration time.Duration `json:"duration"`
+	Steps    []Step        `json:"steps"`
+	// ProvisioningToTests is the gap between cluster provisioning and test execution - nil if any of them wasn't found
+	ProvisioningToTests *Gap `json:"provisioningToTests,omitempty"`
+}
+
+// Options configure the timeline analysis
+type Options struct {
+	// ProvisioningStepPattern and TestStepPattern are regular expressions matching names of steps provisioning
+	// the cluster and executing the tests - default to DefaultProvisioningStepPattern and DefaultTestStepPattern
+	ProvisioningStepPattern string
+	TestStepPattern         string
+	// SlowestSteps is the number of the slowest steps to highlight - defaults to DefaultSlowestSteps
+	SlowestSteps int
+}
+
+// New creates a Timeline from the given steps. Steps without the start or end time are omitted
+func New(steps []Step, opts Options) (*Timeline, error) {
+	if opts.ProvisioningStepPattern == "" {
+		opts.ProvisioningStepPattern = DefaultProvisioningStepPattern
+	}
+	if opts.TestStepPattern == "" {
+		opts.TestStepPattern = DefaultTestStepPattern
+	}
+	if opts.SlowestSteps == 0 {
+		opts.SlowestSteps = DefaultSlowestSteps
+	}
+	provisioningRegexp, err := regexp.Compile(opts.ProvisioningStepPattern)
+	if err != nil {
+		return nil, err
+	}
+	testRegexp, err := regexp.Compile(opts.TestStepPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Timeline{Steps: []Step{}}
+	for _, step := range steps {
+		if step.Started.IsZero() || step.Finished.IsZero() {
+			continue
+		}
+		step.Duration = step.Finished.Sub(step.Started)
+		t.Steps = append(t.Steps, step)
+	}
+	sort.SliceStable(t.Steps, func(i, j int) bool {
+		if !t.Steps[i].Started.Equal(t.Steps[j].Started) {
+			return t.Steps[i].Started.Before(t.Steps[j].Started)
+		}
+		return t.Steps[i].Name < t.Steps[j].Name
+	})
+	if len(t.Steps) == 0 {
+		return t, nil
+	}
+
+	t.Started, t.Finished = t.Steps[0].Started, t.Steps[0].Finished
+	for _, step := range t.Steps {
+		if step.Finished.After(t.Finished) {
+			t.Finished = step.Finished
+		}
+	}
+	t.Duration = t.Finished.Sub(t.Started)
+
+	bySlowest := make([]int, len(t.Steps))
+	for i := range bySlowest {
+		bySlowest[i] = i
+	}
+	sort.SliceStable(bySlowest, func(i, j int) bool {
+		return t.Steps[bySlowest[i]].Duration > t.Steps[bySlowest[j]].Duration
+	})
+	for i := 0; i < opts.SlowestSteps && i < len(bySlowest); i++ {
+		t.Steps[bySlowest[i]].Slowest = true
+	}
+
+	var provisioning, test *Step
+	for i := range t.Steps {
+		if step := &t.Steps[i]; provisioningRegexp.MatchString(step.Name) && (provisioning == nil || step.Finished.After(provisioning.Finished)) {
+			provisioning = step
+		}
+	}
+	if provisioning == nil {
+		return t, nil
+	}
+	// The first test step started after the cluster provisioning has begun
+	for i := range t.Steps {
+		if step := &t.Steps[i]; !provisioningRegexp.MatchString(step.Name) && testRegexp.MatchString(step.Name) && !step.Started.Before(provisioning.Started) {
+			test = step
+			break
+		}
+	}
+	if test != nil {
+		t.ProvisioningToTests = &Gap{ProvisioningStep: provisioning.Name, TestStep: test.Name, Duration: test.Started.Sub(provisioning.Finished)}
+	}
+
+	return t, nil
+}
+
+// SlowestSteps returns the highlighted slowest steps, the slowest first
+func (t *Timeline) SlowestSteps() []Step {
+	var slowest []Step
+	for _, step := range t.Steps {
+		if step.Slowest {
+			slowest = append(slowest, step)
+		}
+	}
+	sort.SliceStable(slowest, func(i, j int) bool {
+		return slowest[i].Duration > slowest[j].Duration
+	})
+	return slowest
+}
+
+// JSON returns the timeline as an indented JSON
+func (t *Timeline) JSON() ([]byte, error) {
+	return json.MarshalIndent(t, "", "    ")
+}
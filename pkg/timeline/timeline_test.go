@@ -0,0 +1,68 @@
+package timeline
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestNew tests creating a timeline of openshift-ci steps
+func TestNew(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	steps := []Step{
+		{Name: "e2e-tests", Started: start.Add(50 * time.Minute), Finished: start.Add(110 * time.Minute), Passed: false},
+		{Name: "ipi-install-install", Started: start.Add(5 * time.Minute), Finished: start.Add(45 * time.Minute), Passed: true},
+		{Name: "unit-test", Started: start, Finished: start.Add(2 * time.Minute), Passed: true},
+		{Name: "gather-extra", Started: start.Add(110 * time.Minute), Finished: start.Add(115 * time.Minute), Passed: true},
+		{Name: "no-started-json", Finished: start.Add(115 * time.Minute), Passed: true},
+	}
+
+	tl, err := New(steps, Options{SlowestSteps: 2})
+	if err != nil {
+		t.Fatalf("failed to create timeline: %v", err)
+	}
+
+	var names []string
+	for _, s := range tl.Steps {
+		names = append(names, s.Name)
+	}
+	if expected := "unit-test ipi-install-install e2e-tests gather-extra"; strings.Join(names, " ") != expected {
+		t.Errorf("expected steps %q, got %q", expected, strings.Join(names, " "))
+	}
+	if tl.Duration != 115*time.Minute || !tl.Started.Equal(start) {
+		t.Errorf("unexpected start %s and duration %s of the timeline", tl.Started, tl.Duration)
+	}
+
+	slowest := tl.SlowestSteps()
+	if len(slowest) != 2 || slowest[0].Name != "e2e-tests" || slowest[1].Name != "ipi-install-install" {
+		t.Errorf("unexpected slowest steps: %+v", slowest)
+	}
+
+	gap := tl.ProvisioningToTests
+	if gap == nil || gap.ProvisioningStep != "ipi-install-install" || gap.TestStep != "e2e-tests" || gap.Duration != 5*time.Minute {
+		t.Errorf("unexpected gap between provisioning and tests: %+v", gap)
+	}
+
+	html, err := tl.HTML()
+	if err != nil {
+		t.Fatalf("failed to render timeline: %v", err)
+	}
+	if !strings.Contains(string(html), "left: 43.48%; width: 52.17%") {
+		t.Errorf("expected the e2e-tests bar to be positioned within the timeline, got:\n%s", html)
+	}
+}
+
+// TestNewWithoutProvisioning tests that no gap is computed if there's no provisioning step
+func TestNewWithoutProvisioning(t *testing.T) {
+	start := time.Now()
+	tl, err := New([]Step{{Name: "e2e", Started: start, Finished: start.Add(time.Minute)}}, Options{})
+	if err != nil {
+		t.Fatalf("failed to create timeline: %v", err)
+	}
+	if tl.ProvisioningToTests != nil {
+		t.Errorf("expected no gap, got %+v", tl.ProvisioningToTests)
+	}
+	if _, err := New(nil, Options{TestStepPattern: "("}); err == nil {
+		t.Errorf("expected an error for invalid step pattern")
+	}
+}
@@ -6,6 +6,12 @@ const (
 	GithubTokenEnv    string = "GITHUB_TOKEN" // #nosec G101
 	ProwJobIDEnv      string = "PROW_JOB_ID"
 	OciArtifactRefEnv string = "OCI_REF"
+	ResultsDBEnv      string = "RESULTS_DB"
+	JobSpecEnv        string = "JOB_SPEC"
+
+	ReportPortalURLEnv     string = "REPORT_PORTAL_URL"
+	ReportPortalProjectEnv string = "REPORT_PORTAL_PROJECT"
+	ReportPortalTokenEnv   string = "REPORT_PORTAL_TOKEN" // #nosec G101
 
 	ArtifactDirParamName             string = "artifact-dir"
 	ProwJobIDParamName               string = "prow-job-id"
@@ -14,8 +20,15 @@ const (
 	E2ETestRunLogFileParamName       string = "e2e-log-name"
 	JUnitFilenameParamName           string = "junit-report-name"
 	OutputFilenameParamName          string = "output-file"
+	KnownIssuesFileParamName         string = "known-issues"
+	ResultsDBParamName               string = "results-db"
+	ReportPortalURLParamName         string = "report-portal-url"
+	ReportPortalProjectParamName     string = "report-portal-project"
 
 	JunitFilename string = `/(j?unit|e2e).*\.xml`
+	// AggregateTestSuiteName is the name of the JUnit suite added by "prowjob create-report" when reporting
+	// multiple jobs - its test cases only summarize the results of the jobs
+	AggregateTestSuiteName string = "aggregate summary"
 )
 
 // CmdParameter represents an abstraction for viper parameters
@@ -2,9 +2,15 @@ package utils
 
 import (
 	"fmt"
+	"net/http"
+	"regexp"
 	"strings"
+
+	"github.com/google/go-github/v56/github"
 )
 
+var ansiEscapeSequenceRegexp = regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]`)
+
 // ParseRepoAndTag extracts the quay.io repository and tag from the given repo flag.
 func ParseRepoAndTag(repoFlag string) (string, string, error) {
 	// Ensure the repoFlag starts with 'quay.io/'
@@ -22,3 +28,14 @@ func ParseRepoAndTag(repoFlag string) (string, string, error) {
 
 	return parts[0], parts[1], nil
 }
+
+// RemoveANSIEscapeSequences removes ANSI escape sequences (e.g. colors) from the given text
+func RemoveANSIEscapeSequences(text string) string {
+	return ansiEscapeSequenceRegexp.ReplaceAllString(text, "")
+}
+
+// NewGithubClient creates a GitHub API client authenticated with the given token - it's shared by all
+// commands talking to GitHub (e.g. commenting on PRs, publishing check runs), so they use the same API endpoint
+func NewGithubClient(token string) *github.Client {
+	return github.NewClient(http.DefaultClient).WithAuthToken(token)
+}